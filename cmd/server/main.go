@@ -1,23 +1,36 @@
 package main
 
 import (
+	"crypto/rand"
+	"flag"
 	"log"
+	"os"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
 	"github.com/ryory2/test-go-app-todo-go/config"
+	"github.com/ryory2/test-go-app-todo-go/internal/auth"
 	"github.com/ryory2/test-go-app-todo-go/internal/handler"
+	"github.com/ryory2/test-go-app-todo-go/internal/middleware"
+	"github.com/ryory2/test-go-app-todo-go/internal/model"
 	"github.com/ryory2/test-go-app-todo-go/internal/repository"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
 
 func main() {
+	// ユーザー管理の導入前から存在するタスクを登録済みのユーザーに引き継ぐ場合は、そのメールアドレスを指定する
+	claimLegacyData := flag.String("claim-legacy-data", "", "email of the user who takes over tasks created before user accounts existed, then exit")
+	flag.Parse()
+
 	// Load configuration
 	cfg := config.LoadConfig()
 
 	// Initialize database connection
-	db, err := gorm.Open(postgres.Open(cfg.DSN()), &gorm.Config{})
+	// TranslateError で一意制約違反などを gorm のエラーに変換する
+	db, err := gorm.Open(postgres.Open(cfg.DSN()), &gorm.Config{TranslateError: true})
 	if err != nil {
 		log.Fatalf("Failed to connect to database: %v", err)
 	}
@@ -27,6 +40,31 @@ func main() {
 
 	// Initialize repository
 	taskRepo := repository.NewTaskRepository(db)
+	tagRepo := repository.NewTagRepository(db)
+	projectRepo := repository.NewProjectRepository(db)
+	auditRepo := repository.NewAuditRepository(db)
+	userRepo := repository.NewUserRepository(db)
+	memberRepo := repository.NewMemberRepository(db)
+	workspaceRepo := repository.NewWorkspaceRepository(db)
+
+	if *claimLegacyData != "" {
+		claimLegacyTasks(userRepo, *claimLegacyData)
+		return
+	}
+
+	// ゴミ箱のタスクを保持期間の経過後に完全に削除する
+	if cfg.TrashRetentionDays > 0 {
+		go purgeTrash(taskRepo, cfg.TrashRetentionDays)
+	}
+
+	// 有効期限を過ぎた取り消しトークン・セッション・失効リストの行を削除する
+	go purgeExpiredTokens(taskRepo, userRepo)
+
+	// アクセストークン（JWT）の署名鍵を読み込む
+	tokens, err := newTokenManager(cfg)
+	if err != nil {
+		log.Fatalf("Failed to configure JWT: %v", err)
+	}
 
 	// Initialize validator
 	validate := validator.New()
@@ -34,6 +72,9 @@ func main() {
 	// Initialize Gin router
 	router := gin.Default()
 
+	// リクエストごとにリクエストIDを割り当てる（監査ログに記録する）
+	router.Use(middleware.RequestID())
+
 	// Middleware to set Content-Type to application/json
 	router.Use(func(c *gin.Context) {
 		c.Writer.Header().Set("Content-Type", "application/json")
@@ -41,16 +82,106 @@ func main() {
 	})
 
 	// Initialize handlers
-	taskHandler := handler.NewTaskHandler(taskRepo, validate)
+	taskHandler := handler.NewTaskHandler(taskRepo, auditRepo, memberRepo, validate, time.Duration(cfg.AssignmentEventLagSeconds)*time.Second)
+	tagHandler := handler.NewTagHandler(tagRepo, validate)
+	projectHandler := handler.NewProjectHandler(projectRepo, auditRepo, memberRepo, validate)
+	auditHandler := handler.NewAuditHandler(auditRepo)
+	authHandler := handler.NewAuthHandler(userRepo, tokens, validate, time.Duration(cfg.RefreshTokenTTLHours)*time.Hour, cfg.DefaultWorkspace)
+	workspaceHandler := handler.NewWorkspaceHandler(workspaceRepo, validate)
 
 	// Define routes
-	api := router.Group("/api/v1")
+	// 認証が不要なエンドポイント
+	public := router.Group("/api/v1")
 	{
-		api.GET("/tasks", taskHandler.GetTasks)
-		api.POST("/tasks", taskHandler.CreateTask)
-		api.PUT("/tasks/:id", taskHandler.UpdateTask)
-		api.DELETE("/tasks/:id", taskHandler.DeleteTask)
-		api.PATCH("/tasks/:id/toggle", taskHandler.ToggleTask)
+		public.POST("/auth/register", authHandler.Register)
+		public.POST("/auth/login", authHandler.Login)
+		public.POST("/auth/refresh", authHandler.Refresh)
+	}
+
+	// 以降のエンドポイントは Authorization: Bearer ヘッダーのアクセストークン（または個人用アクセストークン）による認証が必要
+	api := router.Group("/api/v1", middleware.Authenticate(tokens, userRepo))
+	{
+		api.POST("/auth/logout", authHandler.Logout)
+		api.GET("/auth/me", authHandler.GetCurrentUser)
+
+		api.GET("/workspaces", workspaceHandler.GetWorkspaces)
+
+		// 個人用アクセストークンの管理とワークスペースの作成には admin スコープが必要
+		admin := api.Group("", middleware.RequireScope(model.ScopeAdmin))
+		admin.GET("/auth/tokens", authHandler.GetPersonalAccessTokens)
+		admin.POST("/auth/tokens", authHandler.CreatePersonalAccessToken)
+		admin.DELETE("/auth/tokens/:id", authHandler.DeletePersonalAccessToken)
+		admin.POST("/workspaces", workspaceHandler.CreateWorkspace)
+
+		// 以降のエンドポイントは X-Workspace ヘッダー（またはサブドメイン）で指定したワークスペースのデータのみを操作する
+		tenant := api.Group("", middleware.ResolveTenant(workspaceRepo, cfg.WorkspaceBaseDomain, cfg.DefaultWorkspace))
+
+		// ワークスペースの設定・メンバーの管理と監査ログの参照には、admin スコープとワークスペースの管理者ロールが必要
+		workspaceAdmin := tenant.Group("", middleware.RequireScope(model.ScopeAdmin), middleware.RequireWorkspaceAdmin())
+		workspaceAdmin.PUT("/workspace", workspaceHandler.UpdateWorkspace)
+		workspaceAdmin.GET("/workspace/members", workspaceHandler.GetWorkspaceMembers)
+		workspaceAdmin.PUT("/workspace/members/:user_id", workspaceHandler.SetWorkspaceMember)
+		workspaceAdmin.DELETE("/workspace/members/:user_id", workspaceHandler.RemoveWorkspaceMember)
+		workspaceAdmin.GET("/audit", auditHandler.GetAuditLogs)
+
+		// タスク・タグ・プロジェクトの参照には tasks:read、変更には tasks:write スコープが必要
+		tasks := tenant.Group("", middleware.RequireMethodScope(model.ScopeTasksRead, model.ScopeTasksWrite))
+		tasks.GET("/workspace", workspaceHandler.GetWorkspace)
+		tasks.GET("/tasks", taskHandler.GetTasks)
+		tasks.POST("/tasks", taskHandler.CreateTask)
+		tasks.POST("/tasks/batch", taskHandler.BatchTasks)
+		tasks.POST("/tasks/archive", taskHandler.ArchiveCompletedTasks)
+		tasks.GET("/tasks/trash", taskHandler.GetTrash)
+		tasks.DELETE("/tasks/trash", taskHandler.EmptyTrash)
+		tasks.DELETE("/tasks/trash/:id", taskHandler.PurgeTask)
+		tasks.GET("/tasks/:id", taskHandler.GetTask)
+		tasks.PUT("/tasks/:id", taskHandler.UpdateTask)
+		tasks.PATCH("/tasks/:id", taskHandler.PatchTask)
+		tasks.DELETE("/tasks/:id", taskHandler.DeleteTask)
+		tasks.PATCH("/tasks/:id/toggle", taskHandler.ToggleTask)
+		tasks.POST("/tasks/:id/restore", taskHandler.RestoreTask)
+		tasks.POST("/tasks/:id/archive", taskHandler.ArchiveTask)
+		tasks.POST("/tasks/:id/unarchive", taskHandler.UnarchiveTask)
+		tasks.PUT("/tasks/:id/tags", taskHandler.SetTaskTags)
+		tasks.PUT("/tasks/:id/parent", taskHandler.SetTaskParent)
+		tasks.GET("/tasks/:id/children", taskHandler.GetTaskChildren)
+		tasks.GET("/tasks/:id/subtree", taskHandler.GetTaskSubtree)
+		tasks.GET("/tasks/:id/history", taskHandler.GetTaskHistory)
+		tasks.GET("/tasks/:id/revisions", taskHandler.GetTaskRevisions)
+		tasks.GET("/tasks/:id/revisions/diff", taskHandler.DiffTaskRevisions)
+		tasks.GET("/tasks/:id/revisions/:rev", taskHandler.GetTaskRevision)
+		tasks.POST("/tasks/:id/revert", taskHandler.RevertTask)
+		tasks.GET("/tasks/:id/members", taskHandler.GetTaskMembers)
+		tasks.PUT("/tasks/:id/members/:user_id", taskHandler.SetTaskMember)
+		tasks.DELETE("/tasks/:id/members/:user_id", taskHandler.RemoveTaskMember)
+		tasks.POST("/tasks/:id/assignees", taskHandler.AssignTask)
+		tasks.DELETE("/tasks/:id/assignees/:user_id", taskHandler.UnassignTask)
+		tasks.GET("/tasks/:id/comments", taskHandler.GetTaskComments)
+		tasks.POST("/tasks/:id/comments", taskHandler.CreateTaskComment)
+		tasks.PUT("/tasks/:id/comments/:comment_id", taskHandler.UpdateTaskComment)
+		tasks.DELETE("/tasks/:id/comments/:comment_id", taskHandler.DeleteTaskComment)
+		tasks.GET("/assignment-events", taskHandler.GetAssignmentEvents)
+		tasks.POST("/undo/:token", taskHandler.Undo)
+
+		tasks.GET("/tags", tagHandler.GetTags)
+		tasks.POST("/tags", tagHandler.CreateTag)
+		tasks.GET("/tags/:id", tagHandler.GetTag)
+		tasks.PUT("/tags/:id", tagHandler.UpdateTag)
+		tasks.DELETE("/tags/:id", tagHandler.DeleteTag)
+
+		tasks.GET("/projects", projectHandler.GetProjects)
+		tasks.POST("/projects", projectHandler.CreateProject)
+		tasks.GET("/projects/:id", projectHandler.GetProject)
+		tasks.PUT("/projects/:id", projectHandler.UpdateProject)
+		tasks.DELETE("/projects/:id", projectHandler.DeleteProject)
+		tasks.POST("/projects/:id/archive", projectHandler.ArchiveProject)
+		tasks.POST("/projects/:id/unarchive", projectHandler.UnarchiveProject)
+		tasks.GET("/projects/:id/tasks", projectHandler.GetProjectTasks)
+		tasks.POST("/projects/:id/tasks", projectHandler.MoveTasks)
+		tasks.DELETE("/projects/:id/tasks/:task_id", projectHandler.RemoveTask)
+		tasks.GET("/projects/:id/members", projectHandler.GetProjectMembers)
+		tasks.PUT("/projects/:id/members/:user_id", projectHandler.SetProjectMember)
+		tasks.DELETE("/projects/:id/members/:user_id", projectHandler.RemoveProjectMember)
 	}
 
 	// Start server
@@ -58,3 +189,82 @@ func main() {
 		log.Fatalf("Failed to run server: %v", err)
 	}
 }
+
+// claimLegacyTasks は移行用のユーザーが所有するタスクを email のユーザーに引き継ぎます
+func claimLegacyTasks(users repository.UserRepository, email string) {
+	user, err := users.GetUserByEmail(strings.ToLower(strings.TrimSpace(email)))
+	if err != nil {
+		log.Fatalf("Failed to find user %s: %v", email, err)
+	}
+	claimed, err := users.ClaimLegacyData(user)
+	if err != nil {
+		log.Fatalf("Failed to claim legacy data: %v", err)
+	}
+	log.Printf("User %s has taken over %d legacy tasks", user.Email, claimed)
+}
+
+// purgeTrash は1時間ごとに、削除から retentionDays 日を過ぎたゴミ箱のタスクを完全に削除します
+func purgeTrash(repo repository.TaskRepository, retentionDays int) {
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+	for {
+		purged, err := repo.PurgeDeletedTasks(time.Now().AddDate(0, 0, -retentionDays))
+		if err != nil {
+			log.Printf("Failed to purge trash: %v", err)
+		} else if len(purged) > 0 {
+			log.Printf("Purged %d tasks from trash", len(purged))
+		}
+		<-ticker.C
+	}
+}
+
+// purgeExpiredTokens は1時間ごとに、有効期限を過ぎた取り消しトークン・セッション・失効リストの行を削除します
+func purgeExpiredTokens(tasks repository.TaskRepository, users repository.UserRepository) {
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+	for {
+		if _, err := tasks.DeleteExpiredUndoTokens(time.Now()); err != nil {
+			log.Printf("Failed to delete expired undo tokens: %v", err)
+		}
+		if _, err := users.DeleteExpiredSessions(time.Now()); err != nil {
+			log.Printf("Failed to delete expired sessions: %v", err)
+		}
+		if _, err := users.DeleteExpiredRevokedTokens(time.Now()); err != nil {
+			log.Printf("Failed to delete expired revoked tokens: %v", err)
+		}
+		<-ticker.C
+	}
+}
+
+// newTokenManager は設定からアクセストークンの発行・検証に使う TokenManager を作成します
+func newTokenManager(cfg *config.Config) (*auth.TokenManager, error) {
+	opts := auth.Options{
+		Algorithm: cfg.JWTAlgorithm,
+		Secret:    []byte(cfg.JWTSecret),
+		Issuer:    cfg.JWTIssuer,
+		AccessTTL: time.Duration(cfg.AccessTokenTTLMinutes) * time.Minute,
+	}
+
+	switch cfg.JWTAlgorithm {
+	case auth.AlgorithmHS256:
+		if cfg.JWTSecret == "" {
+			// 再起動すると発行済みのアクセストークンはすべて無効になる
+			log.Printf("JWT_SECRET is not set; using a random secret")
+			opts.Secret = make([]byte, 32)
+			if _, err := rand.Read(opts.Secret); err != nil {
+				return nil, err
+			}
+		}
+	case auth.AlgorithmRS256:
+		var err error
+		if opts.PrivateKeyPEM, err = os.ReadFile(cfg.JWTPrivateKeyFile); err != nil {
+			return nil, err
+		}
+		if cfg.JWTPublicKeyFile != "" {
+			if opts.PublicKeyPEM, err = os.ReadFile(cfg.JWTPublicKeyFile); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return auth.NewTokenManager(opts)
+}
@@ -2,7 +2,9 @@ package config
 
 import (
 	"fmt"
+	"log"
 	"os"
+	"strconv"
 )
 
 type Config struct {
@@ -12,6 +14,32 @@ type Config struct {
 	DBPassword string
 	DBName     string
 	DBSSLMode  string
+
+	// TrashRetentionDays はゴミ箱のタスクを完全に削除するまでの日数です（0 の場合は自動で削除しない）
+	TrashRetentionDays int
+
+	// JWTAlgorithm はアクセストークンの署名アルゴリズム（HS256 または RS256）です
+	JWTAlgorithm string
+	// JWTSecret は HS256 の共有鍵です（32バイト以上。未設定の場合は起動ごとにランダムな鍵を使う）
+	JWTSecret string
+	// JWTPrivateKeyFile・JWTPublicKeyFile は RS256 の PEM 形式の鍵ファイルです（公開鍵は省略可）
+	JWTPrivateKeyFile string
+	JWTPublicKeyFile  string
+	JWTIssuer         string
+	// AccessTokenTTLMinutes はアクセストークンの有効期間（分）です
+	AccessTokenTTLMinutes int
+	// RefreshTokenTTLHours はリフレッシュトークン（ログインセッション）の有効期間（時間）です
+	RefreshTokenTTLHours int
+
+	// WorkspaceBaseDomain はサブドメインでワークスペースを指定する場合のドメインです（未設定の場合はサブドメインを使わない）
+	WorkspaceBaseDomain string
+	// DefaultWorkspace はワークスペースが指定されていないリクエストで使うワークスペースのスラッグです（空の場合は指定を必須にする）。
+	// 登録したユーザーはこのワークスペースのメンバーになります（空の場合はどこにも参加しない）
+	DefaultWorkspace string
+
+	// AssignmentEventLagSeconds は担当者の変更イベントを作成から読み出せるようになるまでの秒数です。
+	// トランザクションのコミットにかかる時間より長くする（短いと遅れてコミットされたイベントを読み飛ばす）
+	AssignmentEventLagSeconds int
 }
 
 func LoadConfig() *Config {
@@ -22,6 +50,21 @@ func LoadConfig() *Config {
 		DBPassword: getEnv("DB_PASSWORD", "password"),
 		DBName:     getEnv("DB_NAME", "todo_db"),
 		DBSSLMode:  getEnv("DB_SSLMODE", "disable"),
+
+		TrashRetentionDays: getEnvInt("TRASH_RETENTION_DAYS", 30),
+
+		JWTAlgorithm:          getEnv("JWT_ALGORITHM", "HS256"),
+		JWTSecret:             getEnv("JWT_SECRET", ""),
+		JWTPrivateKeyFile:     getEnv("JWT_PRIVATE_KEY_FILE", ""),
+		JWTPublicKeyFile:      getEnv("JWT_PUBLIC_KEY_FILE", ""),
+		JWTIssuer:             getEnv("JWT_ISSUER", "test-go-app-todo-go"),
+		AccessTokenTTLMinutes: getEnvInt("ACCESS_TOKEN_TTL_MINUTES", 15),
+		RefreshTokenTTLHours:  getEnvInt("REFRESH_TOKEN_TTL_HOURS", 24*7),
+
+		WorkspaceBaseDomain: getEnv("WORKSPACE_BASE_DOMAIN", ""),
+		DefaultWorkspace:    getEnv("DEFAULT_WORKSPACE", "default"),
+
+		AssignmentEventLagSeconds: getEnvInt("ASSIGNMENT_EVENT_LAG_SECONDS", 5),
 	}
 }
 
@@ -36,3 +79,16 @@ func getEnv(key, fallback string) string {
 	}
 	return fallback
 }
+
+func getEnvInt(key string, fallback int) int {
+	value, exists := os.LookupEnv(key)
+	if !exists {
+		return fallback
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		log.Printf("Invalid %s %q, using default %d", key, value, fallback)
+		return fallback
+	}
+	return n
+}
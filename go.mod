@@ -9,7 +9,11 @@ require (
 	gorm.io/gorm v1.25.12
 )
 
-require gorm.io/driver/postgres v1.5.10
+require (
+	github.com/golang-jwt/jwt/v5 v5.2.1
+	golang.org/x/crypto v0.29.0
+	gorm.io/driver/postgres v1.5.10
+)
 
 require (
 	github.com/bytedance/sonic v1.12.4 // indirect
@@ -42,7 +46,6 @@ require (
 	github.com/twitchyliquid64/golang-asm v0.15.1 // indirect
 	github.com/ugorji/go/codec v1.2.12 // indirect
 	golang.org/x/arch v0.12.0 // indirect
-	golang.org/x/crypto v0.29.0 // indirect
 	golang.org/x/net v0.31.0 // indirect
 	golang.org/x/sync v0.9.0 // indirect
 	golang.org/x/sys v0.27.0 // indirect
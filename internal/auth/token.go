@@ -0,0 +1,139 @@
+package auth
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/ryory2/test-go-app-todo-go/internal/model"
+)
+
+// 署名アルゴリズム
+const (
+	AlgorithmHS256 = "HS256"
+	AlgorithmRS256 = "RS256"
+)
+
+// Options はアクセストークンの署名と検証の設定です
+type Options struct {
+	// Algorithm は HS256 または RS256
+	Algorithm string
+	// Secret は HS256 の共有鍵
+	Secret []byte
+	// PrivateKeyPEM は RS256 の署名に使う PEM 形式の秘密鍵
+	PrivateKeyPEM []byte
+	// PublicKeyPEM は RS256 の検証に使う PEM 形式の公開鍵（省略した場合は秘密鍵から求める）
+	PublicKeyPEM []byte
+	Issuer       string
+	AccessTTL    time.Duration
+}
+
+// Claims はアクセストークンのクレームです
+type Claims struct {
+	jwt.RegisteredClaims
+	Email string `json:"email"`
+	// SessionID はトークンを発行したログインセッション（リフレッシュトークン）のID
+	SessionID uint `json:"sid"`
+}
+
+// UserID はトークンのユーザーのIDを返します
+func (c *Claims) UserID() (uint, error) {
+	id, err := strconv.ParseUint(c.Subject, 10, 0)
+	if err != nil || id == 0 {
+		return 0, fmt.Errorf("invalid subject %q", c.Subject)
+	}
+	return uint(id), nil
+}
+
+// TokenManager は JWT のアクセストークンを発行・検証します
+type TokenManager struct {
+	method    jwt.SigningMethod
+	signKey   interface{}
+	verifyKey interface{}
+	issuer    string
+	accessTTL time.Duration
+}
+
+// NewTokenManager は設定から TokenManager を作成します
+func NewTokenManager(opts Options) (*TokenManager, error) {
+	m := &TokenManager{issuer: opts.Issuer, accessTTL: opts.AccessTTL}
+	switch opts.Algorithm {
+	case AlgorithmHS256:
+		if len(opts.Secret) < 32 {
+			return nil, errors.New("HS256 secret must be at least 32 bytes")
+		}
+		m.method, m.signKey, m.verifyKey = jwt.SigningMethodHS256, opts.Secret, opts.Secret
+	case AlgorithmRS256:
+		privateKey, err := jwt.ParseRSAPrivateKeyFromPEM(opts.PrivateKeyPEM)
+		if err != nil {
+			return nil, fmt.Errorf("invalid RS256 private key: %w", err)
+		}
+		publicKey := &privateKey.PublicKey
+		if len(opts.PublicKeyPEM) > 0 {
+			if publicKey, err = jwt.ParseRSAPublicKeyFromPEM(opts.PublicKeyPEM); err != nil {
+				return nil, fmt.Errorf("invalid RS256 public key: %w", err)
+			}
+			if !publicKey.Equal(&privateKey.PublicKey) {
+				return nil, errors.New("RS256 public key does not match the private key")
+			}
+		}
+		m.method, m.signKey, m.verifyKey = jwt.SigningMethodRS256, privateKey, publicKey
+	default:
+		return nil, fmt.Errorf("unsupported JWT algorithm %q", opts.Algorithm)
+	}
+	return m, nil
+}
+
+// IssueAccessToken はユーザーとログインセッションのアクセストークンを発行します
+func (m *TokenManager) IssueAccessToken(user *model.User, sessionID uint) (string, *Claims, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return "", nil, err
+	}
+
+	now := time.Now()
+	claims := &Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        hex.EncodeToString(id),
+			Issuer:    m.issuer,
+			Subject:   strconv.FormatUint(uint64(user.ID), 10),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(m.accessTTL)),
+		},
+		Email:     user.Email,
+		SessionID: sessionID,
+	}
+	token, err := jwt.NewWithClaims(m.method, claims).SignedString(m.signKey)
+	if err != nil {
+		return "", nil, err
+	}
+	return token, claims, nil
+}
+
+// ParseAccessToken はアクセストークンの署名・発行者・有効期限を検証し、クレームを返します。
+// 設定と異なるアルゴリズムで署名されたトークンは受け付けません
+func (m *TokenManager) ParseAccessToken(token string) (*Claims, error) {
+	claims := &Claims{}
+	_, err := jwt.ParseWithClaims(token, claims, func(*jwt.Token) (interface{}, error) {
+		return m.verifyKey, nil
+	},
+		jwt.WithValidMethods([]string{m.method.Alg()}),
+		jwt.WithIssuer(m.issuer),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := claims.UserID(); err != nil {
+		return nil, err
+	}
+	if claims.ID == "" {
+		return nil, errors.New("token has no jti claim")
+	}
+	return claims, nil
+}
@@ -0,0 +1,132 @@
+// internal/auth/token_test.go
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/ryory2/test-go-app-todo-go/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+var testSecret = []byte("0123456789abcdef0123456789abcdef")
+
+// generateRSAKeyPEM はテスト用の RSA 秘密鍵を PEM 形式で作成します
+func generateRSAKeyPEM(t *testing.T) []byte {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+}
+
+// TestTokenManager は HS256 と RS256 で発行したアクセストークンを検証できることをテストします。
+func TestTokenManager(t *testing.T) {
+	tests := []struct {
+		name string
+		opts Options
+	}{
+		{"HS256", Options{Algorithm: AlgorithmHS256, Secret: testSecret}},
+		{"RS256", Options{Algorithm: AlgorithmRS256, PrivateKeyPEM: generateRSAKeyPEM(t)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.opts.Issuer = "todo"
+			tt.opts.AccessTTL = time.Minute
+			m, err := NewTokenManager(tt.opts)
+			assert.NoError(t, err)
+
+			token, issued, err := m.IssueAccessToken(&model.User{ID: 7, Email: "alice@example.com"}, 3)
+			assert.NoError(t, err)
+
+			claims, err := m.ParseAccessToken(token)
+			assert.NoError(t, err)
+			userID, err := claims.UserID()
+			assert.NoError(t, err)
+			assert.Equal(t, uint(7), userID)
+			assert.Equal(t, "alice@example.com", claims.Email)
+			assert.Equal(t, uint(3), claims.SessionID)
+			assert.Equal(t, issued.ID, claims.ID)
+			assert.Len(t, claims.ID, 32)
+		})
+	}
+}
+
+// TestTokenManager_Rejects は期限切れ・発行者違い・別の鍵やアルゴリズムで署名されたトークンを拒否することをテストします。
+func TestTokenManager_Rejects(t *testing.T) {
+	m, err := NewTokenManager(Options{Algorithm: AlgorithmHS256, Secret: testSecret, Issuer: "todo", AccessTTL: time.Minute})
+	assert.NoError(t, err)
+	user := &model.User{ID: 7}
+
+	sign := func(method jwt.SigningMethod, key interface{}, claims jwt.RegisteredClaims) string {
+		token, err := jwt.NewWithClaims(method, claims).SignedString(key)
+		assert.NoError(t, err)
+		return token
+	}
+	valid := jwt.RegisteredClaims{
+		ID:        "abc",
+		Issuer:    "todo",
+		Subject:   "7",
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+	}
+	expired := valid
+	expired.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-time.Minute))
+	otherIssuer := valid
+	otherIssuer.Issuer = "other"
+	noExpiry := valid
+	noExpiry.ExpiresAt = nil
+
+	rsaKey, err := jwt.ParseRSAPrivateKeyFromPEM(generateRSAKeyPEM(t))
+	assert.NoError(t, err)
+	other, err := NewTokenManager(Options{Algorithm: AlgorithmHS256, Secret: []byte(strings.Repeat("x", 32)), Issuer: "todo", AccessTTL: time.Minute})
+	assert.NoError(t, err)
+	otherToken, _, err := other.IssueAccessToken(user, 1)
+	assert.NoError(t, err)
+
+	tests := map[string]string{
+		"expired":      sign(jwt.SigningMethodHS256, testSecret, expired),
+		"other issuer": sign(jwt.SigningMethodHS256, testSecret, otherIssuer),
+		"no expiry":    sign(jwt.SigningMethodHS256, testSecret, noExpiry),
+		"other secret": otherToken,
+		"RS256":        sign(jwt.SigningMethodRS256, rsaKey, valid),
+		"none":         sign(jwt.SigningMethodNone, jwt.UnsafeAllowNoneSignatureType, valid),
+		"malformed":    "not-a-token",
+	}
+	for name, token := range tests {
+		_, err := m.ParseAccessToken(token)
+		assert.Error(t, err, name)
+	}
+
+	// 同じ鍵で署名された正しいトークンは受け付ける
+	_, err = m.ParseAccessToken(sign(jwt.SigningMethodHS256, testSecret, valid))
+	assert.NoError(t, err)
+}
+
+// TestNewTokenManager_InvalidOptions は鍵が不正な場合にエラーを返すことをテストします。
+func TestNewTokenManager_InvalidOptions(t *testing.T) {
+	_, err := NewTokenManager(Options{Algorithm: AlgorithmHS256, Secret: []byte("short")})
+	assert.Error(t, err)
+
+	_, err = NewTokenManager(Options{Algorithm: AlgorithmRS256, PrivateKeyPEM: []byte("not a key")})
+	assert.Error(t, err)
+
+	_, err = NewTokenManager(Options{Algorithm: AlgorithmRS256, PrivateKeyPEM: generateRSAKeyPEM(t), PublicKeyPEM: publicKeyPEM(t, generateRSAKeyPEM(t))})
+	assert.Error(t, err)
+
+	_, err = NewTokenManager(Options{Algorithm: "ES256"})
+	assert.Error(t, err)
+}
+
+// publicKeyPEM は PEM 形式の秘密鍵に対応する公開鍵を PEM 形式で返します
+func publicKeyPEM(t *testing.T, privateKeyPEM []byte) []byte {
+	key, err := jwt.ParseRSAPrivateKeyFromPEM(privateKeyPEM)
+	assert.NoError(t, err)
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	assert.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+}
@@ -0,0 +1,216 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ryory2/test-go-app-todo-go/internal/middleware"
+	"github.com/ryory2/test-go-app-todo-go/internal/model"
+	"github.com/ryory2/test-go-app-todo-go/internal/repository"
+)
+
+// auditListParams は GET /audit で受け付けるクエリパラメータです
+var auditListParams = map[string]bool{
+	"action":        true,
+	"resource_type": true,
+	"resource_id":   true,
+	"actor":         true,
+	"request_id":    true,
+	"from":          true,
+	"to":            true,
+	"limit":         true,
+	"offset":        true,
+}
+
+// AuditHandler構造体
+type AuditHandler struct {
+	repo repository.AuditRepository
+}
+
+// NewAuditHandler関数
+func NewAuditHandler(repo repository.AuditRepository) *AuditHandler {
+	return &AuditHandler{repo: repo}
+}
+
+// GetAuditLogsハンドラー
+// HTTP: GET /audit
+//
+// 監査ログを新しい順に返す。action・resource_type・resource_id・actor・request_id・from・to で絞り込める
+func (h *AuditHandler) GetAuditLogs(c *gin.Context) {
+	filter, err := parseAuditFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	entries, total, err := h.repo.ForTenant(currentTenantID(c)).GetAuditLogs(filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve audit logs"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":  entries,
+		"total": total,
+	})
+}
+
+// parseAuditFilter は GET /audit のクエリパラメータを検証し、監査ログの絞り込み条件に変換します
+func parseAuditFilter(c *gin.Context) (repository.AuditFilter, error) {
+	filter := repository.AuditFilter{Limit: 50}
+	query := c.Request.URL.Query()
+
+	if err := checkKnownParams(query, auditListParams); err != nil {
+		return filter, err
+	}
+
+	filter.Action = query.Get("action")
+	filter.ResourceType = query.Get("resource_type")
+	filter.Actor = query.Get("actor")
+	filter.RequestID = query.Get("request_id")
+
+	if value := query.Get("resource_id"); value != "" {
+		id, err := strconv.ParseUint(value, 10, 0)
+		if err != nil || id == 0 {
+			return filter, errors.New("Invalid resource_id parameter")
+		}
+		filter.ResourceID = uint(id)
+	}
+
+	// 期間（from 以降 to より前）
+	for _, r := range []struct {
+		name string
+		dest **time.Time
+	}{
+		{"from", &filter.From},
+		{"to", &filter.To},
+	} {
+		value := query.Get(r.name)
+		if value == "" {
+			continue
+		}
+		t, err := parseTimeParam(value, false)
+		if err != nil {
+			return filter, fmt.Errorf("Invalid %s parameter", r.name)
+		}
+		*r.dest = &t
+	}
+	if filter.From != nil && filter.To != nil && !filter.From.Before(*filter.To) {
+		return filter, errors.New("from must be earlier than to")
+	}
+
+	// クエリパラメータを整数に変換
+	var err error
+	if value := query.Get("limit"); value != "" {
+		if filter.Limit, err = strconv.Atoi(value); err != nil || filter.Limit <= 0 || filter.Limit > 500 {
+			return filter, errors.New("Invalid limit parameter")
+		}
+	}
+	if value := query.Get("offset"); value != "" {
+		if filter.Offset, err = strconv.Atoi(value); err != nil || filter.Offset < 0 {
+			return filter, errors.New("Invalid offset parameter")
+		}
+	}
+
+	return filter, nil
+}
+
+// recordTaskAudit はタスクの変更を監査ログに記録します。
+// before は作成時、after は削除時に nil とします。記録に失敗しても操作は取り消さず、ログに出力します
+func (h *TaskHandler) recordTaskAudit(c *gin.Context, action string, before, after *model.Task) {
+	writeTaskAudit(c, h.audit, action, before, after)
+}
+
+// recordTaskAudits は一括で変更したタスクごとに監査ログを記録します
+func (h *TaskHandler) recordTaskAudits(c *gin.Context, action string, changes []repository.TaskChange) {
+	for _, change := range changes {
+		writeTaskAudit(c, h.audit, action, change.Before, change.After)
+	}
+}
+
+// recordTaskAudits はプロジェクトの操作で変更したタスクごとに監査ログを記録します
+func (h *ProjectHandler) recordTaskAudits(c *gin.Context, action string, changes []repository.TaskChange) {
+	for _, change := range changes {
+		writeTaskAudit(c, h.audit, action, change.Before, change.After)
+	}
+}
+
+// writeTaskAudit はタスクの変更を audit のリクエストのワークスペースの監査ログに記録します
+func writeTaskAudit(c *gin.Context, audit repository.AuditRepository, action string, before, after *model.Task) {
+	taskID := uint(0)
+	if before != nil {
+		taskID = before.ID
+	} else if after != nil {
+		taskID = after.ID
+	}
+
+	changes, err := diffFields(before, after)
+	if err == nil && len(changes) == 0 {
+		return // 変更がない操作は記録しない
+	}
+	if err == nil {
+		err = audit.ForTenant(currentTenantID(c)).CreateAuditLog(&model.AuditLog{
+			Action:       action,
+			ResourceType: "task",
+			ResourceID:   taskID,
+			Actor:        auditActor(c),
+			RequestID:    c.GetString(middleware.RequestIDKey),
+			ClientIP:     c.ClientIP(),
+			Changes:      changes,
+		})
+	}
+	if err != nil {
+		log.Printf("Failed to record audit log (action=%s task=%d): %v", action, taskID, err)
+	}
+}
+
+// auditActor は操作したユーザーの識別子を返します（認証されていない場合は "anonymous"）
+func auditActor(c *gin.Context) string {
+	if actor := c.GetString(middleware.ActorKey); actor != "" {
+		return actor
+	}
+	return "anonymous"
+}
+
+// diffFields は2つの値の JSON 表現を比較し、値が異なるフィールドの変更前後の値を返します。
+// nil の値はすべてのフィールドが存在しないものとして扱います
+func diffFields(before, after interface{}) (map[string]model.FieldChange, error) {
+	toMap := func(value interface{}) (map[string]interface{}, error) {
+		object := map[string]interface{}{}
+		if v := reflect.ValueOf(value); !v.IsValid() || (v.Kind() == reflect.Pointer && v.IsNil()) {
+			return object, nil
+		}
+		data, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(data, &object); err != nil {
+			return nil, err
+		}
+		return object, nil
+	}
+
+	b, err := toMap(before)
+	if err != nil {
+		return nil, err
+	}
+	a, err := toMap(after)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make(map[string]model.FieldChange)
+	for key := range mergeKeys(b, a) {
+		if !reflect.DeepEqual(b[key], a[key]) {
+			changes[key] = model.FieldChange{Before: b[key], After: a[key]}
+		}
+	}
+	return changes, nil
+}
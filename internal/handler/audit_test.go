@@ -0,0 +1,220 @@
+// internal/handler/audit_test.go
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ryory2/test-go-app-todo-go/internal/middleware"
+	"github.com/ryory2/test-go-app-todo-go/internal/model"
+	"github.com/ryory2/test-go-app-todo-go/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// setupAuditTestHandler はテスト用の Gin エンジンとモック監査ログリポジトリをセットアップします。
+func setupAuditTestHandler(t *testing.T) (*gin.Engine, *repository.MockAuditRepository) {
+	gin.SetMode(gin.TestMode)
+	mockRepo := new(repository.MockAuditRepository)
+	handler := NewAuditHandler(mockRepo)
+	router := gin.Default()
+	router.Use(authenticateAs(testUserID))
+
+	// エンドポイントの登録
+	router.GET("/audit", handler.GetAuditLogs)
+
+	// ワークスペースへの絞り込みは個別のテストで確認する
+	mockRepo.On("ForTenant", testTenantID).Return().Maybe()
+
+	return router, mockRepo
+}
+
+// TestGetAuditLogs は GetAuditLogs ハンドラーが絞り込み条件を渡して監査ログを返すことをテストします。
+func TestGetAuditLogs(t *testing.T) {
+	router, mockRepo := setupAuditTestHandler(t)
+
+	from := time.Date(2024, 12, 1, 0, 0, 0, 0, time.UTC)
+	entries := []model.AuditLog{
+		{ID: 2, Action: model.AuditActionUpdate, ResourceType: "task", ResourceID: 1, Actor: "anonymous"},
+	}
+
+	// モックリポジトリの期待動作を設定
+	mockRepo.On("GetAuditLogs", repository.AuditFilter{
+		Action:       model.AuditActionUpdate,
+		ResourceType: "task",
+		ResourceID:   1,
+		From:         &from,
+		Limit:        20,
+	}).Return(entries, int64(1), nil)
+
+	// テストリクエストを作成
+	req, err := http.NewRequest(http.MethodGet, "/audit?action=update&resource_type=task&resource_id=1&from=2024-12-01&limit=20", nil)
+	assert.NoError(t, err)
+
+	// リクエストをルーターに送信
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// レスポンスボディを解析
+	var response map[string]interface{}
+	err = json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, float64(1), response["total"])
+	assert.Len(t, response["data"], 1)
+
+	mockRepo.AssertExpectations(t)
+}
+
+// TestGetAuditLogs_InvalidParams は不正なクエリパラメータを指定した場合に 400 を返すことをテストします。
+func TestGetAuditLogs_InvalidParams(t *testing.T) {
+	router, mockRepo := setupAuditTestHandler(t)
+
+	tests := []struct {
+		query    string
+		expected string
+	}{
+		{"foo=bar", "Unknown query parameter: foo"},
+		{"resource_id=abc", "Invalid resource_id parameter"},
+		{"from=yesterday", "Invalid from parameter"},
+		{"from=2024-12-02&to=2024-12-01", "from must be earlier than to"},
+		{"limit=1000", "Invalid limit parameter"},
+	}
+
+	for _, tt := range tests {
+		req, err := http.NewRequest(http.MethodGet, "/audit?"+tt.query, nil)
+		assert.NoError(t, err)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, tt.query)
+
+		var response map[string]interface{}
+		err = json.Unmarshal(w.Body.Bytes(), &response)
+		assert.NoError(t, err)
+		assert.Equal(t, tt.expected, response["error"], tt.query)
+	}
+
+	mockRepo.AssertNotCalled(t, "GetAuditLogs", mock.Anything)
+}
+
+// TestUpdateTask_Audit はタスクの更新時に変更前後の差分・リクエストID・クライアントIPが監査ログに記録されることをテストします。
+func TestUpdateTask_Audit(t *testing.T) {
+	router, mockRepo, auditRepo := setupAuditedTestHandler(t)
+
+	existingTask := &model.Task{ID: 1, Title: "元のタスク", Description: "詳細", Version: 1}
+
+	// モックリポジトリの期待動作を設定
+	mockRepo.On("GetTaskByID", uint(1)).Return(existingTask, nil)
+	mockRepo.On("UpdateTask", mock.AnythingOfType("*model.Task")).Return(nil)
+	auditRepo.On("CreateAuditLog", mock.MatchedBy(func(entry *model.AuditLog) bool {
+		title, ok := entry.Changes["title"]
+		_, unchanged := entry.Changes["description"]
+		return entry.Action == model.AuditActionUpdate &&
+			entry.ResourceType == "task" &&
+			entry.ResourceID == 1 &&
+			entry.Actor == "anonymous" &&
+			entry.RequestID == "req-1" &&
+			entry.ClientIP == "192.0.2.1" &&
+			ok && title.Before == "元のタスク" && title.After == "更新されたタスク" &&
+			!unchanged
+	})).Return(nil)
+
+	// テストリクエストを作成（PUT /tasks/1）
+	body := []byte(`{"title": "更新されたタスク", "description": "詳細"}`)
+	req, err := http.NewRequest(http.MethodPut, "/tasks/1", bytes.NewBuffer(body))
+	assert.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(middleware.RequestIDHeader, "req-1")
+	req.RemoteAddr = "192.0.2.1:12345"
+
+	// リクエストをルーターに送信
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	mockRepo.AssertExpectations(t)
+	auditRepo.AssertExpectations(t)
+}
+
+// TestDeleteTask_Audit はタスクの削除時に変更前の状態が監査ログに記録されることをテストします。
+func TestDeleteTask_Audit(t *testing.T) {
+	router, mockRepo, auditRepo := setupAuditedTestHandler(t)
+
+	existingTask := &model.Task{ID: 1, Title: "削除するタスク", Version: 1}
+
+	// モックリポジトリの期待動作を設定
+	mockRepo.On("GetTaskByID", uint(1)).Return(existingTask, nil)
+	mockRepo.On("DeleteTask", existingTask).Return([]uint(nil), nil)
+	auditRepo.On("CreateAuditLog", mock.MatchedBy(func(entry *model.AuditLog) bool {
+		title := entry.Changes["title"]
+		return entry.Action == model.AuditActionDelete && entry.ResourceID == 1 &&
+			title.Before == "削除するタスク" && title.After == nil
+	})).Return(nil)
+
+	// テストリクエストを作成（DELETE /tasks/1）
+	req, err := http.NewRequest(http.MethodDelete, "/tasks/1", nil)
+	assert.NoError(t, err)
+
+	// リクエストをルーターに送信
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	mockRepo.AssertExpectations(t)
+	auditRepo.AssertExpectations(t)
+}
+
+// TestBatchTasks_AuditSkipsRolledBack は一括操作が失敗して取り消された場合に監査ログを記録しないことをテストします。
+func TestBatchTasks_AuditSkipsRolledBack(t *testing.T) {
+	router, mockRepo, auditRepo := setupAuditedTestHandler(t)
+
+	// モックリポジトリの期待動作を設定
+	mockRepo.On("Transaction").Return(nil)
+	mockRepo.On("CreateTask", mock.AnythingOfType("*model.Task")).Return(nil)
+	mockRepo.On("GetTaskByID", uint(99)).Return((*model.Task)(nil), assert.AnError)
+
+	// テストリクエストを作成（POST /tasks/batch）
+	body := []byte(`{"operations": [
+		{"op": "create", "task": {"title": "新しいタスク"}},
+		{"op": "delete", "id": 99}
+	]}`)
+	req, err := http.NewRequest(http.MethodPost, "/tasks/batch", bytes.NewBuffer(body))
+	assert.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+
+	// リクエストをルーターに送信
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	auditRepo.AssertNotCalled(t, "CreateAuditLog", mock.Anything)
+}
+
+// TestDiffFields は変更されたフィールドのみが差分として返されることをテストします。
+func TestDiffFields(t *testing.T) {
+	before := &model.Task{ID: 1, Title: "before", Priority: model.PriorityLow}
+	after := &model.Task{ID: 1, Title: "after", Priority: model.PriorityLow}
+
+	changes, err := diffFields(before, after)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]model.FieldChange{
+		"title": {Before: "before", After: "after"},
+	}, changes)
+
+	// 作成時（変更前が nil）はすべてのフィールドが差分になる
+	changes, err = diffFields((*model.Task)(nil), after)
+	assert.NoError(t, err)
+	assert.Equal(t, "after", changes["title"].After)
+	assert.Nil(t, changes["title"].Before)
+	assert.Equal(t, float64(1), changes["id"].After)
+}
@@ -0,0 +1,284 @@
+package handler
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/ryory2/test-go-app-todo-go/internal/auth"
+	"github.com/ryory2/test-go-app-todo-go/internal/middleware"
+	"github.com/ryory2/test-go-app-todo-go/internal/model"
+	"github.com/ryory2/test-go-app-todo-go/internal/repository"
+	"gorm.io/gorm"
+)
+
+// dummyUser は存在しないメールアドレスでログインした場合にもパスワードの照合を行い、
+// 応答時間からユーザーの存在を推測されないようにするためのユーザーです
+var dummyUser = sync.OnceValue(func() *model.User {
+	user := &model.User{}
+	if err := user.SetPassword("dummy-password"); err != nil {
+		panic(err)
+	}
+	return user
+})
+
+// AuthHandler構造体
+type AuthHandler struct {
+	users      repository.UserRepository
+	tokens     *auth.TokenManager
+	validate   *validator.Validate
+	refreshTTL time.Duration
+	// defaultWorkspace は登録したユーザーをメンバーにするワークスペースのスラッグです（空の場合はどこにも参加させない）
+	defaultWorkspace string
+}
+
+// NewAuthHandler関数
+func NewAuthHandler(users repository.UserRepository, tokens *auth.TokenManager, validate *validator.Validate, refreshTTL time.Duration, defaultWorkspace string) *AuthHandler {
+	return &AuthHandler{
+		users:            users,
+		tokens:           tokens,
+		validate:         validate,
+		refreshTTL:       refreshTTL,
+		defaultWorkspace: defaultWorkspace,
+	}
+}
+
+// Registerハンドラー
+// HTTP: POST /auth/register
+func (h *AuthHandler) Register(c *gin.Context) {
+	var input struct {
+		Email    string `json:"email" validate:"required,email,max=255"`
+		Name     string `json:"name" validate:"omitempty,max=100"`
+		Password string `json:"password" validate:"required,min=8,max=72"` // bcrypt は72バイトまで
+	}
+
+	// リクエストボディをバインド
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON provided"})
+		return
+	}
+
+	// 入力値のバリデーション
+	input.Email = normalizeEmail(input.Email)
+	if err := h.validate.Struct(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// パスワードをハッシュ化してユーザーを作成し、既定のワークスペースのメンバーにする
+	user := model.User{Email: input.Email, Name: input.Name}
+	if err := user.SetPassword(input.Password); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create user"})
+		return
+	}
+	if err := h.users.CreateUser(&user, h.defaultWorkspace); err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Email is already registered"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create user"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"data": user})
+}
+
+// Loginハンドラー
+// HTTP: POST /auth/login
+//
+// メールアドレスとパスワードを確認し、アクセストークン（JWT）とリフレッシュトークンを発行する
+func (h *AuthHandler) Login(c *gin.Context) {
+	var input struct {
+		Email    string `json:"email" validate:"required"`
+		Password string `json:"password" validate:"required"`
+	}
+
+	// リクエストボディをバインド
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON provided"})
+		return
+	}
+
+	// 入力値のバリデーション
+	if err := h.validate.Struct(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// ユーザーを取得してパスワードを照合
+	user, err := h.users.GetUserByEmail(normalizeEmail(input.Email))
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to log in"})
+		return
+	}
+	if err != nil {
+		dummyUser().CheckPassword(input.Password)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid email or password"})
+		return
+	}
+	if !user.CheckPassword(input.Password) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid email or password"})
+		return
+	}
+
+	// ログインセッションを作成してトークンを発行
+	tokens, err := h.issueTokens(user)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to log in"})
+		return
+	}
+
+	tokens["user"] = user
+	c.JSON(http.StatusOK, gin.H{"data": tokens})
+}
+
+// Refreshハンドラー
+// HTTP: POST /auth/refresh
+//
+// リフレッシュトークンを新しいアクセストークンとリフレッシュトークンに交換する。
+// 使用したリフレッシュトークンは無効になる
+func (h *AuthHandler) Refresh(c *gin.Context) {
+	var input struct {
+		RefreshToken string `json:"refresh_token" validate:"required"`
+	}
+
+	// リクエストボディをバインド
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON provided"})
+		return
+	}
+
+	// 入力値のバリデーション
+	if err := h.validate.Struct(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// リフレッシュトークンのセッションを取得
+	session, err := h.users.GetSession(middleware.HashToken(input.RefreshToken))
+	if err != nil || session.IsExpired(time.Now()) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired refresh token"})
+		return
+	}
+
+	// 使用したセッションを削除する（同時に使われた場合は一方のみ成功する）
+	if err := h.users.DeleteSession(session); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired refresh token"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to refresh token"})
+		return
+	}
+
+	// 新しいセッションを作成してトークンを発行
+	tokens, err := h.issueTokens(&session.User)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to refresh token"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": tokens})
+}
+
+// Logoutハンドラー
+// HTTP: POST /auth/logout
+//
+// 認証に使用したアクセストークンを失効させ、そのログインセッションのリフレッシュトークンを無効にする
+func (h *AuthHandler) Logout(c *gin.Context) {
+	principal, ok := middleware.GetPrincipal(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+		return
+	}
+	if principal.PersonalAccessTokenID != 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Personal access tokens cannot log out; revoke the token instead"})
+		return
+	}
+
+	// アクセストークンを有効期限まで失効リストに登録
+	err := h.users.RevokeToken(&model.RevokedToken{JTI: principal.TokenID, ExpiresAt: principal.ExpiresAt})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to log out"})
+		return
+	}
+
+	// セッションを削除（すでに更新・削除されている場合は何もしない）
+	err = h.users.DeleteSession(&model.Session{ID: principal.SessionID})
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to log out"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Logged out successfully"})
+}
+
+// GetCurrentUserハンドラー
+// HTTP: GET /auth/me
+func (h *AuthHandler) GetCurrentUser(c *gin.Context) {
+	user, err := h.users.GetUserByID(currentUserID(c))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": user})
+}
+
+// currentUserID は認証済みのユーザーのIDを返します（認証されていない場合は 0）
+func currentUserID(c *gin.Context) uint {
+	return c.GetUint(middleware.UserIDKey)
+}
+
+// currentTenantID はリクエストのワークスペースのIDを返します（特定されていない場合は 0）
+func currentTenantID(c *gin.Context) uint {
+	return c.GetUint(middleware.TenantIDKey)
+}
+
+// normalizeEmail はメールアドレスの前後の空白を除き、小文字に揃えます
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// issueTokens はユーザーのログインセッションを作成し、アクセストークンとリフレッシュトークンを発行します
+func (h *AuthHandler) issueTokens(user *model.User) (gin.H, error) {
+	refreshToken, err := newRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+	session := model.Session{
+		TokenHash: middleware.HashToken(refreshToken),
+		UserID:    user.ID,
+		ExpiresAt: time.Now().Add(h.refreshTTL),
+	}
+	if err := h.users.CreateSession(&session); err != nil {
+		return nil, err
+	}
+
+	accessToken, claims, err := h.tokens.IssueAccessToken(user, session.ID)
+	if err != nil {
+		return nil, err
+	}
+	return gin.H{
+		"access_token":       accessToken,
+		"token_type":         "Bearer",
+		"expires_at":         claims.ExpiresAt.Time,
+		"refresh_token":      refreshToken,
+		"refresh_expires_at": session.ExpiresAt,
+	}, nil
+}
+
+// newRefreshToken はランダムなリフレッシュトークンを作成します
+func newRefreshToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
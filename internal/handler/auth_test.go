@@ -0,0 +1,314 @@
+// internal/handler/auth_test.go
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/ryory2/test-go-app-todo-go/internal/auth"
+	"github.com/ryory2/test-go-app-todo-go/internal/middleware"
+	"github.com/ryory2/test-go-app-todo-go/internal/model"
+	"github.com/ryory2/test-go-app-todo-go/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+// testTokenManager はテスト用の HS256 の TokenManager を作成します。
+func testTokenManager(t *testing.T) *auth.TokenManager {
+	tokens, err := auth.NewTokenManager(auth.Options{
+		Algorithm: auth.AlgorithmHS256,
+		Secret:    []byte("0123456789abcdef0123456789abcdef"),
+		Issuer:    "todo",
+		AccessTTL: 15 * time.Minute,
+	})
+	assert.NoError(t, err)
+	return tokens
+}
+
+// setupAuthTestHandler はテスト用の Gin エンジンとモックユーザーリポジトリをセットアップします。
+func setupAuthTestHandler(t *testing.T) (*gin.Engine, *repository.MockUserRepository) {
+	router, mockRepo, _ := setupAuthTestHandlerWithTokens(t)
+	return router, mockRepo
+}
+
+// setupAuthTestHandlerWithTokens はアクセストークンの TokenManager も返す setupAuthTestHandler です。
+func setupAuthTestHandlerWithTokens(t *testing.T) (*gin.Engine, *repository.MockUserRepository, *auth.TokenManager) {
+	gin.SetMode(gin.TestMode)
+	mockRepo := new(repository.MockUserRepository)
+	tokens := testTokenManager(t)
+	validate := validator.New()
+	handler := NewAuthHandler(mockRepo, tokens, validate, time.Hour, "default")
+	router := gin.Default()
+
+	// エンドポイントの登録
+	router.POST("/auth/register", handler.Register)
+	router.POST("/auth/login", handler.Login)
+	router.POST("/auth/refresh", handler.Refresh)
+	router.POST("/auth/logout", middleware.Authenticate(tokens, mockRepo), handler.Logout)
+	router.GET("/auth/me", authenticateAs(testUserID), handler.GetCurrentUser)
+	router.GET("/auth/tokens", authenticateAs(testUserID), handler.GetPersonalAccessTokens)
+	router.POST("/auth/tokens", authenticateAs(testUserID), handler.CreatePersonalAccessToken)
+	router.DELETE("/auth/tokens/:id", authenticateAs(testUserID), handler.DeletePersonalAccessToken)
+
+	return router, mockRepo, tokens
+}
+
+// TestRegister はメールアドレスを正規化し、パスワードをハッシュ化してユーザーを作成することをテストします。
+func TestRegister(t *testing.T) {
+	router, mockRepo := setupAuthTestHandler(t)
+
+	// モックリポジトリの期待動作を設定
+	mockRepo.On("CreateUser", mock.MatchedBy(func(user *model.User) bool {
+		return user.Email == "alice@example.com" && user.PasswordHash != "password123" && user.CheckPassword("password123")
+	}), "default").Return(nil).Run(func(args mock.Arguments) {
+		args.Get(0).(*model.User).ID = 1
+	})
+
+	// テストリクエストを作成（POST /auth/register）
+	body := []byte(`{"email": " Alice@Example.com ", "name": "Alice", "password": "password123"}`)
+	req, err := http.NewRequest(http.MethodPost, "/auth/register", bytes.NewBuffer(body))
+	assert.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+
+	// リクエストをルーターに送信
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	// パスワードのハッシュはレスポンスに含めない
+	assert.NotContains(t, w.Body.String(), "password")
+
+	mockRepo.AssertExpectations(t)
+}
+
+// TestRegister_DuplicateEmail は登録済みのメールアドレスの場合に 409 を返すことをテストします。
+func TestRegister_DuplicateEmail(t *testing.T) {
+	router, mockRepo := setupAuthTestHandler(t)
+
+	// モックリポジトリの期待動作を設定
+	mockRepo.On("CreateUser", mock.AnythingOfType("*model.User"), "default").Return(gorm.ErrDuplicatedKey)
+
+	// テストリクエストを作成（POST /auth/register）
+	body := []byte(`{"email": "alice@example.com", "password": "password123"}`)
+	req, err := http.NewRequest(http.MethodPost, "/auth/register", bytes.NewBuffer(body))
+	assert.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+
+	// リクエストをルーターに送信
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+}
+
+// TestRegister_ValidationError は不正なメールアドレスや短すぎるパスワードの場合に 400 を返すことをテストします。
+func TestRegister_ValidationError(t *testing.T) {
+	router, mockRepo := setupAuthTestHandler(t)
+
+	for _, body := range []string{
+		`{"email": "not-an-email", "password": "password123"}`,
+		`{"email": "alice@example.com", "password": "short"}`,
+	} {
+		req, err := http.NewRequest(http.MethodPost, "/auth/register", bytes.NewBufferString(body))
+		assert.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, body)
+	}
+
+	mockRepo.AssertNotCalled(t, "CreateUser", mock.Anything, mock.Anything)
+}
+
+// TestLogin はパスワードが一致した場合にアクセストークンとリフレッシュトークンを発行し、
+// リフレッシュトークンのハッシュをセッションとして保存することをテストします。
+func TestLogin(t *testing.T) {
+	router, mockRepo, tokens := setupAuthTestHandlerWithTokens(t)
+
+	user := &model.User{ID: 1, Email: "alice@example.com"}
+	assert.NoError(t, user.SetPassword("password123"))
+
+	// モックリポジトリの期待動作を設定
+	mockRepo.On("GetUserByEmail", "alice@example.com").Return(user, nil)
+	mockRepo.On("CreateSession", mock.AnythingOfType("*model.Session")).Return(nil).Run(func(args mock.Arguments) {
+		args.Get(0).(*model.Session).ID = 4
+	})
+
+	// テストリクエストを作成（POST /auth/login）
+	body := []byte(`{"email": "Alice@example.com", "password": "password123"}`)
+	req, err := http.NewRequest(http.MethodPost, "/auth/login", bytes.NewBuffer(body))
+	assert.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+
+	// リクエストをルーターに送信
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// レスポンスボディを解析
+	var response struct {
+		Data struct {
+			AccessToken      string    `json:"access_token"`
+			TokenType        string    `json:"token_type"`
+			ExpiresAt        time.Time `json:"expires_at"`
+			RefreshToken     string    `json:"refresh_token"`
+			RefreshExpiresAt time.Time `json:"refresh_expires_at"`
+		} `json:"data"`
+	}
+	err = json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, "Bearer", response.Data.TokenType)
+	assert.WithinDuration(t, time.Now().Add(15*time.Minute), response.Data.ExpiresAt, 5*time.Second)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), response.Data.RefreshExpiresAt, 5*time.Second)
+
+	// アクセストークンにはユーザーとセッションが含まれる
+	claims, err := tokens.ParseAccessToken(response.Data.AccessToken)
+	assert.NoError(t, err)
+	assert.Equal(t, "1", claims.Subject)
+	assert.Equal(t, uint(4), claims.SessionID)
+
+	// リフレッシュトークン自体は保存せず、ハッシュのみを保存する
+	mockRepo.AssertCalled(t, "CreateSession", mock.MatchedBy(func(session *model.Session) bool {
+		return session.UserID == 1 && session.TokenHash == middleware.HashToken(response.Data.RefreshToken)
+	}))
+}
+
+// TestLogin_InvalidCredentials はパスワードが一致しない場合とユーザーが存在しない場合に同じ 401 を返すことをテストします。
+func TestLogin_InvalidCredentials(t *testing.T) {
+	router, mockRepo := setupAuthTestHandler(t)
+
+	user := &model.User{ID: 1, Email: "alice@example.com"}
+	assert.NoError(t, user.SetPassword("password123"))
+
+	// モックリポジトリの期待動作を設定
+	mockRepo.On("GetUserByEmail", "alice@example.com").Return(user, nil)
+	mockRepo.On("GetUserByEmail", "bob@example.com").Return((*model.User)(nil), gorm.ErrRecordNotFound)
+
+	for _, body := range []string{
+		`{"email": "alice@example.com", "password": "wrong-password"}`,
+		`{"email": "bob@example.com", "password": "password123"}`,
+	} {
+		req, err := http.NewRequest(http.MethodPost, "/auth/login", bytes.NewBufferString(body))
+		assert.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code, body)
+		assert.Contains(t, w.Body.String(), "Invalid email or password")
+	}
+
+	mockRepo.AssertNotCalled(t, "CreateSession", mock.Anything)
+}
+
+// TestRefresh はリフレッシュトークンを新しいトークンに交換し、使用したセッションを削除することをテストします。
+func TestRefresh(t *testing.T) {
+	router, mockRepo := setupAuthTestHandler(t)
+
+	session := &model.Session{ID: 4, UserID: 1, User: model.User{ID: 1, Email: "alice@example.com"}, ExpiresAt: time.Now().Add(time.Hour)}
+
+	// モックリポジトリの期待動作を設定
+	mockRepo.On("GetSession", middleware.HashToken("refresh-token")).Return(session, nil)
+	mockRepo.On("DeleteSession", session).Return(nil)
+	mockRepo.On("CreateSession", mock.MatchedBy(func(s *model.Session) bool {
+		return s.UserID == 1 && s.TokenHash != middleware.HashToken("refresh-token")
+	})).Return(nil)
+
+	// テストリクエストを作成（POST /auth/refresh）
+	req, err := http.NewRequest(http.MethodPost, "/auth/refresh", bytes.NewBufferString(`{"refresh_token": "refresh-token"}`))
+	assert.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+
+	// リクエストをルーターに送信
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "access_token")
+
+	mockRepo.AssertExpectations(t)
+}
+
+// TestRefresh_Invalid は不明・期限切れ・使用済みのリフレッシュトークンの場合に 401 を返すことをテストします。
+func TestRefresh_Invalid(t *testing.T) {
+	router, mockRepo := setupAuthTestHandler(t)
+
+	expired := &model.Session{ID: 5, UserID: 1, ExpiresAt: time.Now().Add(-time.Second)}
+	used := &model.Session{ID: 6, UserID: 1, ExpiresAt: time.Now().Add(time.Hour)}
+
+	// モックリポジトリの期待動作を設定
+	mockRepo.On("GetSession", middleware.HashToken("unknown")).Return((*model.Session)(nil), gorm.ErrRecordNotFound)
+	mockRepo.On("GetSession", middleware.HashToken("expired")).Return(expired, nil)
+	mockRepo.On("GetSession", middleware.HashToken("used")).Return(used, nil)
+	mockRepo.On("DeleteSession", used).Return(gorm.ErrRecordNotFound)
+
+	for _, token := range []string{"unknown", "expired", "used"} {
+		req, err := http.NewRequest(http.MethodPost, "/auth/refresh", bytes.NewBufferString(`{"refresh_token": "`+token+`"}`))
+		assert.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code, token)
+	}
+
+	mockRepo.AssertNotCalled(t, "CreateSession", mock.Anything)
+}
+
+// TestLogout はアクセストークンを失効リストに登録し、ログインセッションを削除することをテストします。
+func TestLogout(t *testing.T) {
+	router, mockRepo, tokens := setupAuthTestHandlerWithTokens(t)
+
+	token, claims, err := tokens.IssueAccessToken(&model.User{ID: 1, Email: "alice@example.com"}, 4)
+	assert.NoError(t, err)
+
+	// モックリポジトリの期待動作を設定
+	mockRepo.On("IsTokenRevoked", claims.ID).Return(false, nil)
+	mockRepo.On("RevokeToken", mock.MatchedBy(func(revoked *model.RevokedToken) bool {
+		return revoked.JTI == claims.ID && revoked.ExpiresAt.Equal(claims.ExpiresAt.Time)
+	})).Return(nil)
+	mockRepo.On("DeleteSession", &model.Session{ID: 4}).Return(nil)
+
+	// テストリクエストを作成（POST /auth/logout）
+	req, err := http.NewRequest(http.MethodPost, "/auth/logout", nil)
+	assert.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	// リクエストをルーターに送信
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	mockRepo.AssertExpectations(t)
+}
+
+// TestGetCurrentUser は認証済みのユーザーの情報を返すことをテストします。
+func TestGetCurrentUser(t *testing.T) {
+	router, mockRepo := setupAuthTestHandler(t)
+
+	// モックリポジトリの期待動作を設定
+	mockRepo.On("GetUserByID", testUserID).Return(&model.User{ID: testUserID, Email: "alice@example.com"}, nil)
+
+	// テストリクエストを作成（GET /auth/me）
+	req, err := http.NewRequest(http.MethodGet, "/auth/me", nil)
+	assert.NoError(t, err)
+
+	// リクエストをルーターに送信
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "alice@example.com")
+
+	mockRepo.AssertExpectations(t)
+}
@@ -0,0 +1,67 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ryory2/test-go-app-todo-go/internal/policy"
+	"github.com/ryory2/test-go-app-todo-go/internal/repository"
+)
+
+// requestError はレスポンスのステータスコードとメッセージを伴うエラーです
+type requestError struct {
+	status  int
+	message string
+}
+
+func (e *requestError) Error() string {
+	return e.message
+}
+
+// newRequestError はステータスコードとメッセージから requestError を作成します
+func newRequestError(status int, message string) error {
+	return &requestError{status: status, message: message}
+}
+
+// errorStatus はエラーに対応するステータスコードとメッセージを返します。
+// requestError 以外の不明なエラーは 500 と fallback のメッセージになります
+func errorStatus(err error, fallback string) (int, string) {
+	var reqErr *requestError
+	var denial *policy.Denial
+	switch {
+	case errors.As(err, &reqErr):
+		return reqErr.status, reqErr.message
+	case errors.As(err, &denial):
+		return http.StatusForbidden, denial.Error()
+	case errors.Is(err, repository.ErrVersionConflict):
+		return http.StatusConflict, "Task was modified by another request"
+	default:
+		return http.StatusInternalServerError, fallback
+	}
+}
+
+// respondError はエラーに応じたエラーレスポンスを書き込みます
+func respondError(c *gin.Context, err error, fallback string) {
+	if respondVersionConflict(c, err) {
+		return
+	}
+	var denial *policy.Denial
+	if errors.As(err, &denial) {
+		respondDenied(c, denial)
+		return
+	}
+	status, message := errorStatus(err, fallback)
+	c.JSON(status, gin.H{"error": message})
+}
+
+// respondDenied はロールの権限が足りない場合の 403 Forbidden のレスポンスを、機械的に判別できる理由とともに書き込みます
+func respondDenied(c *gin.Context, denial *policy.Denial) {
+	c.JSON(http.StatusForbidden, gin.H{
+		"error":         denial.Error(),
+		"reason":        denial.Reason,
+		"action":        denial.Action,
+		"role":          denial.Role,
+		"required_role": denial.RequiredRole,
+	})
+}
@@ -0,0 +1,92 @@
+package handler
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ryory2/test-go-app-todo-go/internal/model"
+	"github.com/ryory2/test-go-app-todo-go/internal/repository"
+)
+
+// taskETag はタスクのIDとバージョンから強い ETag を作成します
+func taskETag(task *model.Task) string {
+	return fmt.Sprintf(`"%d-%d"`, task.ID, task.Version)
+}
+
+// checkIfMatch は If-Match ヘッダーがタスクの現在の ETag と一致するかを確認します。
+// 一致しない場合は 412 Precondition Failed を書き込み false を返します（ヘッダーがない場合は常に true）
+func checkIfMatch(c *gin.Context, task *model.Task) bool {
+	header := c.GetHeader("If-Match")
+	if header == "" || etagMatches(header, taskETag(task), false) {
+		return true
+	}
+	c.Header("ETag", taskETag(task))
+	c.JSON(http.StatusPreconditionFailed, gin.H{"error": "Task has been modified"})
+	return false
+}
+
+// respondVersionConflict は更新時のバージョン競合のレスポンスを書き込みます。
+// If-Match を指定したリクエストは 412、それ以外は 409 とします
+func respondVersionConflict(c *gin.Context, err error) bool {
+	if !errors.Is(err, repository.ErrVersionConflict) {
+		return false
+	}
+	if c.GetHeader("If-Match") != "" {
+		c.JSON(http.StatusPreconditionFailed, gin.H{"error": "Task has been modified"})
+	} else {
+		c.JSON(http.StatusConflict, gin.H{"error": "Task was modified by another request"})
+	}
+	return true
+}
+
+// respondTask は ETag ヘッダーを付けて単一のタスクを返します
+func respondTask(c *gin.Context, status int, task *model.Task) {
+	c.Header("ETag", taskETag(task))
+	c.JSON(status, gin.H{"data": task})
+}
+
+// respondWithETag はレスポンスボディのハッシュから弱い ETag を作成して返します。
+// If-None-Match が一致する場合は 304 Not Modified を返します
+func respondWithETag(c *gin.Context, body interface{}) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to encode response"})
+		return
+	}
+	sum := sha256.Sum256(data)
+	etag := `W/"` + hex.EncodeToString(sum[:16]) + `"`
+
+	c.Header("ETag", etag)
+	if header := c.GetHeader("If-None-Match"); header != "" && etagMatches(header, etag, true) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+	c.Data(http.StatusOK, "application/json; charset=utf-8", data)
+}
+
+// etagMatches はカンマ区切りの ETag のリスト（または "*"）に etag が含まれるかを返します。
+// weak が false の場合は強い比較（弱い ETag は一致しない）を行います
+func etagMatches(header, etag string, weak bool) bool {
+	if strings.TrimSpace(header) == "*" {
+		return true
+	}
+	if weak {
+		etag = strings.TrimPrefix(etag, "W/")
+	}
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if weak {
+			candidate = strings.TrimPrefix(candidate, "W/")
+		}
+		if candidate == etag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,94 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// taskFields は fields パラメータで指定できるタスクのフィールド（JSON のキー）です
+var taskFields = map[string]bool{
+	"id":               true,
+	"title":            true,
+	"description":      true,
+	"due_date":         true,
+	"is_completed":     true,
+	"completed_at":     true,
+	"priority":         true,
+	"project_id":       true,
+	"parent_id":        true,
+	"rrule":            true,
+	"recurrence_of_id": true,
+	"owner_id":         true,
+	"tags":             true,
+	"assignees":        true,
+	"archived_at":      true,
+	"version":          true,
+	"created_at":       true,
+	"updated_at":       true,
+	"deleted_at":       true,
+	"progress":         true,
+	"comment_count":    true,
+}
+
+// parseFields は fields パラメータ（例: fields=id,title,is_completed）を解析します。
+// 未指定の場合は nil を返します。id は常に含めます
+func parseFields(value string) ([]string, error) {
+	if strings.TrimSpace(value) == "" {
+		return nil, nil
+	}
+	fields := []string{"id"}
+	for _, field := range parseCommaSeparated([]string{value}) {
+		if !taskFields[field] {
+			return nil, fmt.Errorf("Invalid fields parameter: unknown field %q", field)
+		}
+		if field != "id" {
+			fields = append(fields, field)
+		}
+	}
+	return fields, nil
+}
+
+// selectFields はタスク（またはタスクのスライス）を JSON に変換し、指定されたフィールドのみを残します。
+// fields が nil の場合は value をそのまま返します
+func selectFields(value interface{}, fields []string) (interface{}, error) {
+	if fields == nil {
+		return value, nil
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	if bytes.Equal(data, []byte("null")) {
+		return value, nil
+	}
+
+	pick := func(object map[string]json.RawMessage) map[string]json.RawMessage {
+		selected := make(map[string]json.RawMessage, len(fields))
+		for _, field := range fields {
+			if v, ok := object[field]; ok {
+				selected[field] = v
+			}
+		}
+		return selected
+	}
+
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("[")) {
+		var objects []map[string]json.RawMessage
+		if err := json.Unmarshal(data, &objects); err != nil {
+			return nil, err
+		}
+		selected := make([]map[string]json.RawMessage, len(objects))
+		for i, object := range objects {
+			selected[i] = pick(object)
+		}
+		return selected, nil
+	}
+
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal(data, &object); err != nil {
+		return nil, err
+	}
+	return pick(object), nil
+}
@@ -0,0 +1,256 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ryory2/test-go-app-todo-go/internal/model"
+	"github.com/ryory2/test-go-app-todo-go/internal/policy"
+	"github.com/ryory2/test-go-app-todo-go/internal/repository"
+	"gorm.io/gorm"
+)
+
+// memberInput はメンバーを追加・変更するリクエストボディです
+type memberInput struct {
+	Role string `json:"role" validate:"required,oneof=owner editor commenter viewer"`
+}
+
+// GetTaskMembersハンドラー
+// HTTP: GET /tasks/{id}/members
+func (h *TaskHandler) GetTaskMembers(c *gin.Context) {
+	task, ok := h.findTask(c)
+	if !ok {
+		return
+	}
+
+	members, err := h.tenantMembers(c).GetTaskMembers(task.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve members"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": members})
+}
+
+// SetTaskMemberハンドラー
+// HTTP: PUT /tasks/{id}/members/{user_id}
+//
+// ユーザーにタスクを共有する（共有済みの場合はロールを変更する）
+func (h *TaskHandler) SetTaskMember(c *gin.Context) {
+	task, ok := h.findTask(c)
+	if !ok {
+		return
+	}
+	userID, ok := parseMemberUserID(c)
+	if !ok {
+		return
+	}
+
+	// ロールの権限を確認
+	if !h.authorize(c, task.ID, policy.ActionManageMembers) {
+		return
+	}
+
+	var input memberInput
+
+	// リクエストボディをバインド
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON provided"})
+		return
+	}
+
+	// 入力値のバリデーション
+	if err := h.validate.Struct(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// メンバーを追加・変更
+	member := model.TaskMember{TaskID: task.ID, UserID: userID, Role: input.Role}
+	if err := h.tenantMembers(c).SetTaskMember(&member); err != nil {
+		respondMemberError(c, err, "Failed to update member")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": member})
+}
+
+// RemoveTaskMemberハンドラー
+// HTTP: DELETE /tasks/{id}/members/{user_id}
+func (h *TaskHandler) RemoveTaskMember(c *gin.Context) {
+	task, ok := h.findTask(c)
+	if !ok {
+		return
+	}
+	userID, ok := parseMemberUserID(c)
+	if !ok {
+		return
+	}
+
+	// ロールの権限を確認（自分自身は権限によらず共有を解除できる）
+	if userID != currentUserID(c) && !h.authorize(c, task.ID, policy.ActionManageMembers) {
+		return
+	}
+
+	if err := h.tenantMembers(c).RemoveTaskMember(task.ID, userID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Member not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove member"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Member removed successfully"})
+}
+
+// GetProjectMembersハンドラー
+// HTTP: GET /projects/{id}/members
+func (h *ProjectHandler) GetProjectMembers(c *gin.Context) {
+	project, ok := h.findProject(c)
+	if !ok {
+		return
+	}
+
+	members, err := h.tenantMembers(c).GetProjectMembers(project.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve members"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": members})
+}
+
+// SetProjectMemberハンドラー
+// HTTP: PUT /projects/{id}/members/{user_id}
+//
+// ユーザーをプロジェクトのメンバーにする（メンバーの場合はロールを変更する）
+func (h *ProjectHandler) SetProjectMember(c *gin.Context) {
+	project, ok := h.findProject(c)
+	if !ok {
+		return
+	}
+	userID, ok := parseMemberUserID(c)
+	if !ok {
+		return
+	}
+
+	// ロールの権限を確認
+	if !h.authorize(c, project.ID, policy.ActionManageMembers) {
+		return
+	}
+
+	var input memberInput
+
+	// リクエストボディをバインド
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON provided"})
+		return
+	}
+
+	// 入力値のバリデーション
+	if err := h.validate.Struct(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// メンバーを追加・変更
+	member := model.ProjectMember{ProjectID: project.ID, UserID: userID, Role: input.Role}
+	if err := h.tenantMembers(c).SetProjectMember(&member); err != nil {
+		respondMemberError(c, err, "Failed to update member")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": member})
+}
+
+// RemoveProjectMemberハンドラー
+// HTTP: DELETE /projects/{id}/members/{user_id}
+func (h *ProjectHandler) RemoveProjectMember(c *gin.Context) {
+	project, ok := h.findProject(c)
+	if !ok {
+		return
+	}
+	userID, ok := parseMemberUserID(c)
+	if !ok {
+		return
+	}
+
+	// ロールの権限を確認（自分自身は権限によらずプロジェクトから抜けられる）
+	if userID != currentUserID(c) && !h.authorize(c, project.ID, policy.ActionManageMembers) {
+		return
+	}
+
+	if err := h.tenantMembers(c).RemoveProjectMember(project.ID, userID); err != nil {
+		respondMemberError(c, err, "Failed to remove member")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Member removed successfully"})
+}
+
+// authorize は認証済みのユーザーがタスクに対して action を実行できるかを確認します。
+// 許可されない場合はエラーレスポンスを書き込み false を返します
+func (h *TaskHandler) authorize(c *gin.Context, taskID uint, action policy.Action) bool {
+	if err := h.enforcer(c).AuthorizeTask(currentUserID(c), taskID, action); err != nil {
+		respondError(c, err, "Failed to check permissions")
+		return false
+	}
+	return true
+}
+
+// authorize は認証済みのユーザーがプロジェクトに対して action を実行できるかを確認します。
+// 許可されない場合はエラーレスポンスを書き込み false を返します
+func (h *ProjectHandler) authorize(c *gin.Context, projectID uint, action policy.Action) bool {
+	if err := h.enforcer(c).AuthorizeProject(currentUserID(c), projectID, action); err != nil {
+		respondError(c, err, "Failed to check permissions")
+		return false
+	}
+	return true
+}
+
+// tenantMembers はリクエストのワークスペースのメンバーのみを参照するリポジトリを返します
+func (h *TaskHandler) tenantMembers(c *gin.Context) repository.MemberRepository {
+	return h.members.ForTenant(currentTenantID(c))
+}
+
+// tenantMembers はリクエストのワークスペースのメンバーのみを参照するリポジトリを返します
+func (h *ProjectHandler) tenantMembers(c *gin.Context) repository.MemberRepository {
+	return h.members.ForTenant(currentTenantID(c))
+}
+
+// enforcer はリクエストのワークスペースのメンバーのロールで権限を確認する Enforcer を返します
+func (h *TaskHandler) enforcer(c *gin.Context) *policy.Enforcer {
+	return policy.NewEnforcer(h.tenantMembers(c))
+}
+
+// enforcer はリクエストのワークスペースのメンバーのロールで権限を確認する Enforcer を返します
+func (h *ProjectHandler) enforcer(c *gin.Context) *policy.Enforcer {
+	return policy.NewEnforcer(h.tenantMembers(c))
+}
+
+// parseMemberUserID はURLパラメータからメンバーのユーザーIDを取得します。失敗時はエラーレスポンスを書き込み false を返します
+func parseMemberUserID(c *gin.Context) (uint, bool) {
+	userID, err := strconv.Atoi(c.Param("user_id"))
+	if err != nil || userID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return 0, false
+	}
+	return uint(userID), true
+}
+
+// respondMemberError はタスク・プロジェクトのメンバーの変更に失敗した場合のエラーレスポンスを書き込みます
+func respondMemberError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Member not found"})
+	case errors.Is(err, gorm.ErrForeignKeyViolated), errors.Is(err, repository.ErrNotWorkspaceMember):
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+	case errors.Is(err, repository.ErrLastOwner):
+		c.JSON(http.StatusConflict, gin.H{"error": "Project must have at least one owner"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
@@ -0,0 +1,320 @@
+// internal/handler/member_test.go
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ryory2/test-go-app-todo-go/internal/model"
+	"github.com/ryory2/test-go-app-todo-go/internal/policy"
+	"github.com/ryory2/test-go-app-todo-go/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+// TestDeleteTask_Forbidden は owner 以外のロールではタスクを削除できず、理由とともに 403 を返すことをテストします。
+func TestDeleteTask_Forbidden(t *testing.T) {
+	router, mockRepo, _, memberRepo := setupMemberTestHandler(t)
+
+	// モックリポジトリの期待動作を設定
+	task := &model.Task{ID: 1, Title: "共有されたタスク"}
+	mockRepo.On("GetTaskByID", uint(1)).Return(task, nil)
+	memberRepo.On("GetTaskRole", uint(1), testUserID).Return(model.RoleEditor, nil)
+
+	// テストリクエストを作成（DELETE /tasks/1）
+	req, err := http.NewRequest(http.MethodDelete, "/tasks/1", nil)
+	assert.NoError(t, err)
+
+	// リクエストをルーターに送信
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	var response map[string]interface{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, policy.ReasonInsufficientRole, response["reason"])
+	assert.Equal(t, string(policy.ActionDelete), response["action"])
+	assert.Equal(t, model.RoleEditor, response["role"])
+	assert.Equal(t, model.RoleOwner, response["required_role"])
+
+	// リポジトリの削除処理は呼び出されない
+	mockRepo.AssertNotCalled(t, "DeleteTask", mock.Anything)
+	mockRepo.AssertNotCalled(t, "GetChildren", mock.Anything)
+}
+
+// TestUpdateTask_Forbidden は viewer・commenter のロールではタスクを更新できないことをテストします。
+func TestUpdateTask_Forbidden(t *testing.T) {
+	for _, role := range []string{model.RoleViewer, model.RoleCommenter} {
+		router, mockRepo, _, memberRepo := setupMemberTestHandler(t)
+
+		// モックリポジトリの期待動作を設定
+		mockRepo.On("GetTaskByID", uint(1)).Return(&model.Task{ID: 1, Title: "共有されたタスク"}, nil)
+		memberRepo.On("GetTaskRole", uint(1), testUserID).Return(role, nil)
+
+		// テストリクエストを作成（PUT /tasks/1）
+		req, err := http.NewRequest(http.MethodPut, "/tasks/1", bytes.NewBufferString(`{"title":"変更"}`))
+		assert.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+
+		// リクエストをルーターに送信
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusForbidden, w.Code, role)
+		assert.Contains(t, w.Body.String(), `"reason":"insufficient_role"`, role)
+		mockRepo.AssertNotCalled(t, "UpdateTask", mock.Anything)
+	}
+}
+
+// TestCreateTask_ProjectNotMember はメンバーではないプロジェクトにはタスクを作成できないことをテストします。
+func TestCreateTask_ProjectNotMember(t *testing.T) {
+	router, mockRepo, _, memberRepo := setupMemberTestHandler(t)
+
+	// モックリポジトリの期待動作を設定
+	memberRepo.On("GetProjectRole", uint(3), testUserID).Return("", nil)
+
+	// テストリクエストを作成（POST /tasks）
+	req, err := http.NewRequest(http.MethodPost, "/tasks", bytes.NewBufferString(`{"title":"新しいタスク","project_id":3}`))
+	assert.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+
+	// リクエストをルーターに送信
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Contains(t, w.Body.String(), `"reason":"not_a_member"`)
+	mockRepo.AssertNotCalled(t, "CreateTask", mock.Anything)
+}
+
+// TestGetProject_NotMember はメンバーではないプロジェクトは参照できないことをテストします。
+func TestGetProject_NotMember(t *testing.T) {
+	router, mockRepo, _, memberRepo := setupProjectMemberTestHandler(t)
+
+	// モックリポジトリの期待動作を設定
+	mockRepo.On("GetProjectByID", uint(3)).Return(&model.Project{ID: 3, Name: "他のチームのプロジェクト"}, nil)
+	memberRepo.On("GetProjectRole", uint(3), testUserID).Return("", nil)
+
+	// テストリクエストを作成（GET /projects/3）
+	req, err := http.NewRequest(http.MethodGet, "/projects/3", nil)
+	assert.NoError(t, err)
+
+	// リクエストをルーターに送信
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Contains(t, w.Body.String(), `"reason":"not_a_member"`)
+	assert.NotContains(t, w.Body.String(), "他のチームのプロジェクト")
+}
+
+// TestBatchTasks_Forbidden は一括操作で権限のない操作の結果に拒否の理由を含めることをテストします。
+func TestBatchTasks_Forbidden(t *testing.T) {
+	router, mockRepo, _, memberRepo := setupMemberTestHandler(t)
+
+	// モックリポジトリの期待動作を設定
+	mockRepo.On("Transaction", mock.Anything).Return(nil)
+	mockRepo.On("GetTaskByID", uint(1)).Return(&model.Task{ID: 1}, nil)
+	memberRepo.On("GetTaskRole", uint(1), testUserID).Return(model.RoleViewer, nil)
+
+	// テストリクエストを作成（POST /tasks/batch）
+	body := `{"mode":"partial","operations":[{"op":"delete","id":1}]}`
+	req, err := http.NewRequest(http.MethodPost, "/tasks/batch", bytes.NewBufferString(body))
+	assert.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+
+	// リクエストをルーターに送信
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response struct {
+		Data []batchResult `json:"data"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	if assert.Len(t, response.Data, 1) {
+		assert.Equal(t, http.StatusForbidden, response.Data[0].Status)
+		assert.Equal(t, policy.ReasonInsufficientRole, response.Data[0].Reason)
+	}
+	mockRepo.AssertNotCalled(t, "DeleteTask", mock.Anything)
+}
+
+// TestSetTaskMember はタスクの owner がユーザーにタスクを共有できることをテストします。
+func TestSetTaskMember(t *testing.T) {
+	router, mockRepo, _, memberRepo := setupMemberTestHandler(t)
+
+	// モックリポジトリの期待動作を設定
+	mockRepo.On("GetTaskByID", uint(1)).Return(&model.Task{ID: 1}, nil)
+	memberRepo.On("GetTaskRole", uint(1), testUserID).Return(model.RoleOwner, nil)
+	memberRepo.On("SetTaskMember", &model.TaskMember{TaskID: 1, UserID: 2, Role: model.RoleCommenter}).Return(nil)
+
+	// テストリクエストを作成（PUT /tasks/1/members/2）
+	req, err := http.NewRequest(http.MethodPut, "/tasks/1/members/2", bytes.NewBufferString(`{"role":"commenter"}`))
+	assert.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+
+	// リクエストをルーターに送信
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	memberRepo.AssertExpectations(t)
+}
+
+// TestSetTaskMember_NotWorkspaceMember はワークスペースのメンバーではないユーザーにはタスクを共有できないことをテストします。
+func TestSetTaskMember_NotWorkspaceMember(t *testing.T) {
+	router, mockRepo, _, memberRepo := setupMemberTestHandler(t)
+
+	// モックリポジトリの期待動作を設定
+	mockRepo.On("GetTaskByID", uint(1)).Return(&model.Task{ID: 1}, nil)
+	memberRepo.On("GetTaskRole", uint(1), testUserID).Return(model.RoleOwner, nil)
+	memberRepo.On("SetTaskMember", &model.TaskMember{TaskID: 1, UserID: 3, Role: model.RoleViewer}).Return(repository.ErrNotWorkspaceMember)
+
+	// テストリクエストを作成（PUT /tasks/1/members/3）
+	req, err := http.NewRequest(http.MethodPut, "/tasks/1/members/3", bytes.NewBufferString(`{"role":"viewer"}`))
+	assert.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+
+	// リクエストをルーターに送信
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Contains(t, w.Body.String(), "User not found")
+	memberRepo.AssertExpectations(t)
+}
+
+// TestSetTaskMember_Forbidden は editor のロールではタスクを共有できないことをテストします。
+func TestSetTaskMember_Forbidden(t *testing.T) {
+	router, mockRepo, _, memberRepo := setupMemberTestHandler(t)
+
+	// モックリポジトリの期待動作を設定
+	mockRepo.On("GetTaskByID", uint(1)).Return(&model.Task{ID: 1}, nil)
+	memberRepo.On("GetTaskRole", uint(1), testUserID).Return(model.RoleEditor, nil)
+
+	// テストリクエストを作成（PUT /tasks/1/members/2）
+	req, err := http.NewRequest(http.MethodPut, "/tasks/1/members/2", bytes.NewBufferString(`{"role":"owner"}`))
+	assert.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+
+	// リクエストをルーターに送信
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	memberRepo.AssertNotCalled(t, "SetTaskMember", mock.Anything)
+}
+
+// TestRemoveTaskMember_Self はロールによらず自分自身の共有を解除できることをテストします。
+func TestRemoveTaskMember_Self(t *testing.T) {
+	router, mockRepo, _, memberRepo := setupMemberTestHandler(t)
+
+	// モックリポジトリの期待動作を設定
+	mockRepo.On("GetTaskByID", uint(1)).Return(&model.Task{ID: 1}, nil)
+	memberRepo.On("RemoveTaskMember", uint(1), testUserID).Return(nil)
+
+	// テストリクエストを作成（DELETE /tasks/1/members/1）
+	req, err := http.NewRequest(http.MethodDelete, "/tasks/1/members/1", nil)
+	assert.NoError(t, err)
+
+	// リクエストをルーターに送信
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	memberRepo.AssertNotCalled(t, "GetTaskRole", mock.Anything, mock.Anything)
+	memberRepo.AssertExpectations(t)
+}
+
+// TestDeleteProject_Forbidden は owner 以外のロールではプロジェクトを削除できないことをテストします。
+func TestDeleteProject_Forbidden(t *testing.T) {
+	router, mockRepo, _, memberRepo := setupProjectMemberTestHandler(t)
+
+	// モックリポジトリの期待動作を設定
+	mockRepo.On("GetProjectByID", uint(1)).Return(&model.Project{ID: 1, Name: "共有プロジェクト"}, nil)
+	memberRepo.On("GetProjectRole", uint(1), testUserID).Return(model.RoleEditor, nil)
+
+	// テストリクエストを作成（DELETE /projects/1）
+	req, err := http.NewRequest(http.MethodDelete, "/projects/1", nil)
+	assert.NoError(t, err)
+
+	// リクエストをルーターに送信
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Contains(t, w.Body.String(), `"required_role":"owner"`)
+	mockRepo.AssertNotCalled(t, "DeleteProject", mock.Anything, mock.Anything)
+}
+
+// TestSetProjectMember_LastOwner は最後の owner のロールを変更できないことをテストします。
+func TestSetProjectMember_LastOwner(t *testing.T) {
+	router, mockRepo, _, memberRepo := setupProjectMemberTestHandler(t)
+
+	// モックリポジトリの期待動作を設定
+	mockRepo.On("GetProjectByID", uint(1)).Return(&model.Project{ID: 1}, nil)
+	memberRepo.On("GetProjectRole", uint(1), testUserID).Return(model.RoleOwner, nil)
+	memberRepo.On("SetProjectMember", &model.ProjectMember{ProjectID: 1, UserID: testUserID, Role: model.RoleEditor}).Return(repository.ErrLastOwner)
+
+	// テストリクエストを作成（PUT /projects/1/members/1）
+	req, err := http.NewRequest(http.MethodPut, "/projects/1/members/1", bytes.NewBufferString(`{"role":"editor"}`))
+	assert.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+
+	// リクエストをルーターに送信
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	memberRepo.AssertExpectations(t)
+}
+
+// TestSetProjectMember_NotWorkspaceMember はワークスペースのメンバーではないユーザーをプロジェクトに追加できないことをテストします。
+func TestSetProjectMember_NotWorkspaceMember(t *testing.T) {
+	router, mockRepo, _, memberRepo := setupProjectMemberTestHandler(t)
+
+	// モックリポジトリの期待動作を設定
+	mockRepo.On("GetProjectByID", uint(1)).Return(&model.Project{ID: 1}, nil)
+	memberRepo.On("GetProjectRole", uint(1), testUserID).Return(model.RoleOwner, nil)
+	memberRepo.On("SetProjectMember", &model.ProjectMember{ProjectID: 1, UserID: 3, Role: model.RoleEditor}).Return(repository.ErrNotWorkspaceMember)
+
+	// テストリクエストを作成（PUT /projects/1/members/3）
+	req, err := http.NewRequest(http.MethodPut, "/projects/1/members/3", bytes.NewBufferString(`{"role":"editor"}`))
+	assert.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+
+	// リクエストをルーターに送信
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Contains(t, w.Body.String(), "User not found")
+	memberRepo.AssertExpectations(t)
+}
+
+// TestRemoveProjectMember_NotFound はメンバーではないユーザーの場合に 404 を返すことをテストします。
+func TestRemoveProjectMember_NotFound(t *testing.T) {
+	router, mockRepo, _, memberRepo := setupProjectMemberTestHandler(t)
+
+	// モックリポジトリの期待動作を設定
+	mockRepo.On("GetProjectByID", uint(1)).Return(&model.Project{ID: 1}, nil)
+	memberRepo.On("GetProjectRole", uint(1), testUserID).Return(model.RoleOwner, nil)
+	memberRepo.On("RemoveProjectMember", uint(1), uint(5)).Return(gorm.ErrRecordNotFound)
+
+	// テストリクエストを作成（DELETE /projects/1/members/5）
+	req, err := http.NewRequest(http.MethodDelete, "/projects/1/members/5", nil)
+	assert.NoError(t, err)
+
+	// リクエストをルーターに送信
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	memberRepo.AssertExpectations(t)
+}
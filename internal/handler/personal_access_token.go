@@ -0,0 +1,112 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ryory2/test-go-app-todo-go/internal/middleware"
+	"github.com/ryory2/test-go-app-todo-go/internal/model"
+	"gorm.io/gorm"
+)
+
+// defaultPersonalAccessTokenDays は有効期限を指定しなかった場合の個人用アクセストークンの有効日数です
+const defaultPersonalAccessTokenDays = 30
+
+// GetPersonalAccessTokensハンドラー
+// HTTP: GET /auth/tokens
+func (h *AuthHandler) GetPersonalAccessTokens(c *gin.Context) {
+	tokens, err := h.users.GetPersonalAccessTokens(currentUserID(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve tokens"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": tokens})
+}
+
+// CreatePersonalAccessTokenハンドラー
+// HTTP: POST /auth/tokens
+//
+// 個人用アクセストークンを発行する。トークンはこのレスポンスでのみ返し、以降は確認できない
+func (h *AuthHandler) CreatePersonalAccessToken(c *gin.Context) {
+	var input struct {
+		Name          string   `json:"name" validate:"required,max=100"`
+		Scopes        []string `json:"scopes" validate:"required,min=1,dive,oneof=tasks:read tasks:write admin"`
+		ExpiresInDays *int     `json:"expires_in_days" validate:"omitempty,min=1,max=365"`
+	}
+
+	// リクエストボディをバインド
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON provided"})
+		return
+	}
+
+	// 入力値のバリデーション
+	if err := h.validate.Struct(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// 個人用アクセストークンで、自身より広いスコープのトークンは発行できない
+	if principal, ok := middleware.GetPrincipal(c); ok {
+		for _, scope := range input.Scopes {
+			if !principal.HasScope(scope) {
+				c.JSON(http.StatusForbidden, gin.H{"error": "Cannot grant a scope the current token does not have: " + scope})
+				return
+			}
+		}
+	}
+
+	days := defaultPersonalAccessTokenDays
+	if input.ExpiresInDays != nil {
+		days = *input.ExpiresInDays
+	}
+
+	secret, err := newRefreshToken()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create token"})
+		return
+	}
+	value := model.PersonalAccessTokenPrefix + secret
+	token := model.PersonalAccessToken{
+		UserID:      currentUserID(c),
+		Name:        input.Name,
+		TokenPrefix: value[:len(model.PersonalAccessTokenPrefix)+4],
+		TokenHash:   middleware.HashToken(value),
+		Scopes:      input.Scopes,
+		ExpiresAt:   time.Now().AddDate(0, 0, days),
+	}
+	if err := h.users.CreatePersonalAccessToken(&token); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create token"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"data": token, "token": value})
+}
+
+// DeletePersonalAccessTokenハンドラー
+// HTTP: DELETE /auth/tokens/{id}
+//
+// 個人用アクセストークンを失効させる
+func (h *AuthHandler) DeletePersonalAccessToken(c *gin.Context) {
+	// URLパラメータからIDを取得
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid token ID"})
+		return
+	}
+
+	if err := h.users.DeletePersonalAccessToken(currentUserID(c), uint(id)); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Token not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke token"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Token revoked successfully"})
+}
@@ -0,0 +1,142 @@
+// internal/handler/personal_access_token_test.go
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/ryory2/test-go-app-todo-go/internal/middleware"
+	"github.com/ryory2/test-go-app-todo-go/internal/model"
+	"github.com/ryory2/test-go-app-todo-go/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+// TestCreatePersonalAccessToken はトークンを発行し、ハッシュのみを保存することをテストします。
+func TestCreatePersonalAccessToken(t *testing.T) {
+	router, mockRepo := setupAuthTestHandler(t)
+
+	// モックリポジトリの期待動作を設定
+	var saved *model.PersonalAccessToken
+	mockRepo.On("CreatePersonalAccessToken", mock.AnythingOfType("*model.PersonalAccessToken")).Run(func(args mock.Arguments) {
+		saved = args.Get(0).(*model.PersonalAccessToken)
+		saved.ID = 1
+	}).Return(nil)
+
+	// テストリクエストを作成（POST /auth/tokens）
+	body := `{"name":"ci","scopes":["tasks:read"],"expires_in_days":7}`
+	req, err := http.NewRequest(http.MethodPost, "/auth/tokens", bytes.NewBufferString(body))
+	assert.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+
+	// リクエストをルーターに送信
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	var response struct {
+		Data  map[string]interface{} `json:"data"`
+		Token string                 `json:"token"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.True(t, strings.HasPrefix(response.Token, model.PersonalAccessTokenPrefix))
+	assert.Equal(t, "ci", response.Data["name"])
+	assert.NotContains(t, w.Body.String(), "token_hash")
+
+	assert.Equal(t, testUserID, saved.UserID)
+	assert.Equal(t, middleware.HashToken(response.Token), saved.TokenHash)
+	assert.True(t, strings.HasPrefix(response.Token, saved.TokenPrefix))
+	assert.WithinDuration(t, time.Now().AddDate(0, 0, 7), saved.ExpiresAt, time.Minute)
+
+	mockRepo.AssertExpectations(t)
+}
+
+// TestCreatePersonalAccessToken_ValidationError は不明なスコープの場合に 400 を返すことをテストします。
+func TestCreatePersonalAccessToken_ValidationError(t *testing.T) {
+	router, mockRepo := setupAuthTestHandler(t)
+
+	for _, body := range []string{`{"name":"ci","scopes":["tasks:delete"]}`, `{"name":"ci","scopes":[]}`, `{"name":"ci","scopes":["admin"],"expires_in_days":0}`} {
+		req, err := http.NewRequest(http.MethodPost, "/auth/tokens", bytes.NewBufferString(body))
+		assert.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, body)
+	}
+
+	mockRepo.AssertNotCalled(t, "CreatePersonalAccessToken", mock.Anything)
+}
+
+// TestCreatePersonalAccessToken_ScopeEscalation は個人用アクセストークンで自身にないスコープを付与できないことをテストします。
+func TestCreatePersonalAccessToken_ScopeEscalation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockRepo := new(repository.MockUserRepository)
+	handler := NewAuthHandler(mockRepo, testTokenManager(t), validator.New(), time.Hour, "default")
+	router := gin.Default()
+	router.POST("/auth/tokens", func(c *gin.Context) {
+		middleware.SetPrincipal(c, &middleware.Principal{UserID: testUserID, PersonalAccessTokenID: 1, Scopes: []string{model.ScopeTasksWrite}})
+	}, handler.CreatePersonalAccessToken)
+
+	req, err := http.NewRequest(http.MethodPost, "/auth/tokens", bytes.NewBufferString(`{"name":"ci","scopes":["tasks:read","admin"]}`))
+	assert.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	mockRepo.AssertNotCalled(t, "CreatePersonalAccessToken", mock.Anything)
+}
+
+// TestGetPersonalAccessTokens はユーザーのトークンの一覧を返すことをテストします。
+func TestGetPersonalAccessTokens(t *testing.T) {
+	router, mockRepo := setupAuthTestHandler(t)
+
+	// モックリポジトリの期待動作を設定
+	mockRepo.On("GetPersonalAccessTokens", testUserID).Return([]model.PersonalAccessToken{
+		{ID: 1, UserID: testUserID, Name: "ci", TokenPrefix: "todo_pat_abcd", TokenHash: "hash", Scopes: []string{model.ScopeTasksRead}},
+	}, nil)
+
+	// テストリクエストを作成（GET /auth/tokens）
+	req, err := http.NewRequest(http.MethodGet, "/auth/tokens", nil)
+	assert.NoError(t, err)
+
+	// リクエストをルーターに送信
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "todo_pat_abcd")
+	assert.NotContains(t, w.Body.String(), `"hash"`)
+
+	mockRepo.AssertExpectations(t)
+}
+
+// TestDeletePersonalAccessToken はトークンを失効させ、他のユーザーのトークンの場合は 404 を、不正なIDの場合は 400 を返すことをテストします。
+func TestDeletePersonalAccessToken(t *testing.T) {
+	router, mockRepo := setupAuthTestHandler(t)
+
+	// モックリポジトリの期待動作を設定
+	mockRepo.On("DeletePersonalAccessToken", testUserID, uint(1)).Return(nil)
+	mockRepo.On("DeletePersonalAccessToken", testUserID, uint(2)).Return(gorm.ErrRecordNotFound)
+
+	for id, expected := range map[string]int{"1": http.StatusOK, "2": http.StatusNotFound, "abc": http.StatusBadRequest, "0": http.StatusBadRequest, "-1": http.StatusBadRequest} {
+		req, err := http.NewRequest(http.MethodDelete, "/auth/tokens/"+id, nil)
+		assert.NoError(t, err)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, expected, w.Code, id)
+	}
+
+	mockRepo.AssertExpectations(t)
+}
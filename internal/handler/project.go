@@ -0,0 +1,382 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/ryory2/test-go-app-todo-go/internal/model"
+	"github.com/ryory2/test-go-app-todo-go/internal/policy"
+	"github.com/ryory2/test-go-app-todo-go/internal/repository"
+	"gorm.io/gorm"
+)
+
+// ProjectHandler構造体
+type ProjectHandler struct {
+	repo     repository.ProjectRepository
+	audit    repository.AuditRepository
+	members  repository.MemberRepository
+	validate *validator.Validate
+}
+
+// NewProjectHandler関数
+func NewProjectHandler(repo repository.ProjectRepository, audit repository.AuditRepository, members repository.MemberRepository, validate *validator.Validate) *ProjectHandler {
+	return &ProjectHandler{
+		repo:     repo,
+		audit:    audit,
+		members:  members,
+		validate: validate,
+	}
+}
+
+// GetProjectsハンドラー
+// HTTP: GET /projects
+//
+// 認証済みのユーザーがメンバーになっているプロジェクトのみを返す
+func (h *ProjectHandler) GetProjects(c *gin.Context) {
+	// アーカイブ済みのプロジェクトは明示的に指定された場合のみ含める
+	includeArchived := c.Query("include_archived") == "true"
+
+	projects, err := h.projects(c).GetProjects(includeArchived)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve projects"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": projects})
+}
+
+// GetProjectハンドラー
+// HTTP: GET /projects/{id}
+func (h *ProjectHandler) GetProject(c *gin.Context) {
+	project, ok := h.findProject(c)
+	if !ok {
+		return
+	}
+
+	// ロールの権限を確認
+	if !h.authorize(c, project.ID, policy.ActionView) {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": project})
+}
+
+// CreateProjectハンドラー
+// HTTP: POST /projects
+func (h *ProjectHandler) CreateProject(c *gin.Context) {
+	var input model.Project
+
+	// リクエストボディをバインド
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON provided"})
+		return
+	}
+
+	// 入力値のバリデーション
+	if err := h.validate.Struct(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// プロジェクトを作成
+	input.ArchivedAt = nil // 新規作成時はアーカイブしない
+	if err := h.projects(c).CreateProject(&input); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create project"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"data": input})
+}
+
+// UpdateProjectハンドラー
+// HTTP: PUT /projects/{id}
+func (h *ProjectHandler) UpdateProject(c *gin.Context) {
+	project, ok := h.findProject(c)
+	if !ok {
+		return
+	}
+
+	// ロールの権限を確認
+	if !h.authorize(c, project.ID, policy.ActionEdit) {
+		return
+	}
+
+	var input model.Project
+
+	// リクエストボディをバインド
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON provided"})
+		return
+	}
+
+	// 入力値のバリデーション
+	if err := h.validate.Struct(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// プロジェクトのフィールドを更新
+	project.Name = input.Name
+	project.Description = input.Description
+	project.UpdatedAt = time.Now()
+
+	if err := h.projects(c).UpdateProject(project); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update project"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": project})
+}
+
+// DeleteProjectハンドラー
+// HTTP: DELETE /projects/{id}?mode=detach|cascade|archive
+//
+// mode=detach（既定）: タスクはプロジェクトから外して残す
+// mode=cascade: プロジェクトに属するタスクも削除する
+// mode=archive: 削除せずにプロジェクトをアーカイブする
+func (h *ProjectHandler) DeleteProject(c *gin.Context) {
+	mode := c.DefaultQuery("mode", "detach")
+	if mode != "detach" && mode != "cascade" && mode != "archive" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid mode parameter"})
+		return
+	}
+
+	project, ok := h.findProject(c)
+	if !ok {
+		return
+	}
+
+	// ロールの権限を確認（アーカイブはアーカイブと同じ権限でよい）
+	action := policy.ActionDelete
+	if mode == "archive" {
+		action = policy.ActionEdit
+	}
+	if !h.authorize(c, project.ID, action) {
+		return
+	}
+
+	if mode == "archive" {
+		h.setArchived(c, project, true)
+		return
+	}
+
+	// プロジェクトを削除
+	changes, err := h.projects(c).DeleteProject(project, mode == "cascade")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete project"})
+		return
+	}
+
+	// 削除した、またはプロジェクトから外したタスクごとに監査ログに記録
+	for _, change := range changes {
+		auditAction := model.AuditActionProject
+		if change.After == nil {
+			auditAction = model.AuditActionDelete
+		}
+		writeTaskAudit(c, h.audit, auditAction, change.Before, change.After)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Project deleted successfully"})
+}
+
+// ArchiveProjectハンドラー
+// HTTP: POST /projects/{id}/archive
+func (h *ProjectHandler) ArchiveProject(c *gin.Context) {
+	project, ok := h.findProject(c)
+	if !ok {
+		return
+	}
+
+	// ロールの権限を確認
+	if !h.authorize(c, project.ID, policy.ActionEdit) {
+		return
+	}
+
+	h.setArchived(c, project, true)
+}
+
+// UnarchiveProjectハンドラー
+// HTTP: POST /projects/{id}/unarchive
+func (h *ProjectHandler) UnarchiveProject(c *gin.Context) {
+	project, ok := h.findProject(c)
+	if !ok {
+		return
+	}
+
+	// ロールの権限を確認
+	if !h.authorize(c, project.ID, policy.ActionEdit) {
+		return
+	}
+
+	h.setArchived(c, project, false)
+}
+
+// GetProjectTasksハンドラー
+// HTTP: GET /projects/{id}/tasks
+func (h *ProjectHandler) GetProjectTasks(c *gin.Context) {
+	project, ok := h.findProject(c)
+	if !ok {
+		return
+	}
+
+	// クエリパラメータを整数に変換
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if err != nil || limit <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit parameter"})
+		return
+	}
+
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid offset parameter"})
+		return
+	}
+
+	// アーカイブ済みのタスクは明示的に指定された場合のみ含める
+	includeArchived := c.Query("include_archived") == "true"
+
+	tasks, total, err := h.projects(c).GetProjectTasks(project.ID, includeArchived, limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve tasks"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":  tasks,
+		"total": total,
+	})
+}
+
+// MoveTasksハンドラー
+// HTTP: POST /projects/{id}/tasks
+func (h *ProjectHandler) MoveTasks(c *gin.Context) {
+	project, ok := h.findProject(c)
+	if !ok {
+		return
+	}
+
+	// ロールの権限を確認
+	if !h.authorize(c, project.ID, policy.ActionEdit) {
+		return
+	}
+
+	// アーカイブ済みのプロジェクトにはタスクを追加できない
+	if project.IsArchived() {
+		c.JSON(http.StatusConflict, gin.H{"error": "Project is archived"})
+		return
+	}
+
+	var input struct {
+		TaskIDs []uint `json:"task_ids" validate:"required,min=1"`
+	}
+
+	// リクエストボディをバインド
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON provided"})
+		return
+	}
+
+	// 入力値のバリデーション
+	if err := h.validate.Struct(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// タスクをプロジェクトへ移動（元のプロジェクトからは外れる）
+	changes, err := h.projects(c).MoveTasks(input.TaskIDs, &project.ID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to move tasks"})
+		return
+	}
+
+	// 移動したタスクごとに監査ログに記録
+	h.recordTaskAudits(c, model.AuditActionProject, changes)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Tasks moved successfully"})
+}
+
+// RemoveTaskハンドラー
+// HTTP: DELETE /projects/{id}/tasks/{task_id}
+func (h *ProjectHandler) RemoveTask(c *gin.Context) {
+	project, ok := h.findProject(c)
+	if !ok {
+		return
+	}
+
+	// ロールの権限を確認
+	if !h.authorize(c, project.ID, policy.ActionEdit) {
+		return
+	}
+
+	taskID, err := strconv.Atoi(c.Param("task_id"))
+	if err != nil || taskID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid task ID"})
+		return
+	}
+
+	// プロジェクトに属するタスクのみプロジェクトから外す
+	change, err := h.projects(c).RemoveTask(project.ID, uint(taskID))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove task from project"})
+		return
+	}
+
+	// 監査ログに記録
+	writeTaskAudit(c, h.audit, model.AuditActionProject, change.Before, change.After)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Task removed from project successfully"})
+}
+
+// setArchived はプロジェクトのアーカイブ状態を更新してレスポンスを返します
+func (h *ProjectHandler) setArchived(c *gin.Context, project *model.Project, archived bool) {
+	now := time.Now()
+	if archived {
+		project.ArchivedAt = &now
+	} else {
+		project.ArchivedAt = nil
+	}
+	project.UpdatedAt = now
+
+	if err := h.projects(c).UpdateProject(project); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update project"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": project})
+}
+
+// projects はプロジェクト内のタスクの操作を認証済みのユーザーが所有するタスクに絞り込んだリポジトリを返します
+func (h *ProjectHandler) projects(c *gin.Context) repository.ProjectRepository {
+	return h.repo.ForTenant(currentTenantID(c)).ForUser(currentUserID(c))
+}
+
+// findProject はURLパラメータのIDからプロジェクトを取得します。失敗時はエラーレスポンスを書き込み false を返します
+func (h *ProjectHandler) findProject(c *gin.Context) (*model.Project, bool) {
+	// URLパラメータからIDを取得
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid project ID"})
+		return nil, false
+	}
+
+	// 既存のプロジェクトを取得
+	project, err := h.projects(c).GetProjectByID(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
+		return nil, false
+	}
+	return project, true
+}
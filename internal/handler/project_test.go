@@ -0,0 +1,243 @@
+// internal/handler/project_test.go
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/ryory2/test-go-app-todo-go/internal/model"
+	"github.com/ryory2/test-go-app-todo-go/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+// setupProjectTestHandler はテスト用の Gin エンジンとモックプロジェクトリポジトリをセットアップします。
+func setupProjectTestHandler(t *testing.T) (*gin.Engine, *repository.MockProjectRepository) {
+	router, mockRepo, auditRepo := setupProjectAuditedTestHandler(t)
+	auditRepo.On("CreateAuditLog", mock.Anything).Return(nil).Maybe()
+	return router, mockRepo
+}
+
+// setupProjectAuditedTestHandler は監査ログのモックリポジトリも返す setupProjectTestHandler です。
+// 認証済みのユーザーはすべてのプロジェクトの owner として扱います
+func setupProjectAuditedTestHandler(t *testing.T) (*gin.Engine, *repository.MockProjectRepository, *repository.MockAuditRepository) {
+	router, mockRepo, auditRepo, memberRepo := setupProjectMemberTestHandler(t)
+	memberRepo.On("GetProjectRole", mock.Anything, testUserID).Return(model.RoleOwner, nil).Maybe()
+	return router, mockRepo, auditRepo
+}
+
+// setupProjectMemberTestHandler はメンバーのモックリポジトリも返す setupProjectAuditedTestHandler です。
+// ロールの期待動作は設定しないため、個別のテストで設定します
+func setupProjectMemberTestHandler(t *testing.T) (*gin.Engine, *repository.MockProjectRepository, *repository.MockAuditRepository, *repository.MockMemberRepository) {
+	gin.SetMode(gin.TestMode)
+	mockRepo := new(repository.MockProjectRepository)
+	auditRepo := new(repository.MockAuditRepository)
+	memberRepo := new(repository.MockMemberRepository)
+	validate := validator.New()
+	handler := NewProjectHandler(mockRepo, auditRepo, memberRepo, validate)
+	router := gin.Default()
+	router.Use(authenticateAs(testUserID))
+
+	// エンドポイントの登録
+	router.GET("/projects", handler.GetProjects)
+	router.POST("/projects", handler.CreateProject)
+	router.GET("/projects/:id", handler.GetProject)
+	router.PUT("/projects/:id", handler.UpdateProject)
+	router.DELETE("/projects/:id", handler.DeleteProject)
+	router.POST("/projects/:id/archive", handler.ArchiveProject)
+	router.POST("/projects/:id/unarchive", handler.UnarchiveProject)
+	router.GET("/projects/:id/tasks", handler.GetProjectTasks)
+	router.POST("/projects/:id/tasks", handler.MoveTasks)
+	router.DELETE("/projects/:id/tasks/:task_id", handler.RemoveTask)
+	router.GET("/projects/:id/members", handler.GetProjectMembers)
+	router.PUT("/projects/:id/members/:user_id", handler.SetProjectMember)
+	router.DELETE("/projects/:id/members/:user_id", handler.RemoveProjectMember)
+
+	// ワークスペース・認証済みのユーザーへの絞り込みは個別のテストで確認する
+	mockRepo.On("ForTenant", testTenantID).Return().Maybe()
+	mockRepo.On("ForUser", testUserID).Return().Maybe()
+	memberRepo.On("ForTenant", testTenantID).Return().Maybe()
+	auditRepo.On("ForTenant", testTenantID).Return().Maybe()
+
+	return router, mockRepo, auditRepo, memberRepo
+}
+
+// TestGetProjectTasks は GetProjectTasks ハンドラーの正常動作をテストします。
+func TestGetProjectTasks(t *testing.T) {
+	router, mockRepo := setupProjectTestHandler(t)
+
+	projectID := uint(1)
+	project := &model.Project{ID: projectID, Name: "バックエンド"}
+	tasks := []model.Task{
+		{ID: 1, Title: "タスク1", ProjectID: &projectID},
+		{ID: 2, Title: "タスク2", ProjectID: &projectID},
+	}
+
+	// モックリポジトリの期待動作を設定
+	mockRepo.On("GetProjectByID", projectID).Return(project, nil)
+	mockRepo.On("GetProjectTasks", projectID, false, 10, 0).Return(tasks, int64(2), nil)
+
+	// テストリクエストを作成（GET /projects/1/tasks）
+	req, err := http.NewRequest(http.MethodGet, "/projects/1/tasks", nil)
+	assert.NoError(t, err)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// レスポンスのステータスコードが 200 OK であることを確認
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// "data" と "total" が正しいことを確認
+	var response map[string]interface{}
+	err = json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	data, exists := response["data"].([]interface{})
+	assert.True(t, exists)
+	assert.Len(t, data, 2)
+	assert.Equal(t, float64(2), response["total"])
+
+	mockRepo.AssertExpectations(t)
+}
+
+// TestDeleteProject_Cascade は mode=cascade で他のメンバーのものを含めてタスクごと削除され、タスクごとに監査ログに記録されることをテストします。
+func TestDeleteProject_Cascade(t *testing.T) {
+	router, mockRepo, auditRepo := setupProjectAuditedTestHandler(t)
+
+	project := &model.Project{ID: 1, Name: "削除するプロジェクト"}
+
+	// モックリポジトリの期待動作を設定
+	mockRepo.On("GetProjectByID", uint(1)).Return(project, nil)
+	// 他のメンバーが所有するタスク（4）も削除し、ゴミ箱のタスク（5）はプロジェクトから外す
+	owner, member := testUserID, uint(9)
+	mockRepo.On("DeleteProject", project, true).Return([]repository.TaskChange{
+		{Before: &model.Task{ID: 3, Title: "タスク3", ProjectID: &project.ID, OwnerID: &owner}},
+		{Before: &model.Task{ID: 4, Title: "タスク4", ProjectID: &project.ID, OwnerID: &member}},
+		{
+			Before: &model.Task{ID: 5, Title: "タスク5", ProjectID: &project.ID, OwnerID: &member, Version: 1},
+			After:  &model.Task{ID: 5, Title: "タスク5", OwnerID: &member, Version: 2},
+		},
+	}, nil)
+	for id, action := range map[uint]string{3: model.AuditActionDelete, 4: model.AuditActionDelete, 5: model.AuditActionProject} {
+		id, action := id, action
+		auditRepo.On("CreateAuditLog", mock.MatchedBy(func(entry *model.AuditLog) bool {
+			return entry.Action == action && entry.ResourceID == id
+		})).Return(nil).Once()
+	}
+
+	// テストリクエストを作成（DELETE /projects/1?mode=cascade）
+	req, err := http.NewRequest(http.MethodDelete, "/projects/1?mode=cascade", nil)
+	assert.NoError(t, err)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// レスポンスのステータスコードが 200 OK であることを確認
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	mockRepo.AssertExpectations(t)
+	auditRepo.AssertExpectations(t)
+}
+
+// TestDeleteProject_Archive は mode=archive で削除せずにアーカイブされることをテストします。
+func TestDeleteProject_Archive(t *testing.T) {
+	router, mockRepo := setupProjectTestHandler(t)
+
+	project := &model.Project{ID: 1, Name: "アーカイブするプロジェクト"}
+
+	// モックリポジトリの期待動作を設定
+	mockRepo.On("GetProjectByID", uint(1)).Return(project, nil)
+	mockRepo.On("UpdateProject", mock.MatchedBy(func(p *model.Project) bool {
+		return p.ID == 1 && p.IsArchived()
+	})).Return(nil)
+
+	// テストリクエストを作成（DELETE /projects/1?mode=archive）
+	req, err := http.NewRequest(http.MethodDelete, "/projects/1?mode=archive", nil)
+	assert.NoError(t, err)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// レスポンスのステータスコードが 200 OK であることを確認
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// 削除が呼び出されていないことを確認
+	mockRepo.AssertNotCalled(t, "DeleteProject", mock.Anything, mock.Anything)
+	mockRepo.AssertExpectations(t)
+}
+
+// TestMoveTasks は MoveTasks ハンドラーの正常動作をテストします。
+func TestMoveTasks(t *testing.T) {
+	router, mockRepo := setupProjectTestHandler(t)
+
+	project := &model.Project{ID: 2, Name: "移動先"}
+
+	// モックリポジトリの期待動作を設定
+	mockRepo.On("GetProjectByID", uint(2)).Return(project, nil)
+	mockRepo.On("MoveTasks", []uint{1, 3}, mock.MatchedBy(func(id *uint) bool {
+		return id != nil && *id == 2
+	})).Return([]repository.TaskChange{
+		{Before: &model.Task{ID: 1, Version: 1}, After: &model.Task{ID: 1, ProjectID: &project.ID, Version: 2}},
+		{Before: &model.Task{ID: 3, Version: 1}, After: &model.Task{ID: 3, ProjectID: &project.ID, Version: 2}},
+	}, nil)
+
+	// テストリクエストを作成（POST /projects/2/tasks）
+	req, err := http.NewRequest(http.MethodPost, "/projects/2/tasks", bytes.NewBufferString(`{"task_ids":[1,3]}`))
+	assert.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// レスポンスのステータスコードが 200 OK であることを確認
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	mockRepo.AssertExpectations(t)
+}
+
+// TestMoveTasks_ArchivedProject はアーカイブ済みプロジェクトへの移動が 409 になることをテストします。
+func TestMoveTasks_ArchivedProject(t *testing.T) {
+	router, mockRepo := setupProjectTestHandler(t)
+
+	archivedAt := time.Now()
+	project := &model.Project{ID: 2, Name: "アーカイブ済み", ArchivedAt: &archivedAt}
+
+	// モックリポジトリの期待動作を設定
+	mockRepo.On("GetProjectByID", uint(2)).Return(project, nil)
+
+	req, err := http.NewRequest(http.MethodPost, "/projects/2/tasks", bytes.NewBufferString(`{"task_ids":[1]}`))
+	assert.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// レスポンスのステータスコードが 409 Conflict であることを確認
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	mockRepo.AssertNotCalled(t, "MoveTasks", mock.Anything, mock.Anything)
+	mockRepo.AssertExpectations(t)
+}
+
+// TestRemoveTask_TaskNotFound は存在しないタスクをプロジェクトから外そうとした場合に 404 を返すことをテストします。
+func TestRemoveTask_TaskNotFound(t *testing.T) {
+	router, mockRepo := setupProjectTestHandler(t)
+
+	project := &model.Project{ID: 1, Name: "プロジェクト"}
+
+	// モックリポジトリの期待動作を設定
+	mockRepo.On("GetProjectByID", uint(1)).Return(project, nil)
+	mockRepo.On("RemoveTask", uint(1), uint(99)).Return((*repository.TaskChange)(nil), gorm.ErrRecordNotFound)
+
+	// テストリクエストを作成（DELETE /projects/1/tasks/99）
+	req, err := http.NewRequest(http.MethodDelete, "/projects/1/tasks/99", nil)
+	assert.NoError(t, err)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// レスポンスのステータスコードが 404 Not Found であることを確認
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	mockRepo.AssertExpectations(t)
+}
@@ -0,0 +1,188 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/ryory2/test-go-app-todo-go/internal/middleware"
+	"github.com/ryory2/test-go-app-todo-go/internal/model"
+	"github.com/ryory2/test-go-app-todo-go/internal/repository"
+	"gorm.io/gorm"
+)
+
+// TagHandler構造体
+type TagHandler struct {
+	repo     repository.TagRepository
+	validate *validator.Validate
+}
+
+// NewTagHandler関数
+func NewTagHandler(repo repository.TagRepository, validate *validator.Validate) *TagHandler {
+	return &TagHandler{
+		repo:     repo,
+		validate: validate,
+	}
+}
+
+// GetTagsハンドラー
+// HTTP: GET /tags
+func (h *TagHandler) GetTags(c *gin.Context) {
+	// リポジトリを使用してタグを取得
+	tags, err := h.tags(c).GetTags()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve tags"})
+		return
+	}
+
+	// レスポンスを送信
+	c.JSON(http.StatusOK, gin.H{"data": tags})
+}
+
+// GetTagハンドラー
+// HTTP: GET /tags/{id}
+func (h *TagHandler) GetTag(c *gin.Context) {
+	tag, ok := h.findTag(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": tag})
+}
+
+// CreateTagハンドラー
+// HTTP: POST /tags
+func (h *TagHandler) CreateTag(c *gin.Context) {
+	var input model.Tag
+
+	// リクエストボディをバインド
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON provided"})
+		return
+	}
+
+	// 入力値のバリデーション
+	if err := h.validate.Struct(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// 認証済みのユーザーを所有者としてタグを作成
+	userID := currentUserID(c)
+	input.OwnerID = &userID
+	if err := h.tags(c).CreateTag(&input); err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Tag already exists"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create tag"})
+		return
+	}
+
+	// 作成されたタグを返す
+	c.JSON(http.StatusCreated, gin.H{"data": input})
+}
+
+// UpdateTagハンドラー
+// HTTP: PUT /tags/{id}
+func (h *TagHandler) UpdateTag(c *gin.Context) {
+	tag, ok := h.findTag(c)
+	if !ok {
+		return
+	}
+
+	// タグの所有者またはワークスペースの管理者であることを確認
+	if !authorizeTag(c, tag) {
+		return
+	}
+
+	var input model.Tag
+
+	// リクエストボディをバインド
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON provided"})
+		return
+	}
+
+	// 入力値のバリデーション
+	if err := h.validate.Struct(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// タグを更新
+	tag.Name = input.Name
+	if err := h.tags(c).UpdateTag(tag); err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Tag already exists"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update tag"})
+		return
+	}
+
+	// 更新されたタグを返す
+	c.JSON(http.StatusOK, gin.H{"data": tag})
+}
+
+// DeleteTagハンドラー
+// HTTP: DELETE /tags/{id}
+func (h *TagHandler) DeleteTag(c *gin.Context) {
+	tag, ok := h.findTag(c)
+	if !ok {
+		return
+	}
+
+	// タグの所有者またはワークスペースの管理者であることを確認
+	if !authorizeTag(c, tag) {
+		return
+	}
+
+	// タグを削除（タスクとの関連も削除される）
+	if err := h.tags(c).DeleteTag(tag); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete tag"})
+		return
+	}
+
+	// 削除成功のレスポンスを送信
+	c.JSON(http.StatusOK, gin.H{"message": "Tag deleted successfully"})
+}
+
+// findTag はURLパラメータのIDからタグを取得します。失敗時はエラーレスポンスを書き込み false を返します
+func (h *TagHandler) findTag(c *gin.Context) (*model.Tag, bool) {
+	// URLパラメータからIDを取得
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid tag ID"})
+		return nil, false
+	}
+
+	// 既存のタグを取得
+	tag, err := h.tags(c).GetTagByID(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Tag not found"})
+		return nil, false
+	}
+	return tag, true
+}
+
+// authorizeTag はタグがワークスペースの全員のタスクで使われるため、変更・削除をタグの所有者とワークスペースの管理者に限ります。
+// 許可されない場合はエラーレスポンスを書き込み false を返します
+func authorizeTag(c *gin.Context, tag *model.Tag) bool {
+	if c.GetString(middleware.WorkspaceRoleKey) == model.WorkspaceRoleAdmin {
+		return true
+	}
+	if tag.OwnerID != nil && *tag.OwnerID == currentUserID(c) {
+		return true
+	}
+	c.JSON(http.StatusForbidden, gin.H{"error": "Only the tag owner or a workspace admin can change this tag", "reason": "not_tag_owner"})
+	return false
+}
+
+// tags はリクエストのワークスペースのタグのみを操作するリポジトリを返します
+func (h *TagHandler) tags(c *gin.Context) repository.TagRepository {
+	return h.repo.ForTenant(currentTenantID(c))
+}
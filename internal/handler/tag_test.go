@@ -0,0 +1,226 @@
+// internal/handler/tag_test.go
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/ryory2/test-go-app-todo-go/internal/middleware"
+	"github.com/ryory2/test-go-app-todo-go/internal/model"
+	"github.com/ryory2/test-go-app-todo-go/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+// setupTagTestHandler はテスト用の Gin エンジンとモックタグリポジトリをセットアップします。
+// 認証済みのユーザーはワークスペースの member として扱います
+func setupTagTestHandler(t *testing.T) (*gin.Engine, *repository.MockTagRepository) {
+	return setupTagTestHandlerWithRole(t, model.WorkspaceRoleMember)
+}
+
+// setupTagTestHandlerWithRole は認証済みのユーザーのワークスペースでのロールを指定する setupTagTestHandler です。
+func setupTagTestHandlerWithRole(t *testing.T, role string) (*gin.Engine, *repository.MockTagRepository) {
+	gin.SetMode(gin.TestMode)
+	mockRepo := new(repository.MockTagRepository)
+	validate := validator.New()
+	handler := NewTagHandler(mockRepo, validate)
+	router := gin.Default()
+	router.Use(authenticateAs(testUserID))
+	router.Use(func(c *gin.Context) {
+		middleware.SetTenant(c, &model.Workspace{ID: testTenantID, Slug: "test"}, role)
+		c.Next()
+	})
+
+	// エンドポイントの登録
+	router.GET("/tags", handler.GetTags)
+	router.POST("/tags", handler.CreateTag)
+	router.GET("/tags/:id", handler.GetTag)
+	router.PUT("/tags/:id", handler.UpdateTag)
+	router.DELETE("/tags/:id", handler.DeleteTag)
+
+	// ワークスペースへの絞り込みは個別のテストで確認する
+	mockRepo.On("ForTenant", testTenantID).Return().Maybe()
+
+	return router, mockRepo
+}
+
+// TestGetTags は GetTags ハンドラーの正常動作をテストします。
+func TestGetTags(t *testing.T) {
+	router, mockRepo := setupTagTestHandler(t)
+
+	// モックリポジトリの期待動作を設定
+	mockRepo.On("GetTags").Return([]model.Tag{{ID: 1, Name: "backend"}, {ID: 2, Name: "frontend"}}, nil)
+
+	// テストリクエストを作成
+	req, err := http.NewRequest(http.MethodGet, "/tags", nil)
+	assert.NoError(t, err)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// レスポンスのステータスコードが 200 OK であることを確認
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// "data" フィールドにタグ一覧が含まれていることを確認
+	var response map[string]interface{}
+	err = json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	data, exists := response["data"].([]interface{})
+	assert.True(t, exists)
+	assert.Len(t, data, 2)
+
+	mockRepo.AssertExpectations(t)
+}
+
+// TestCreateTag は CreateTag ハンドラーの正常動作をテストします。
+func TestCreateTag(t *testing.T) {
+	router, mockRepo := setupTagTestHandler(t)
+
+	// モックリポジトリの期待動作を設定
+	mockRepo.On("CreateTag", mock.MatchedBy(func(tag *model.Tag) bool {
+		return tag.OwnerID != nil && *tag.OwnerID == testUserID
+	})).Return(nil).Run(func(args mock.Arguments) {
+		tag := args.Get(0).(*model.Tag)
+		tag.ID = 1
+	})
+
+	// テストリクエストを作成（POST /tags）
+	req, err := http.NewRequest(http.MethodPost, "/tags", bytes.NewBufferString(`{"name":"backend"}`))
+	assert.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// レスポンスのステータスコードが 201 Created であることを確認
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	// "data" フィールドに作成されたタグが含まれていることを確認
+	var response map[string]interface{}
+	err = json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	data, exists := response["data"].(map[string]interface{})
+	assert.True(t, exists)
+	assert.Equal(t, float64(1), data["id"])
+	assert.Equal(t, "backend", data["name"])
+
+	mockRepo.AssertExpectations(t)
+}
+
+// TestCreateTag_Duplicate は同名のタグが既に存在する場合に 409 を返すことをテストします。
+func TestCreateTag_Duplicate(t *testing.T) {
+	router, mockRepo := setupTagTestHandler(t)
+
+	// 一意制約違反を返すように設定
+	mockRepo.On("CreateTag", mock.AnythingOfType("*model.Tag")).Return(gorm.ErrDuplicatedKey)
+
+	req, err := http.NewRequest(http.MethodPost, "/tags", bytes.NewBufferString(`{"name":"backend"}`))
+	assert.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// レスポンスのステータスコードが 409 Conflict であることを確認
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	mockRepo.AssertExpectations(t)
+}
+
+// TestDeleteTag はタグの所有者がタグを削除できることをテストします。
+func TestDeleteTag(t *testing.T) {
+	router, mockRepo := setupTagTestHandler(t)
+
+	ownerID := testUserID
+	existingTag := &model.Tag{ID: 1, Name: "backend", OwnerID: &ownerID}
+
+	// モックリポジトリの期待動作を設定
+	mockRepo.On("GetTagByID", uint(1)).Return(existingTag, nil)
+	mockRepo.On("DeleteTag", existingTag).Return(nil)
+
+	// テストリクエストを作成（DELETE /tags/1）
+	req, err := http.NewRequest(http.MethodDelete, "/tags/1", nil)
+	assert.NoError(t, err)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// レスポンスのステータスコードが 200 OK であることを確認
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	mockRepo.AssertExpectations(t)
+}
+
+// TestDeleteTag_NotOwner はタグの所有者でないメンバーはタグを削除できないことをテストします。
+func TestDeleteTag_NotOwner(t *testing.T) {
+	router, mockRepo := setupTagTestHandler(t)
+
+	ownerID := uint(5)
+	mockRepo.On("GetTagByID", uint(1)).Return(&model.Tag{ID: 1, Name: "backend", OwnerID: &ownerID}, nil)
+
+	req, err := http.NewRequest(http.MethodDelete, "/tags/1", nil)
+	assert.NoError(t, err)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Contains(t, w.Body.String(), "not_tag_owner")
+	mockRepo.AssertNotCalled(t, "DeleteTag", mock.Anything)
+}
+
+// TestUpdateTag_NoOwner は所有者のないタグを member は変更できないことをテストします。
+func TestUpdateTag_NoOwner(t *testing.T) {
+	router, mockRepo := setupTagTestHandler(t)
+
+	mockRepo.On("GetTagByID", uint(1)).Return(&model.Tag{ID: 1, Name: "backend"}, nil)
+
+	req, err := http.NewRequest(http.MethodPut, "/tags/1", bytes.NewBufferString(`{"name":"server"}`))
+	assert.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	mockRepo.AssertNotCalled(t, "UpdateTag", mock.Anything)
+}
+
+// TestUpdateTag_WorkspaceAdmin はワークスペースの管理者が他のユーザーのタグを変更できることをテストします。
+func TestUpdateTag_WorkspaceAdmin(t *testing.T) {
+	router, mockRepo := setupTagTestHandlerWithRole(t, model.WorkspaceRoleAdmin)
+
+	ownerID := uint(5)
+	tag := &model.Tag{ID: 1, Name: "backend", OwnerID: &ownerID}
+	mockRepo.On("GetTagByID", uint(1)).Return(tag, nil)
+	mockRepo.On("UpdateTag", mock.MatchedBy(func(tag *model.Tag) bool {
+		return tag.Name == "server"
+	})).Return(nil)
+
+	req, err := http.NewRequest(http.MethodPut, "/tags/1", bytes.NewBufferString(`{"name":"server"}`))
+	assert.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockRepo.AssertExpectations(t)
+}
+
+// TestGetTags_ScopedToTenant はタグの一覧をリクエストのワークスペースのタグに絞り込むことをテストします。
+func TestGetTags_ScopedToTenant(t *testing.T) {
+	router, mockRepo := setupTagTestHandler(t)
+
+	// モックリポジトリの期待動作を設定
+	mockRepo.On("GetTags").Return([]model.Tag{}, nil)
+
+	// テストリクエストを作成
+	req, err := http.NewRequest(http.MethodGet, "/tags", nil)
+	assert.NoError(t, err)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	mockRepo.AssertCalled(t, "ForTenant", testTenantID)
+}
@@ -1,63 +1,154 @@
 package handler
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
+	"sort"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
 	"github.com/ryory2/test-go-app-todo-go/internal/model"
+	"github.com/ryory2/test-go-app-todo-go/internal/policy"
 	"github.com/ryory2/test-go-app-todo-go/internal/repository"
+	"github.com/ryory2/test-go-app-todo-go/pkg/jsonpatch"
+	"github.com/ryory2/test-go-app-todo-go/pkg/rrule"
+	"gorm.io/gorm"
+)
+
+const (
+	// maxOccurrenceRange は発生予定を展開できる期間の上限です
+	maxOccurrenceRange = 366 * 24 * time.Hour
+	// maxOccurrencesPerTask は1つのタスクから展開する発生予定の上限です
+	maxOccurrencesPerTask = 100
+	// maxSearchQueryLength はキーワード検索の最大バイト数です
+	maxSearchQueryLength = 200
 )
 
 // TaskHandler構造体
 type TaskHandler struct {
 	repo     repository.TaskRepository
+	audit    repository.AuditRepository
+	members  repository.MemberRepository
 	validate *validator.Validate
+	// eventLag は担当者の変更イベントを読み出すまでの待ち時間です（コミットが遅れたイベントを読み飛ばさないため）
+	eventLag time.Duration
 }
 
 // NewTaskHandler関数
-func NewTaskHandler(repo repository.TaskRepository, validate *validator.Validate) *TaskHandler {
+func NewTaskHandler(repo repository.TaskRepository, audit repository.AuditRepository, members repository.MemberRepository, validate *validator.Validate, eventLag time.Duration) *TaskHandler {
 	return &TaskHandler{
 		repo:     repo,
+		audit:    audit,
+		members:  members,
 		validate: validate,
+		eventLag: eventLag,
 	}
 }
 
 // GetTasksハンドラー
 // HTTP: GET /tasks
 func (h *TaskHandler) GetTasks(c *gin.Context) {
-	// クエリパラメータの取得
-	status := c.Query("status")
-	limitStr := c.DefaultQuery("limit", "10")
-	offsetStr := c.DefaultQuery("offset", "0")
+	// クエリパラメータを検証して絞り込み条件に変換
+	filter, err := parseTaskFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
-	// クエリパラメータを整数に変換
-	limit, err := strconv.Atoi(limitStr)
-	if err != nil || limit <= 0 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit parameter"})
+	// 繰り返しタスクの発生予定を展開する期間
+	expandFrom, expandTo, expand, err := parseOccurrenceRange(c.Query("occurrences_from"), c.Query("occurrences_to"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	offset, err := strconv.Atoi(offsetStr)
-	if err != nil || offset < 0 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid offset parameter"})
+	// レスポンスに含めるフィールド（例: fields=id,title,is_completed）
+	fields, err := parseFields(c.Query("fields"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
 	// リポジトリを使用してタスクを取得
-	tasks, total, err := h.repo.GetTasks(status, limit, offset)
+	page, err := h.tasks(c).GetTasks(filter)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve tasks"})
 		return
 	}
 
+	data, err := selectFields(page.Tasks, fields)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to encode response"})
+		return
+	}
+
 	// レスポンスを送信
-	c.JSON(http.StatusOK, gin.H{
-		"data":  tasks,
-		"total": total,
-	})
+	body := gin.H{"data": data}
+	if !filter.SkipCount {
+		body["total"] = page.Total
+	}
+	if page.NextCursor != "" {
+		body["next_cursor"] = page.NextCursor
+	}
+	if page.PrevCursor != "" {
+		body["prev_cursor"] = page.PrevCursor
+	}
+	if expand {
+		// 発生予定はページに関係なく、絞り込み条件に一致するすべての繰り返しタスクから展開する
+		recurring, err := h.tasks(c).GetRecurringTasks(filter, expandTo)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve tasks"})
+			return
+		}
+		body["occurrences"] = expandOccurrences(recurring, expandFrom, expandTo)
+	}
+	respondWithETag(c, body)
+}
+
+// GetTaskハンドラー
+// HTTP: GET /tasks/{id}
+func (h *TaskHandler) GetTask(c *gin.Context) {
+	// URLパラメータからIDを取得
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid task ID"})
+		return
+	}
+
+	// レスポンスに含めるフィールド
+	fields, err := parseFields(c.Query("fields"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// 既存のタスクを取得
+	task, err := h.tasks(c).GetTaskByID(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+		return
+	}
+
+	// If-None-Match が現在の ETag と一致する場合は 304 を返す
+	etag := taskETag(task)
+	c.Header("ETag", etag)
+	if header := c.GetHeader("If-None-Match"); header != "" && etagMatches(header, etag, true) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	data, err := selectFields(task, fields)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to encode response"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": data})
 }
 
 // CreateTaskハンドラー
@@ -71,21 +162,17 @@ func (h *TaskHandler) CreateTask(c *gin.Context) {
 		return
 	}
 
-	// 入力値のバリデーション
-	if err := h.validate.Struct(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
-	}
-
 	// タスクを作成
-	input.IsCompleted = false // 新規作成時は未完了とする
-	if err := h.repo.CreateTask(&input); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create task"})
+	if err := h.createTask(c, h.tasks(c), &input); err != nil {
+		respondError(c, err, "Failed to create task")
 		return
 	}
 
+	// 監査ログに記録
+	h.recordTaskAudit(c, model.AuditActionCreate, nil, &input)
+
 	// 作成されたタスクを返す
-	c.JSON(http.StatusCreated, gin.H{"data": input})
+	respondTask(c, http.StatusCreated, &input)
 }
 
 // UpdateTaskハンドラー
@@ -100,12 +187,23 @@ func (h *TaskHandler) UpdateTask(c *gin.Context) {
 	}
 
 	// 既存のタスクを取得
-	task, err := h.repo.GetTaskByID(uint(id))
+	task, err := h.tasks(c).GetTaskByID(uint(id))
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
 		return
 	}
 
+	// ロールの権限を確認
+	if !h.authorize(c, task.ID, policy.ActionEdit) {
+		return
+	}
+
+	// If-Match が指定されている場合は現在のバージョンと一致するかを確認
+	if !checkIfMatch(c, task) {
+		return
+	}
+	before := *task // 監査ログ用に変更前の状態を保持
+
 	var input model.Task
 
 	// リクエストボディをバインド
@@ -120,26 +218,104 @@ func (h *TaskHandler) UpdateTask(c *gin.Context) {
 		return
 	}
 
+	// 繰り返しルールの検証
+	if err := normalizeRRule(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	// タスクのフィールドを更新
 	task.Title = input.Title
 	task.Description = input.Description
 	task.DueDate = input.DueDate
 	task.IsCompleted = input.IsCompleted
+	task.RRule = input.RRule
+	task.Priority = input.Priority
+	if task.Priority == "" {
+		task.Priority = model.PriorityNone
+	}
 	task.UpdatedAt = time.Now()
 
 	// タスクを更新
-	if err := h.repo.UpdateTask(task); err != nil {
+	if err := h.tasks(c).UpdateTask(task); err != nil {
+		if respondVersionConflict(c, err) {
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update task"})
 		return
 	}
 
-	// 更新されたタスクを返す
-	c.JSON(http.StatusOK, gin.H{"data": task})
+	// 監査ログに記録
+	h.recordTaskAudit(c, model.AuditActionUpdate, &before, task)
+
+	// 更新されたタスクと取り消しトークンを返す
+	respondTaskWithUndo(c, task, h.issueUndoToken(c, model.UndoActionUpdate, &before, task, nil))
+}
+
+// PatchTaskハンドラー
+// HTTP: PATCH /tasks/{id}
+//
+// Content-Type: application/merge-patch+json（RFC 7396）または application/json-patch+json（RFC 6902）。
+// 指定されたフィールドのみを変更し、変更されたフィールドのみを検証する
+func (h *TaskHandler) PatchTask(c *gin.Context) {
+	// URLパラメータからIDを取得
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid task ID"})
+		return
+	}
+
+	// 既存のタスクを取得
+	task, err := h.tasks(c).GetTaskByID(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+		return
+	}
+
+	// ロールの権限を確認
+	if !h.authorize(c, task.ID, policy.ActionEdit) {
+		return
+	}
+
+	// If-Match が指定されている場合は現在のバージョンと一致するかを確認
+	if !checkIfMatch(c, task) {
+		return
+	}
+	before := *task // 監査ログ用に変更前の状態を保持
+
+	// リクエストボディを読み込む
+	body, err := c.GetRawData()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON provided"})
+		return
+	}
+
+	// パッチを適用してタスクを更新
+	if err := h.applyPatch(h.tasks(c), task, c.ContentType(), body); err != nil {
+		respondError(c, err, "Failed to update task")
+		return
+	}
+
+	// 監査ログに記録
+	h.recordTaskAudit(c, model.AuditActionUpdate, &before, task)
+
+	// 更新されたタスクと取り消しトークンを返す
+	respondTaskWithUndo(c, task, h.issueUndoToken(c, model.UndoActionUpdate, &before, task, nil))
 }
 
 // DeleteTaskハンドラー
 // HTTP: DELETE /tasks/{id}
+//
+// children=promote（既定）: 子タスクは削除するタスクの親へ付け替える
+// children=cascade: 子孫タスクもすべて削除する
 func (h *TaskHandler) DeleteTask(c *gin.Context) {
+	children := c.DefaultQuery("children", "promote")
+	if children != "promote" && children != "cascade" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid children parameter"})
+		return
+	}
+
 	// URLパラメータからIDを取得
 	idStr := c.Param("id")
 	id, err := strconv.Atoi(idStr)
@@ -149,24 +325,52 @@ func (h *TaskHandler) DeleteTask(c *gin.Context) {
 	}
 
 	// 既存のタスクを取得
-	task, err := h.repo.GetTaskByID(uint(id))
+	task, err := h.tasks(c).GetTaskByID(uint(id))
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
 		return
 	}
 
-	// タスクを削除
-	if err := h.repo.DeleteTask(task); err != nil {
+	// ロールの権限を確認
+	if !h.authorize(c, task.ID, policy.ActionDelete) {
+		return
+	}
+
+	// If-Match が指定されている場合は現在のバージョンと一致するかを確認
+	if !checkIfMatch(c, task) {
+		return
+	}
+
+	// タスクを削除（取り消し時に元に戻せるよう、親を付け替えた子タスクを記録しておく）
+	var childIDs []uint
+	if children == "cascade" {
+		err = h.tasks(c).DeleteTaskTree(task)
+	} else {
+		childIDs, err = h.tasks(c).DeleteTask(task)
+	}
+	if err != nil {
+		if respondVersionConflict(c, err) {
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete task"})
 		return
 	}
 
+	// 監査ログに記録
+	h.recordTaskAudit(c, model.AuditActionDelete, task, nil)
+
 	// 削除成功のレスポンスを送信
-	c.JSON(http.StatusOK, gin.H{"message": "Task deleted successfully"})
+	body := gin.H{"message": "Task deleted successfully"}
+	if undo := h.issueUndoToken(c, model.UndoActionDelete, task, task, childIDs); undo != nil {
+		body["undo"] = undo
+	}
+	c.JSON(http.StatusOK, body)
 }
 
 // ToggleTaskハンドラー
 // HTTP: PATCH /tasks/{id}/toggle
+//
+// cascade=true を指定して完了にした場合は子孫タスクもすべて完了にする
 func (h *TaskHandler) ToggleTask(c *gin.Context) {
 	// URLパラメータからIDを取得
 	idStr := c.Param("id")
@@ -177,18 +381,480 @@ func (h *TaskHandler) ToggleTask(c *gin.Context) {
 	}
 
 	// 既存のタスクを取得
-	task, err := h.repo.GetTaskByID(uint(id))
+	task, err := h.tasks(c).GetTaskByID(uint(id))
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
 		return
 	}
 
+	// ロールの権限を確認
+	if !h.authorize(c, task.ID, policy.ActionEdit) {
+		return
+	}
+
+	// If-Match が指定されている場合は現在のバージョンと一致するかを確認
+	if !checkIfMatch(c, task) {
+		return
+	}
+	before := *task // 監査ログ用に変更前の状態を保持
+
 	// タスクの完了状態をトグル
-	if err := h.repo.ToggleTaskCompletion(task); err != nil {
+	var childIDs []uint
+	toggle := h.tasks(c).ToggleTaskCompletion
+	if c.Query("cascade") == "true" {
+		toggle = h.tasks(c).ToggleTaskCompletionCascade
+
+		// 取り消し時に未完了へ戻せるよう、一緒に完了にする子孫タスクを記録しておく
+		if !task.IsCompleted {
+			subtree, err := h.tasks(c).GetSubtree(task.ID)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to toggle task completion"})
+				return
+			}
+			for _, t := range subtree {
+				if t.ID != task.ID && !t.IsCompleted {
+					childIDs = append(childIDs, t.ID)
+				}
+			}
+		}
+	}
+	if err := toggle(task); err != nil {
+		if respondVersionConflict(c, err) {
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to toggle task completion"})
 		return
 	}
 
+	// 監査ログに記録
+	h.recordTaskAudit(c, model.AuditActionToggle, &before, task)
+
+	// 更新されたタスクと取り消しトークンを返す
+	respondTaskWithUndo(c, task, h.issueUndoToken(c, model.UndoActionToggle, &before, task, childIDs))
+}
+
+// SetTaskTagsハンドラー
+// HTTP: PUT /tasks/{id}/tags
+func (h *TaskHandler) SetTaskTags(c *gin.Context) {
+	// URLパラメータからIDを取得
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid task ID"})
+		return
+	}
+
+	// 既存のタスクを取得
+	task, err := h.tasks(c).GetTaskByID(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+		return
+	}
+
+	// ロールの権限を確認
+	if !h.authorize(c, task.ID, policy.ActionEdit) {
+		return
+	}
+
+	// If-Match が指定されている場合は現在のバージョンと一致するかを確認
+	if !checkIfMatch(c, task) {
+		return
+	}
+	before := *task // 監査ログ用に変更前の状態を保持
+
+	var input struct {
+		TagIDs []uint `json:"tag_ids"`
+	}
+
+	// リクエストボディをバインド
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON provided"})
+		return
+	}
+
+	// タスクのタグを置き換え
+	if err := h.tasks(c).SetTaskTags(task, input.TagIDs); err != nil {
+		if respondVersionConflict(c, err) {
+			return
+		}
+		if errors.Is(err, repository.ErrTagNotFound) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Tag not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update task tags"})
+		return
+	}
+
+	// 監査ログに記録
+	h.recordTaskAudit(c, model.AuditActionTags, &before, task)
+
+	// 更新されたタスクを返す
+	respondTask(c, http.StatusOK, task)
+}
+
+// GetTaskChildrenハンドラー
+// HTTP: GET /tasks/{id}/children
+func (h *TaskHandler) GetTaskChildren(c *gin.Context) {
+	// URLパラメータからIDを取得
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid task ID"})
+		return
+	}
+
+	// 既存のタスクを取得
+	if _, err := h.tasks(c).GetTaskByID(uint(id)); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+		return
+	}
+
+	// 直下の子タスクを取得
+	children, err := h.tasks(c).GetChildren(uint(id))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve tasks"})
+		return
+	}
+
+	respondWithETag(c, gin.H{"data": children})
+}
+
+// GetTaskHistoryハンドラー
+// HTTP: GET /tasks/{id}/history
+func (h *TaskHandler) GetTaskHistory(c *gin.Context) {
+	// URLパラメータからIDを取得
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid task ID"})
+		return
+	}
+
+	// 既存のタスクを取得
+	if _, err := h.tasks(c).GetTaskByID(uint(id)); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+		return
+	}
+
+	// 完了・再オープンの履歴を古い順に取得
+	events, err := h.tasks(c).GetCompletionHistory(uint(id))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve task history"})
+		return
+	}
+
+	respondWithETag(c, gin.H{"data": events})
+}
+
+// GetTaskSubtreeハンドラー
+// HTTP: GET /tasks/{id}/subtree
+func (h *TaskHandler) GetTaskSubtree(c *gin.Context) {
+	// URLパラメータからIDを取得
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid task ID"})
+		return
+	}
+
+	// サブツリーに含まれるタスクを取得
+	tasks, err := h.tasks(c).GetSubtree(uint(id))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve tasks"})
+		return
+	}
+
+	// ツリー構造に組み立て
+	tree := model.BuildTaskTree(tasks, uint(id))
+	if tree == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+		return
+	}
+
+	respondWithETag(c, gin.H{"data": tree})
+}
+
+// SetTaskParentハンドラー
+// HTTP: PUT /tasks/{id}/parent
+func (h *TaskHandler) SetTaskParent(c *gin.Context) {
+	// URLパラメータからIDを取得
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid task ID"})
+		return
+	}
+
+	// 既存のタスクを取得
+	task, err := h.tasks(c).GetTaskByID(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+		return
+	}
+
+	// ロールの権限を確認
+	if !h.authorize(c, task.ID, policy.ActionEdit) {
+		return
+	}
+
+	// If-Match が指定されている場合は現在のバージョンと一致するかを確認
+	if !checkIfMatch(c, task) {
+		return
+	}
+	before := *task // 監査ログ用に変更前の状態を保持
+
+	var input struct {
+		ParentID *uint `json:"parent_id"`
+	}
+
+	// リクエストボディをバインド
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON provided"})
+		return
+	}
+
+	if input.ParentID != nil {
+		// 親タスクの存在を確認
+		if _, err := h.tasks(c).GetTaskByID(*input.ParentID); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Parent task not found"})
+			return
+		}
+
+		// 自分自身や子孫タスクの下には移動できない（循環の防止）
+		subtree, err := h.tasks(c).GetSubtree(task.ID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update task parent"})
+			return
+		}
+		for _, descendant := range subtree {
+			if descendant.ID == *input.ParentID {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Task cannot be moved under itself or its descendants"})
+				return
+			}
+		}
+	}
+
+	// 親タスクを更新
+	if err := h.tasks(c).SetTaskParent(task, input.ParentID); err != nil {
+		if respondVersionConflict(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update task parent"})
+		return
+	}
+
+	// 監査ログに記録
+	h.recordTaskAudit(c, model.AuditActionParent, &before, task)
+
 	// 更新されたタスクを返す
-	c.JSON(http.StatusOK, gin.H{"data": task})
+	respondTask(c, http.StatusOK, task)
+}
+
+// tasks はリクエストのワークスペースのタスクのうち、認証済みのユーザーが所有するタスク、
+// または共有されたタスクのみを操作するリポジトリを返します
+func (h *TaskHandler) tasks(c *gin.Context) repository.TaskRepository {
+	return h.repo.ForTenant(currentTenantID(c)).ForUser(currentUserID(c))
+}
+
+// findTask はURLパラメータのIDからタスクを取得します。失敗時はエラーレスポンスを書き込み false を返します
+func (h *TaskHandler) findTask(c *gin.Context) (*model.Task, bool) {
+	// URLパラメータからIDを取得
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid task ID"})
+		return nil, false
+	}
+
+	// 既存のタスクを取得
+	task, err := h.tasks(c).GetTaskByID(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+		return nil, false
+	}
+	return task, true
+}
+
+// createTask は入力値を検証し、認証済みのユーザーのタスクとして新しいタスクを作成します
+func (h *TaskHandler) createTask(c *gin.Context, repo repository.TaskRepository, input *model.Task) error {
+	// 入力値のバリデーション
+	if err := h.validate.Struct(input); err != nil {
+		return newRequestError(http.StatusBadRequest, err.Error())
+	}
+
+	// 繰り返しルールの検証
+	if err := normalizeRRule(input); err != nil {
+		return newRequestError(http.StatusBadRequest, err.Error())
+	}
+
+	// 親タスクが指定されている場合は存在を確認
+	if input.ParentID != nil {
+		if _, err := repo.GetTaskByID(*input.ParentID); err != nil {
+			return newRequestError(http.StatusBadRequest, "Parent task not found")
+		}
+	}
+
+	// プロジェクトが指定されている場合は、プロジェクトにタスクを追加できるロールかを確認
+	if input.ProjectID != nil {
+		if err := h.enforcer(c).AuthorizeProject(currentUserID(c), *input.ProjectID, policy.ActionEdit); err != nil {
+			return err
+		}
+	}
+
+	// タスクを作成
+	input.IsCompleted = false // 新規作成時は未完了とする
+	input.CompletedAt = nil
+	if input.Priority == "" {
+		input.Priority = model.PriorityNone
+	}
+	input.Tags = nil                   // タグは PUT /tasks/{id}/tags で設定する
+	input.Assignees = nil              // 担当者は POST /tasks/{id}/assignees で設定する
+	input.Version = 0                  // バージョンはデータベースの既定値（1）から始める
+	input.ArchivedAt = nil             // 新規作成時はアーカイブしない
+	input.DeletedAt = gorm.DeletedAt{} // ゴミ箱には作成しない
+	if err := repo.CreateTask(input); err != nil {
+		if errors.Is(err, gorm.ErrForeignKeyViolated) {
+			return newRequestError(http.StatusBadRequest, "Project not found")
+		}
+		return err
+	}
+	return nil
+}
+
+// applyPatch はタスクにパッチを適用し、変更されたフィールドのみを検証して更新します
+func (h *TaskHandler) applyPatch(repo repository.TaskRepository, task *model.Task, contentType string, body []byte) error {
+	// パッチを適用
+	patched, changed, err := patchTask(task, contentType, body)
+	if err != nil {
+		switch {
+		case errors.Is(err, errUnsupportedPatchType):
+			return newRequestError(http.StatusUnsupportedMediaType, err.Error())
+		case errors.Is(err, jsonpatch.ErrTestFailed):
+			return newRequestError(http.StatusConflict, err.Error())
+		default:
+			return newRequestError(http.StatusBadRequest, err.Error())
+		}
+	}
+
+	// 変更がなければ更新しない
+	if len(changed) == 0 {
+		return nil
+	}
+
+	// 変更されたフィールドのみバリデーション
+	fields := make([]string, len(changed))
+	for i, key := range changed {
+		fields[i] = patchableTaskFields[key]
+	}
+	if err := h.validate.StructPartial(patched, fields...); err != nil {
+		return newRequestError(http.StatusBadRequest, err.Error())
+	}
+
+	// タスクのフィールドを更新
+	for _, key := range changed {
+		copyTaskField(task, patched, key)
+	}
+	if task.Priority == "" {
+		task.Priority = model.PriorityNone
+	}
+
+	// 繰り返しルールの検証（期限日の削除も含めて確認する）
+	if err := normalizeRRule(task); err != nil {
+		return newRequestError(http.StatusBadRequest, err.Error())
+	}
+	task.UpdatedAt = time.Now()
+
+	// タスクを更新
+	return repo.UpdateTask(task)
+}
+
+// normalizeRRule は繰り返しルールを検証し、正規化した形式に置き換えます
+func normalizeRRule(task *model.Task) error {
+	if task.RRule == "" {
+		return nil
+	}
+	rule, err := rrule.Parse(task.RRule)
+	if err != nil {
+		return fmt.Errorf("Invalid rrule: %v", err)
+	}
+	// 次回の期限日を計算するため、繰り返しタスクには期限日が必要
+	if task.DueDate.IsZero() {
+		return errors.New("due_date is required for recurring tasks")
+	}
+	task.RRule = rule.String()
+	return nil
+}
+
+// parseOccurrenceRange は発生予定を展開する期間のクエリパラメータを解析します
+func parseOccurrenceRange(fromStr, toStr string) (from, to time.Time, ok bool, err error) {
+	if fromStr == "" && toStr == "" {
+		return time.Time{}, time.Time{}, false, nil
+	}
+	if fromStr == "" || toStr == "" {
+		return time.Time{}, time.Time{}, false, errors.New("occurrences_from and occurrences_to must be specified together")
+	}
+
+	if from, err = parseTimeParam(fromStr, false); err != nil {
+		return time.Time{}, time.Time{}, false, errors.New("Invalid occurrences_from parameter")
+	}
+	if to, err = parseTimeParam(toStr, true); err != nil {
+		return time.Time{}, time.Time{}, false, errors.New("Invalid occurrences_to parameter")
+	}
+	if to.Before(from) || to.Sub(from) > maxOccurrenceRange {
+		return time.Time{}, time.Time{}, false, errors.New("occurrences range must be between 0 and 366 days")
+	}
+	return from, to, true, nil
+}
+
+// parseTimeParam は RFC3339 または YYYY-MM-DD 形式の日時を解析します。
+// 日付のみの場合、endOfDay が true ならその日の終わりを返します
+func parseTimeParam(value string, endOfDay bool) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if endOfDay {
+		t = t.AddDate(0, 0, 1).Add(-time.Nanosecond)
+	}
+	return t, nil
+}
+
+// expandOccurrences は未完了の繰り返しタスクについて、期間内の次回以降の発生予定を展開します（タスクは生成しない）
+func expandOccurrences(tasks []model.Task, from, to time.Time) []model.Occurrence {
+	occurrences := []model.Occurrence{}
+	for _, task := range tasks {
+		if task.RRule == "" || task.IsCompleted || task.DueDate.IsZero() {
+			continue
+		}
+		rule, err := rrule.Parse(task.RRule)
+		if err != nil {
+			continue
+		}
+		for _, due := range rule.Between(task.DueDate, from, to, maxOccurrencesPerTask+1) {
+			// タスク自身の期限日は発生予定に含めない
+			if !due.After(task.DueDate) {
+				continue
+			}
+			occurrences = append(occurrences, model.Occurrence{TaskID: task.ID, Title: task.Title, DueDate: due})
+		}
+	}
+	sort.SliceStable(occurrences, func(i, j int) bool {
+		return occurrences[i].DueDate.Before(occurrences[j].DueDate)
+	})
+	return occurrences
+}
+
+// parseCommaSeparated はカンマ区切り・複数指定のクエリパラメータを値のスライスに展開します
+func parseCommaSeparated(values []string) []string {
+	var result []string
+	for _, value := range values {
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				result = append(result, item)
+			}
+		}
+	}
+	return result
 }
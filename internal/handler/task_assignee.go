@@ -0,0 +1,174 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ryory2/test-go-app-todo-go/internal/model"
+	"github.com/ryory2/test-go-app-todo-go/internal/policy"
+	"github.com/ryory2/test-go-app-todo-go/internal/repository"
+	"gorm.io/gorm"
+)
+
+// maxAssignmentEvents は担当者の変更イベントを一度に取得できる最大件数です
+const maxAssignmentEvents = 1000
+
+// AssignTaskハンドラー
+// HTTP: POST /tasks/{id}/assignees
+//
+// ユーザーをタスクの担当者に追加する（すでに担当者になっているユーザーはそのままにする）。
+// 担当者にできるのは、タスクの所有者・メンバーなどタスクを参照できるユーザーのみ
+func (h *TaskHandler) AssignTask(c *gin.Context) {
+	task, ok := h.findTask(c)
+	if !ok {
+		return
+	}
+
+	// ロールの権限を確認
+	if !h.authorize(c, task.ID, policy.ActionEdit) {
+		return
+	}
+
+	// If-Match が指定されている場合は現在のバージョンと一致するかを確認
+	if !checkIfMatch(c, task) {
+		return
+	}
+	before := *task // 監査ログ用に変更前の状態を保持
+
+	var input struct {
+		UserIDs []uint `json:"user_ids" validate:"required,min=1,max=50,dive,gt=0"`
+	}
+
+	// リクエストボディをバインド
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON provided"})
+		return
+	}
+
+	// 入力値のバリデーション
+	if err := h.validate.Struct(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// 担当者にするユーザーがタスクを参照できることを確認
+	for _, userID := range input.UserIDs {
+		role, err := h.tenantMembers(c).GetTaskRole(task.ID, userID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check permissions"})
+			return
+		}
+		if role == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("User %d is not a member of this task", userID)})
+			return
+		}
+	}
+
+	// 担当者を追加
+	if _, err := h.tasks(c).AssignTask(task, input.UserIDs, currentUserID(c)); err != nil {
+		if respondVersionConflict(c, err) {
+			return
+		}
+		if errors.Is(err, gorm.ErrForeignKeyViolated) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to assign task"})
+		return
+	}
+
+	// 監査ログに記録
+	h.recordTaskAudit(c, model.AuditActionAssign, &before, task)
+
+	// 更新されたタスクを返す
+	respondTask(c, http.StatusOK, task)
+}
+
+// UnassignTaskハンドラー
+// HTTP: DELETE /tasks/{id}/assignees/{user_id}
+func (h *TaskHandler) UnassignTask(c *gin.Context) {
+	task, ok := h.findTask(c)
+	if !ok {
+		return
+	}
+	userID, ok := parseMemberUserID(c)
+	if !ok {
+		return
+	}
+
+	// ロールの権限を確認（自分自身は権限によらず担当から外れることができる）
+	if userID != currentUserID(c) && !h.authorize(c, task.ID, policy.ActionEdit) {
+		return
+	}
+
+	// If-Match が指定されている場合は現在のバージョンと一致するかを確認
+	if !checkIfMatch(c, task) {
+		return
+	}
+	before := *task // 監査ログ用に変更前の状態を保持
+
+	// 担当者を解除
+	if _, err := h.tasks(c).UnassignTask(task, userID, currentUserID(c)); err != nil {
+		if respondVersionConflict(c, err) {
+			return
+		}
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Assignee not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unassign task"})
+		return
+	}
+
+	// 監査ログに記録
+	h.recordTaskAudit(c, model.AuditActionUnassign, &before, task)
+
+	// 更新されたタスクを返す
+	respondTask(c, http.StatusOK, task)
+}
+
+// GetAssignmentEventsハンドラー
+// HTTP: GET /assignment-events
+//
+// 参照できるタスクの担当者の変更イベントを古い順に返す。
+// 前回のレスポンスの next_cursor を cursor に指定すると、その後のイベントのみを取得できる。
+// コミット前のイベントを読み飛ばさないよう、作成から待ち時間（ASSIGNMENT_EVENT_LAG_SECONDS）が経過していないイベントは次回以降の取得で返す
+func (h *TaskHandler) GetAssignmentEvents(c *gin.Context) {
+	// カーソルを解析
+	token := c.Query("cursor")
+	var after *repository.Cursor
+	if token != "" {
+		var err error
+		if after, err = repository.DecodeAssignmentEventCursor(token); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid cursor parameter"})
+			return
+		}
+	}
+
+	// クエリパラメータを整数に変換
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	if err != nil || limit <= 0 || limit > maxAssignmentEvents {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit parameter"})
+		return
+	}
+
+	events, err := h.tasks(c).GetAssignmentEvents(after, h.eventLag, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve assignment events"})
+		return
+	}
+
+	// 次回の取得位置（イベントがない場合は cursor のまま）
+	next := token
+	if len(events) > 0 {
+		next = repository.NewAssignmentEventCursor(&events[len(events)-1])
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":        events,
+		"next_cursor": next,
+	})
+}
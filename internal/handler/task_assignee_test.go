@@ -0,0 +1,246 @@
+// internal/handler/task_assignee_test.go
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ryory2/test-go-app-todo-go/internal/model"
+	"github.com/ryory2/test-go-app-todo-go/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+// TestAssignTask はタスクを参照できるユーザーを担当者に追加し、監査ログに記録することをテストします。
+func TestAssignTask(t *testing.T) {
+	router, mockRepo, auditRepo := setupAuditedTestHandler(t)
+
+	// モックリポジトリの期待動作を設定
+	task := &model.Task{ID: 1, Title: "担当するタスク", Version: 1}
+	mockRepo.On("GetTaskByID", uint(1)).Return(task, nil)
+	mockRepo.On("AssignTask", task, []uint{testUserID}, testUserID).Return([]model.AssignmentEvent{
+		{ID: 10, TaskID: 1, UserID: testUserID, Action: model.AssignmentActionAssigned},
+	}, nil).Run(func(args mock.Arguments) {
+		task := args.Get(0).(*model.Task)
+		task.Assignees = []model.User{{ID: testUserID, Name: "Alice"}}
+		task.Version++
+	})
+	auditRepo.On("CreateAuditLog", mock.MatchedBy(func(entry *model.AuditLog) bool {
+		return entry.Action == model.AuditActionAssign && entry.ResourceID == 1
+	})).Return(nil)
+
+	// テストリクエストを作成（POST /tasks/1/assignees）
+	req, err := http.NewRequest(http.MethodPost, "/tasks/1/assignees", bytes.NewBufferString(`{"user_ids":[1]}`))
+	assert.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+
+	// リクエストをルーターに送信
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response struct {
+		Data model.Task `json:"data"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Len(t, response.Data.Assignees, 1)
+	assert.Equal(t, testUserID, response.Data.Assignees[0].ID)
+
+	mockRepo.AssertExpectations(t)
+	auditRepo.AssertExpectations(t)
+}
+
+// TestAssignTask_NotMember はタスクを参照できないユーザーは担当者にできないことをテストします。
+func TestAssignTask_NotMember(t *testing.T) {
+	router, mockRepo, _, memberRepo := setupMemberTestHandler(t)
+
+	// モックリポジトリの期待動作を設定
+	mockRepo.On("GetTaskByID", uint(1)).Return(&model.Task{ID: 1, Title: "タスク"}, nil)
+	memberRepo.On("GetTaskRole", uint(1), testUserID).Return(model.RoleOwner, nil)
+	memberRepo.On("GetTaskRole", uint(1), uint(5)).Return("", nil)
+
+	// テストリクエストを作成（POST /tasks/1/assignees）
+	req, err := http.NewRequest(http.MethodPost, "/tasks/1/assignees", bytes.NewBufferString(`{"user_ids":[5]}`))
+	assert.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+
+	// リクエストをルーターに送信
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "User 5 is not a member of this task")
+	mockRepo.AssertNotCalled(t, "AssignTask", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// TestAssignTask_Forbidden は viewer のロールではタスクの担当者を変更できないことをテストします。
+func TestAssignTask_Forbidden(t *testing.T) {
+	router, mockRepo, _, memberRepo := setupMemberTestHandler(t)
+
+	// モックリポジトリの期待動作を設定
+	mockRepo.On("GetTaskByID", uint(1)).Return(&model.Task{ID: 1, Title: "共有されたタスク"}, nil)
+	memberRepo.On("GetTaskRole", uint(1), testUserID).Return(model.RoleViewer, nil)
+
+	// テストリクエストを作成（POST /tasks/1/assignees）
+	req, err := http.NewRequest(http.MethodPost, "/tasks/1/assignees", bytes.NewBufferString(`{"user_ids":[1]}`))
+	assert.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+
+	// リクエストをルーターに送信
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	mockRepo.AssertNotCalled(t, "AssignTask", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// TestUnassignTask_Self は viewer のロールでも自分自身は担当から外れることができることをテストします。
+func TestUnassignTask_Self(t *testing.T) {
+	router, mockRepo, auditRepo, memberRepo := setupMemberTestHandler(t)
+
+	// モックリポジトリの期待動作を設定
+	task := &model.Task{ID: 1, Title: "共有されたタスク", Assignees: []model.User{{ID: testUserID}}}
+	auditRepo.On("CreateAuditLog", mock.Anything).Return(nil)
+	mockRepo.On("GetTaskByID", uint(1)).Return(task, nil)
+	mockRepo.On("UnassignTask", task, testUserID, testUserID).Return(&model.AssignmentEvent{ID: 11}, nil).Run(func(args mock.Arguments) {
+		args.Get(0).(*model.Task).Assignees = nil
+	})
+
+	// テストリクエストを作成（DELETE /tasks/1/assignees/1）
+	req, err := http.NewRequest(http.MethodDelete, "/tasks/1/assignees/1", nil)
+	assert.NoError(t, err)
+
+	// リクエストをルーターに送信
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockRepo.AssertExpectations(t)
+	memberRepo.AssertNotCalled(t, "GetTaskRole", mock.Anything, mock.Anything)
+}
+
+// TestUnassignTask_NotAssigned は担当者でないユーザーを解除しようとした場合に 404 を返すことをテストします。
+func TestUnassignTask_NotAssigned(t *testing.T) {
+	router, mockRepo := setupTestHandler(t)
+
+	// モックリポジトリの期待動作を設定
+	task := &model.Task{ID: 1, Title: "タスク"}
+	mockRepo.On("GetTaskByID", uint(1)).Return(task, nil)
+	mockRepo.On("UnassignTask", task, uint(5), testUserID).Return((*model.AssignmentEvent)(nil), gorm.ErrRecordNotFound)
+
+	// テストリクエストを作成（DELETE /tasks/1/assignees/5）
+	req, err := http.NewRequest(http.MethodDelete, "/tasks/1/assignees/5", nil)
+	assert.NoError(t, err)
+
+	// リクエストをルーターに送信
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	mockRepo.AssertExpectations(t)
+}
+
+// TestGetAssignmentEvents は担当者の変更イベントと、最後のイベントの位置を指す次回のカーソルを返すことをテストします。
+func TestGetAssignmentEvents(t *testing.T) {
+	router, mockRepo := setupTestHandler(t)
+
+	createdAt := time.Date(2024, 12, 12, 9, 0, 0, 0, time.UTC)
+	events := []model.AssignmentEvent{
+		{ID: 11, TaskID: 1, UserID: 2, Action: model.AssignmentActionAssigned, CreatedAt: createdAt},
+		{ID: 14, TaskID: 3, UserID: 2, Action: model.AssignmentActionUnassigned, CreatedAt: createdAt.Add(time.Second)},
+	}
+	next := repository.NewAssignmentEventCursor(&events[1])
+
+	// モックリポジトリの期待動作を設定
+	mockRepo.On("GetAssignmentEvents", (*repository.Cursor)(nil), testEventLag, 2).Return(events, nil)
+	mockRepo.On("GetAssignmentEvents", mock.AnythingOfType("*repository.Cursor"), testEventLag, 2).Return([]model.AssignmentEvent{}, nil)
+
+	tests := []struct {
+		query    string
+		expected int
+		next     string
+	}{
+		{"limit=2", 2, next},
+		// 新しいイベントがない場合は同じカーソルを返す
+		{"cursor=" + next + "&limit=2", 0, next},
+	}
+
+	for _, tt := range tests {
+		// テストリクエストを作成（GET /assignment-events）
+		req, err := http.NewRequest(http.MethodGet, "/assignment-events?"+tt.query, nil)
+		assert.NoError(t, err)
+
+		// リクエストをルーターに送信
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code, tt.query)
+
+		var response map[string]interface{}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Len(t, response["data"], tt.expected, tt.query)
+		assert.Equal(t, tt.next, response["next_cursor"], tt.query)
+	}
+
+	mockRepo.AssertExpectations(t)
+}
+
+// TestGetAssignmentEvents_InvalidCursor は不正なカーソルに 400 を返すことをテストします。
+func TestGetAssignmentEvents_InvalidCursor(t *testing.T) {
+	router, mockRepo := setupTestHandler(t)
+
+	// テストリクエストを作成（GET /assignment-events?cursor=invalid）
+	req, err := http.NewRequest(http.MethodGet, "/assignment-events?cursor=invalid", nil)
+	assert.NoError(t, err)
+
+	// リクエストをルーターに送信
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "Invalid cursor parameter")
+
+	mockRepo.AssertNotCalled(t, "GetAssignmentEvents", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// TestGetTasks_Assignee は assignee=me・unassigned を担当者の絞り込み条件に変換することをテストします。
+func TestGetTasks_Assignee(t *testing.T) {
+	tests := []struct {
+		query    string
+		expected func(filter repository.TaskFilter) bool
+	}{
+		{"assignee=me", func(filter repository.TaskFilter) bool {
+			return filter.AssigneeID != nil && *filter.AssigneeID == testUserID && !filter.Unassigned
+		}},
+		{"assignee=7", func(filter repository.TaskFilter) bool {
+			return filter.AssigneeID != nil && *filter.AssigneeID == 7
+		}},
+		{"assignee=unassigned", func(filter repository.TaskFilter) bool {
+			return filter.AssigneeID == nil && filter.Unassigned
+		}},
+	}
+
+	for _, tt := range tests {
+		router, mockRepo := setupTestHandler(t)
+
+		// モックリポジトリの期待動作を設定
+		mockRepo.On("GetTasks", mock.MatchedBy(tt.expected)).Return(&repository.TaskPage{Tasks: []model.Task{}}, nil)
+
+		// テストリクエストを作成
+		req, err := http.NewRequest(http.MethodGet, "/tasks?"+tt.query, nil)
+		assert.NoError(t, err)
+
+		// リクエストをルーターに送信
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code, tt.query)
+		mockRepo.AssertExpectations(t)
+	}
+}
@@ -0,0 +1,208 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ryory2/test-go-app-todo-go/internal/model"
+	"github.com/ryory2/test-go-app-todo-go/internal/policy"
+	"github.com/ryory2/test-go-app-todo-go/internal/repository"
+)
+
+// maxBatchOperations は1回の一括操作で実行できる操作数の上限です
+const maxBatchOperations = 100
+
+// batchAuditActions は一括操作の操作ごとの監査ログの操作の種類です
+var batchAuditActions = map[string]string{
+	"create":   model.AuditActionCreate,
+	"update":   model.AuditActionUpdate,
+	"delete":   model.AuditActionDelete,
+	"complete": model.AuditActionToggle,
+}
+
+// batchOperation は一括操作の1操作です
+type batchOperation struct {
+	// Op は create / update / delete / complete のいずれか
+	Op string `json:"op"`
+	// ID は update / delete / complete の対象のタスクID
+	ID uint `json:"id"`
+	// Task は create では作成するタスク、update ではマージパッチ（RFC 7396）
+	Task json.RawMessage `json:"task"`
+	// IfMatch が指定された場合は対象のタスクの ETag と一致する場合のみ実行する
+	IfMatch string `json:"if_match"`
+}
+
+// batchResult は一括操作の1操作の結果です
+type batchResult struct {
+	Index  int         `json:"index"`
+	Op     string      `json:"op"`
+	Status int         `json:"status"`
+	Data   *model.Task `json:"data,omitempty"`
+	Error  string      `json:"error,omitempty"`
+	// Reason はロールの権限が足りずに拒否した場合の理由（policy.Reason*）
+	Reason string `json:"reason,omitempty"`
+}
+
+// BatchTasksハンドラー
+// HTTP: POST /tasks/batch
+//
+// mode=atomic（既定）: すべての操作を1つのトランザクションで実行し、1つでも失敗した場合はすべて取り消す
+// mode=partial: 操作ごとにセーブポイントを設け、失敗した操作のみ取り消す
+func (h *TaskHandler) BatchTasks(c *gin.Context) {
+	var input struct {
+		Mode       string           `json:"mode"`
+		Operations []batchOperation `json:"operations"`
+	}
+
+	// リクエストボディをバインド
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON provided"})
+		return
+	}
+
+	// 入力値のバリデーション
+	if input.Mode == "" {
+		input.Mode = "atomic"
+	}
+	if input.Mode != "atomic" && input.Mode != "partial" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid mode: must be atomic or partial"})
+		return
+	}
+	if len(input.Operations) == 0 || len(input.Operations) > maxBatchOperations {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("operations must contain between 1 and %d items", maxBatchOperations)})
+		return
+	}
+
+	results := make([]batchResult, len(input.Operations))
+	befores := make([]*model.Task, len(input.Operations)) // 監査ログ用の変更前の状態
+	failed := -1
+	err := h.tasks(c).Transaction(func(tx repository.TaskRepository) error {
+		for i, op := range input.Operations {
+			var task *model.Task
+			var status int
+			run := func(repo repository.TaskRepository) error {
+				var err error
+				befores[i], task, status, err = h.runBatchOperation(c, repo, op)
+				return err
+			}
+
+			var err error
+			if input.Mode == "partial" {
+				// 失敗した操作のみ取り消すため、操作ごとにセーブポイントを設ける
+				err = tx.Transaction(run)
+			} else {
+				err = run(tx)
+			}
+
+			results[i] = batchResult{Index: i, Op: op.Op, Status: status, Data: task}
+			if err != nil {
+				results[i].Status, results[i].Error = errorStatus(err, "Failed to execute operation")
+				var denial *policy.Denial
+				if errors.As(err, &denial) {
+					results[i].Reason = denial.Reason
+				}
+				results[i].Data = nil
+				if input.Mode == "atomic" {
+					failed = i
+					return err
+				}
+			}
+		}
+		return nil
+	})
+
+	if err != nil {
+		if failed < 0 {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to execute batch"})
+			return
+		}
+		// 失敗した操作以外は取り消された（または実行されていない）ことを示す
+		for i := range results {
+			if i != failed {
+				results[i] = batchResult{
+					Index:  i,
+					Op:     input.Operations[i].Op,
+					Status: http.StatusFailedDependency,
+					Error:  fmt.Sprintf("Not applied because operation %d failed", failed),
+				}
+			}
+		}
+		c.JSON(results[failed].Status, gin.H{
+			"error": "Batch failed; no changes were applied",
+			"data":  results,
+		})
+		return
+	}
+
+	// 監査ログに記録（取り消された操作は記録しない）
+	for i, op := range input.Operations {
+		if results[i].Error == "" {
+			h.recordTaskAudit(c, batchAuditActions[op.Op], befores[i], results[i].Data)
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": results})
+}
+
+// runBatchOperation は userID のユーザーとして一括操作の1操作を実行し、変更前と変更後のタスクとステータスコードを返します
+func (h *TaskHandler) runBatchOperation(c *gin.Context, repo repository.TaskRepository, op batchOperation) (*model.Task, *model.Task, int, error) {
+	if op.Op == "create" {
+		var task model.Task
+		if err := json.Unmarshal(op.Task, &task); err != nil {
+			return nil, nil, 0, newRequestError(http.StatusBadRequest, "Invalid task provided")
+		}
+		if err := h.createTask(c, repo, &task); err != nil {
+			return nil, nil, 0, err
+		}
+		return nil, &task, http.StatusCreated, nil
+	}
+
+	if op.Op != "update" && op.Op != "delete" && op.Op != "complete" {
+		return nil, nil, 0, newRequestError(http.StatusBadRequest, fmt.Sprintf("Unknown operation %q", op.Op))
+	}
+
+	// 対象のタスクを取得
+	task, err := repo.GetTaskByID(op.ID)
+	if err != nil {
+		return nil, nil, 0, newRequestError(http.StatusNotFound, "Task not found")
+	}
+
+	// ロールの権限を確認
+	action := policy.ActionEdit
+	if op.Op == "delete" {
+		action = policy.ActionDelete
+	}
+	if err := h.enforcer(c).AuthorizeTask(currentUserID(c), task.ID, action); err != nil {
+		return nil, nil, 0, err
+	}
+
+	// if_match が指定されている場合は現在のバージョンと一致するかを確認
+	if op.IfMatch != "" && !etagMatches(op.IfMatch, taskETag(task), false) {
+		return nil, nil, 0, newRequestError(http.StatusPreconditionFailed, "Task has been modified")
+	}
+	before := *task
+
+	switch op.Op {
+	case "update":
+		if err := h.applyPatch(repo, task, mergePatchContentType, op.Task); err != nil {
+			return nil, nil, 0, err
+		}
+		return &before, task, http.StatusOK, nil
+	case "delete":
+		if _, err := repo.DeleteTask(task); err != nil {
+			return nil, nil, 0, err
+		}
+		return &before, nil, http.StatusOK, nil
+	default:
+		// 完了済みのタスクはそのままにする（トグルしない）
+		if !task.IsCompleted {
+			if err := repo.ToggleTaskCompletion(task); err != nil {
+				return nil, nil, 0, err
+			}
+		}
+		return &before, task, http.StatusOK, nil
+	}
+}
@@ -0,0 +1,186 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ryory2/test-go-app-todo-go/internal/model"
+	"github.com/ryory2/test-go-app-todo-go/internal/policy"
+)
+
+// commentInput はコメントの作成・編集のリクエストボディです
+type commentInput struct {
+	Body string `json:"body" validate:"required,max=10000"` // Markdown
+}
+
+// GetTaskCommentsハンドラー
+// HTTP: GET /tasks/{id}/comments
+func (h *TaskHandler) GetTaskComments(c *gin.Context) {
+	task, ok := h.findTask(c)
+	if !ok {
+		return
+	}
+
+	// クエリパラメータを整数に変換
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if err != nil || limit <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit parameter"})
+		return
+	}
+
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid offset parameter"})
+		return
+	}
+
+	// コメントを投稿された順に取得
+	comments, total, err := h.tasks(c).GetComments(task.ID, limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve comments"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":  comments,
+		"total": total,
+	})
+}
+
+// CreateTaskCommentハンドラー
+// HTTP: POST /tasks/{id}/comments
+func (h *TaskHandler) CreateTaskComment(c *gin.Context) {
+	task, ok := h.findTask(c)
+	if !ok {
+		return
+	}
+
+	// ロールの権限を確認
+	if !h.authorize(c, task.ID, policy.ActionComment) {
+		return
+	}
+
+	input, ok := h.bindComment(c)
+	if !ok {
+		return
+	}
+
+	comment := model.Comment{TaskID: task.ID, AuthorID: currentUserID(c), Body: input.Body}
+	if err := h.tasks(c).CreateComment(&comment); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create comment"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"data": comment})
+}
+
+// UpdateTaskCommentハンドラー
+// HTTP: PUT /tasks/{id}/comments/{comment_id}
+//
+// コメントの本文を編集する。編集できるのはコメントを投稿したユーザーのみ
+func (h *TaskHandler) UpdateTaskComment(c *gin.Context) {
+	task, ok := h.findTask(c)
+	if !ok {
+		return
+	}
+
+	comment, ok := h.findComment(c, task.ID)
+	if !ok {
+		return
+	}
+
+	// 投稿者であり、現在もコメントできるロールを持つことを確認
+	if comment.AuthorID != currentUserID(c) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the author can edit this comment"})
+		return
+	}
+	if !h.authorize(c, task.ID, policy.ActionComment) {
+		return
+	}
+
+	input, ok := h.bindComment(c)
+	if !ok {
+		return
+	}
+
+	now := time.Now()
+	comment.Body = input.Body
+	comment.EditedAt = &now
+	comment.UpdatedAt = now
+	if err := h.tasks(c).UpdateComment(comment); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update comment"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": comment})
+}
+
+// DeleteTaskCommentハンドラー
+// HTTP: DELETE /tasks/{id}/comments/{comment_id}
+//
+// コメントを削除する。投稿者のほか、タスクを削除できるロール（owner）も削除できる
+func (h *TaskHandler) DeleteTaskComment(c *gin.Context) {
+	task, ok := h.findTask(c)
+	if !ok {
+		return
+	}
+
+	comment, ok := h.findComment(c, task.ID)
+	if !ok {
+		return
+	}
+
+	// ロールの権限を確認
+	action := policy.ActionDelete
+	if comment.AuthorID == currentUserID(c) {
+		action = policy.ActionComment
+	}
+	if !h.authorize(c, task.ID, action) {
+		return
+	}
+
+	if err := h.tasks(c).DeleteComment(comment); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete comment"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Comment deleted successfully"})
+}
+
+// bindComment はコメントのリクエストボディをバインドして検証します。失敗時はエラーレスポンスを書き込み false を返します
+func (h *TaskHandler) bindComment(c *gin.Context) (*commentInput, bool) {
+	var input commentInput
+
+	// リクエストボディをバインド
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON provided"})
+		return nil, false
+	}
+
+	// 入力値のバリデーション（空白のみの本文は受け付けない）
+	input.Body = strings.TrimSpace(input.Body)
+	if err := h.validate.Struct(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil, false
+	}
+	return &input, true
+}
+
+// findComment はURLパラメータのIDからタスクのコメントを取得します。失敗時はエラーレスポンスを書き込み false を返します
+func (h *TaskHandler) findComment(c *gin.Context, taskID uint) (*model.Comment, bool) {
+	id, err := strconv.Atoi(c.Param("comment_id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid comment ID"})
+		return nil, false
+	}
+
+	comment, err := h.tasks(c).GetComment(taskID, uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Comment not found"})
+		return nil, false
+	}
+	return comment, true
+}
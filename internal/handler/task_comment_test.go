@@ -0,0 +1,291 @@
+// internal/handler/task_comment_test.go
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ryory2/test-go-app-todo-go/internal/model"
+	"github.com/ryory2/test-go-app-todo-go/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+// TestGetTaskComments はタスクのコメントを投稿された順にページングして返すことをテストします。
+func TestGetTaskComments(t *testing.T) {
+	router, mockRepo := setupTestHandler(t)
+
+	// モックリポジトリの期待動作を設定
+	mockRepo.On("GetTaskByID", uint(1)).Return(&model.Task{ID: 1, Title: "タスク"}, nil)
+	comments := []model.Comment{
+		{ID: 3, TaskID: 1, AuthorID: testUserID, Body: "**最初**のコメント"},
+		{ID: 4, TaskID: 1, AuthorID: 5, Body: "返信"},
+	}
+	mockRepo.On("GetComments", uint(1), 2, 2).Return(comments, int64(4), nil)
+
+	// テストリクエストを作成（GET /tasks/1/comments?limit=2&offset=2）
+	req, err := http.NewRequest(http.MethodGet, "/tasks/1/comments?limit=2&offset=2", nil)
+	assert.NoError(t, err)
+
+	// リクエストをルーターに送信
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response struct {
+		Data  []model.Comment `json:"data"`
+		Total int64           `json:"total"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Len(t, response.Data, 2)
+	assert.Equal(t, "**最初**のコメント", response.Data[0].Body)
+	assert.Equal(t, int64(4), response.Total)
+
+	mockRepo.AssertExpectations(t)
+}
+
+// TestGetTaskComments_InvalidLimit は不正な limit の場合に 400 を返すことをテストします。
+func TestGetTaskComments_InvalidLimit(t *testing.T) {
+	router, mockRepo := setupTestHandler(t)
+
+	mockRepo.On("GetTaskByID", uint(1)).Return(&model.Task{ID: 1, Title: "タスク"}, nil)
+
+	req, err := http.NewRequest(http.MethodGet, "/tasks/1/comments?limit=0", nil)
+	assert.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockRepo.AssertNotCalled(t, "GetComments", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// TestCreateTaskComment は認証済みのユーザーを投稿者としてコメントを作成することをテストします。
+func TestCreateTaskComment(t *testing.T) {
+	router, mockRepo := setupTestHandler(t)
+
+	// モックリポジトリの期待動作を設定
+	mockRepo.On("GetTaskByID", uint(1)).Return(&model.Task{ID: 1, Title: "タスク"}, nil)
+	mockRepo.On("CreateComment", mock.MatchedBy(func(comment *model.Comment) bool {
+		return comment.TaskID == 1 && comment.AuthorID == testUserID && comment.Body == "# 見出し\n\n本文"
+	})).Return(nil).Run(func(args mock.Arguments) {
+		args.Get(0).(*model.Comment).ID = 10
+	})
+
+	// テストリクエストを作成（POST /tasks/1/comments）
+	body, _ := json.Marshal(map[string]string{"body": "  # 見出し\n\n本文\n"})
+	req, err := http.NewRequest(http.MethodPost, "/tasks/1/comments", bytes.NewBuffer(body))
+	assert.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+
+	// リクエストをルーターに送信
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	var response struct {
+		Data model.Comment `json:"data"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, uint(10), response.Data.ID)
+	assert.Nil(t, response.Data.EditedAt)
+
+	mockRepo.AssertExpectations(t)
+}
+
+// TestCreateTaskComment_EmptyBody は空白のみの本文の場合に 400 を返すことをテストします。
+func TestCreateTaskComment_EmptyBody(t *testing.T) {
+	router, mockRepo := setupTestHandler(t)
+
+	mockRepo.On("GetTaskByID", uint(1)).Return(&model.Task{ID: 1, Title: "タスク"}, nil)
+
+	req, err := http.NewRequest(http.MethodPost, "/tasks/1/comments", bytes.NewBufferString(`{"body":"   "}`))
+	assert.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockRepo.AssertNotCalled(t, "CreateComment", mock.Anything)
+}
+
+// TestCreateTaskComment_Viewer は viewer のロールではコメントできないことをテストします。
+func TestCreateTaskComment_Viewer(t *testing.T) {
+	router, mockRepo, _, memberRepo := setupMemberTestHandler(t)
+
+	mockRepo.On("GetTaskByID", uint(1)).Return(&model.Task{ID: 1, Title: "タスク"}, nil)
+	memberRepo.On("GetTaskRole", uint(1), testUserID).Return(model.RoleViewer, nil)
+
+	req, err := http.NewRequest(http.MethodPost, "/tasks/1/comments", bytes.NewBufferString(`{"body":"コメント"}`))
+	assert.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Contains(t, w.Body.String(), "insufficient_role")
+	mockRepo.AssertNotCalled(t, "CreateComment", mock.Anything)
+}
+
+// TestUpdateTaskComment は投稿者がコメントを編集すると編集日時を記録することをテストします。
+func TestUpdateTaskComment(t *testing.T) {
+	router, mockRepo := setupTestHandler(t)
+
+	// モックリポジトリの期待動作を設定
+	mockRepo.On("GetTaskByID", uint(1)).Return(&model.Task{ID: 1, Title: "タスク"}, nil)
+	mockRepo.On("GetComment", uint(1), uint(10)).Return(&model.Comment{ID: 10, TaskID: 1, AuthorID: testUserID, Body: "古い本文"}, nil)
+	mockRepo.On("UpdateComment", mock.MatchedBy(func(comment *model.Comment) bool {
+		return comment.ID == 10 && comment.Body == "新しい本文" && comment.EditedAt != nil
+	})).Return(nil)
+
+	// テストリクエストを作成（PUT /tasks/1/comments/10）
+	req, err := http.NewRequest(http.MethodPut, "/tasks/1/comments/10", bytes.NewBufferString(`{"body":"新しい本文"}`))
+	assert.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+
+	// リクエストをルーターに送信
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response struct {
+		Data model.Comment `json:"data"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "新しい本文", response.Data.Body)
+	assert.NotNil(t, response.Data.EditedAt)
+
+	mockRepo.AssertExpectations(t)
+}
+
+// TestUpdateTaskComment_NotAuthor は投稿者以外はタスクの owner でもコメントを編集できないことをテストします。
+func TestUpdateTaskComment_NotAuthor(t *testing.T) {
+	router, mockRepo := setupTestHandler(t)
+
+	mockRepo.On("GetTaskByID", uint(1)).Return(&model.Task{ID: 1, Title: "タスク"}, nil)
+	mockRepo.On("GetComment", uint(1), uint(10)).Return(&model.Comment{ID: 10, TaskID: 1, AuthorID: 5, Body: "他人のコメント"}, nil)
+
+	req, err := http.NewRequest(http.MethodPut, "/tasks/1/comments/10", bytes.NewBufferString(`{"body":"書き換え"}`))
+	assert.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Contains(t, w.Body.String(), "Only the author can edit this comment")
+	mockRepo.AssertNotCalled(t, "UpdateComment", mock.Anything)
+}
+
+// TestUpdateTaskComment_NotFound は存在しないコメントの場合に 404 を返すことをテストします。
+func TestUpdateTaskComment_NotFound(t *testing.T) {
+	router, mockRepo := setupTestHandler(t)
+
+	mockRepo.On("GetTaskByID", uint(1)).Return(&model.Task{ID: 1, Title: "タスク"}, nil)
+	mockRepo.On("GetComment", uint(1), uint(99)).Return((*model.Comment)(nil), gorm.ErrRecordNotFound)
+
+	req, err := http.NewRequest(http.MethodPut, "/tasks/1/comments/99", bytes.NewBufferString(`{"body":"本文"}`))
+	assert.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+// TestDeleteTaskComment_Author は commenter のロールを持つ投稿者が自分のコメントを削除できることをテストします。
+func TestDeleteTaskComment_Author(t *testing.T) {
+	router, mockRepo, _, memberRepo := setupMemberTestHandler(t)
+
+	// モックリポジトリの期待動作を設定
+	mockRepo.On("GetTaskByID", uint(1)).Return(&model.Task{ID: 1, Title: "タスク"}, nil)
+	comment := &model.Comment{ID: 10, TaskID: 1, AuthorID: testUserID, Body: "自分のコメント"}
+	mockRepo.On("GetComment", uint(1), uint(10)).Return(comment, nil)
+	mockRepo.On("DeleteComment", comment).Return(nil)
+	memberRepo.On("GetTaskRole", uint(1), testUserID).Return(model.RoleCommenter, nil)
+
+	// テストリクエストを作成（DELETE /tasks/1/comments/10）
+	req, err := http.NewRequest(http.MethodDelete, "/tasks/1/comments/10", nil)
+	assert.NoError(t, err)
+
+	// リクエストをルーターに送信
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockRepo.AssertExpectations(t)
+}
+
+// TestDeleteTaskComment_OtherUser は owner ではないユーザーは他人のコメントを削除できないことをテストします。
+func TestDeleteTaskComment_OtherUser(t *testing.T) {
+	router, mockRepo, _, memberRepo := setupMemberTestHandler(t)
+
+	mockRepo.On("GetTaskByID", uint(1)).Return(&model.Task{ID: 1, Title: "タスク"}, nil)
+	mockRepo.On("GetComment", uint(1), uint(10)).Return(&model.Comment{ID: 10, TaskID: 1, AuthorID: 5, Body: "他人のコメント"}, nil)
+	memberRepo.On("GetTaskRole", uint(1), testUserID).Return(model.RoleEditor, nil)
+
+	req, err := http.NewRequest(http.MethodDelete, "/tasks/1/comments/10", nil)
+	assert.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	mockRepo.AssertNotCalled(t, "DeleteComment", mock.Anything)
+}
+
+// TestDeleteTaskComment_Owner はタスクの owner が他人のコメントを削除できることをテストします。
+func TestDeleteTaskComment_Owner(t *testing.T) {
+	router, mockRepo := setupTestHandler(t)
+
+	mockRepo.On("GetTaskByID", uint(1)).Return(&model.Task{ID: 1, Title: "タスク"}, nil)
+	comment := &model.Comment{ID: 10, TaskID: 1, AuthorID: 5, Body: "他人のコメント"}
+	mockRepo.On("GetComment", uint(1), uint(10)).Return(comment, nil)
+	mockRepo.On("DeleteComment", comment).Return(nil)
+
+	req, err := http.NewRequest(http.MethodDelete, "/tasks/1/comments/10", nil)
+	assert.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockRepo.AssertExpectations(t)
+}
+
+// TestGetTasks_CommentCount はタスク一覧のレスポンスにコメントの件数が含まれることをテストします。
+func TestGetTasks_CommentCount(t *testing.T) {
+	router, mockRepo := setupTestHandler(t)
+
+	tasks := []model.Task{
+		{ID: 1, Title: "コメントのあるタスク", CommentCount: 3},
+		{ID: 2, Title: "コメントのないタスク"},
+	}
+	mockRepo.On("GetTasks", repository.TaskFilter{Status: "all", Limit: 10}).Return(&repository.TaskPage{Tasks: tasks, Total: 2}, nil)
+
+	req, err := http.NewRequest(http.MethodGet, "/tasks?status=all&limit=10", nil)
+	assert.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response struct {
+		Data []map[string]interface{} `json:"data"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Len(t, response.Data, 2)
+	assert.Equal(t, float64(3), response.Data[0]["comment_count"])
+	assert.Equal(t, float64(0), response.Data[1]["comment_count"])
+}
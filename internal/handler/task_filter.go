@@ -0,0 +1,258 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ryory2/test-go-app-todo-go/internal/repository"
+)
+
+// maxFilterIDs は ids パラメータで指定できるIDの最大数です
+const maxFilterIDs = 100
+
+// taskListParams は GET /tasks で受け付けるクエリパラメータです
+var taskListParams = map[string]bool{
+	"status":           true,
+	"archived":         true,
+	"tags":             true,
+	"tag_match":        true,
+	"q":                true,
+	"sort":             true,
+	"ids":              true,
+	"assignee":         true,
+	"due_before":       true,
+	"due_after":        true,
+	"overdue":          true,
+	"created_before":   true,
+	"created_after":    true,
+	"updated_before":   true,
+	"updated_after":    true,
+	"has_description":  true,
+	"limit":            true,
+	"offset":           true,
+	"cursor":           true,
+	"count":            true,
+	"fields":           true,
+	"occurrences_from": true,
+	"occurrences_to":   true,
+}
+
+// parseTaskFilter は GET /tasks のクエリパラメータを検証し、タスクの絞り込み条件に変換します。
+// 未知のパラメータや不正な値はエラーとして返します
+func parseTaskFilter(c *gin.Context) (repository.TaskFilter, error) {
+	var filter repository.TaskFilter
+	query := c.Request.URL.Query()
+
+	if err := checkKnownParams(query, taskListParams); err != nil {
+		return filter, err
+	}
+
+	// 同じパラメータの重複指定は tags・ids 以外では受け付けない
+	for key, values := range query {
+		if len(values) > 1 && key != "tags" && key != "ids" {
+			return filter, fmt.Errorf("Duplicate query parameter: %s", key)
+		}
+	}
+
+	// 完了状態（all: すべて, completed: 完了, pending: 未完了）
+	filter.Status = query.Get("status")
+	switch filter.Status {
+	case "", "all", "completed", "pending":
+	default:
+		return filter, errors.New("Invalid status parameter")
+	}
+
+	// アーカイブ済みのタスク（exclude: 除外, include: 含める, only: アーカイブ済みのみ）
+	switch archived := query.Get("archived"); archived {
+	case "", "exclude":
+	case "include", "only":
+		filter.Archived = archived
+	default:
+		return filter, errors.New("Invalid archived parameter")
+	}
+
+	// タグの一致条件を検証（any: いずれかのタグ, all: すべてのタグ）
+	filter.Tags = parseCommaSeparated(query["tags"])
+	switch query.Get("tag_match") {
+	case "", "any":
+	case "all":
+		filter.MatchAllTags = true
+	default:
+		return filter, errors.New("Invalid tag_match parameter")
+	}
+
+	// キーワード検索（タイトル・説明の全文検索）
+	filter.Query = strings.TrimSpace(query.Get("q"))
+	if len(filter.Query) > maxSearchQueryLength {
+		return filter, errors.New("Invalid q parameter: too long")
+	}
+
+	// 並び順を解析（例: sort=-priority,due_date）
+	sortFields, err := repository.ParseSort(query.Get("sort"))
+	if err != nil {
+		return filter, errors.New("Invalid sort parameter: " + err.Error())
+	}
+	filter.Sort = sortFields
+
+	// IDの一覧（例: ids=1,2,3）
+	if filter.IDs, err = parseIDList(query["ids"]); err != nil {
+		return filter, err
+	}
+
+	// 担当者（me: 認証済みのユーザー, unassigned: 担当者なし, または担当者のユーザーID）
+	switch assignee := query.Get("assignee"); assignee {
+	case "":
+	case "me":
+		userID := currentUserID(c)
+		filter.AssigneeID = &userID
+	case "unassigned":
+		filter.Unassigned = true
+	default:
+		userID, err := strconv.ParseUint(assignee, 10, 64)
+		if err != nil || userID == 0 {
+			return filter, errors.New("Invalid assignee parameter")
+		}
+		id := uint(userID)
+		filter.AssigneeID = &id
+	}
+
+	// 日時の範囲（before は指定日時より前、after は指定日時より後）
+	ranges := []struct {
+		name     string
+		endOfDay bool
+		dest     **time.Time
+	}{
+		{"due_before", false, &filter.DueBefore},
+		{"due_after", true, &filter.DueAfter},
+		{"created_before", false, &filter.CreatedBefore},
+		{"created_after", true, &filter.CreatedAfter},
+		{"updated_before", false, &filter.UpdatedBefore},
+		{"updated_after", true, &filter.UpdatedAfter},
+	}
+	for _, r := range ranges {
+		value := query.Get(r.name)
+		if value == "" {
+			continue
+		}
+		t, err := parseTimeParam(value, r.endOfDay)
+		if err != nil {
+			return filter, fmt.Errorf("Invalid %s parameter", r.name)
+		}
+		*r.dest = &t
+	}
+	if err := checkTimeRange("due", filter.DueAfter, filter.DueBefore); err != nil {
+		return filter, err
+	}
+	if err := checkTimeRange("created", filter.CreatedAfter, filter.CreatedBefore); err != nil {
+		return filter, err
+	}
+	if err := checkTimeRange("updated", filter.UpdatedAfter, filter.UpdatedBefore); err != nil {
+		return filter, err
+	}
+
+	// 真偽値の条件
+	if filter.Overdue, err = parseBoolParam(query, "overdue"); err != nil {
+		return filter, err
+	}
+	if filter.HasDescription, err = parseBoolParam(query, "has_description"); err != nil {
+		return filter, err
+	}
+
+	// クエリパラメータを整数に変換
+	filter.Limit = 10
+	if value := query.Get("limit"); value != "" {
+		if filter.Limit, err = strconv.Atoi(value); err != nil || filter.Limit <= 0 {
+			return filter, errors.New("Invalid limit parameter")
+		}
+	}
+	if value := query.Get("offset"); value != "" {
+		if filter.Offset, err = strconv.Atoi(value); err != nil || filter.Offset < 0 {
+			return filter, errors.New("Invalid offset parameter")
+		}
+	}
+
+	// カーソルによるページング（offset との併用は不可）
+	if token := query.Get("cursor"); token != "" {
+		if query.Has("offset") {
+			return filter, errors.New("cursor and offset cannot be used together")
+		}
+		// 関連度順（q 指定で sort 未指定）はキーセットページングに対応しない
+		if filter.Query != "" && len(filter.Sort) == 0 {
+			return filter, errors.New("cursor requires the sort parameter when q is specified")
+		}
+		if filter.Cursor, err = repository.DecodeCursor(token, filter.Sort); err != nil {
+			return filter, errors.New("Invalid cursor parameter")
+		}
+	}
+
+	// 全件数を数えるか（既定ではカーソル指定時は数えない）
+	count, err := parseBoolParam(query, "count")
+	if err != nil {
+		return filter, err
+	}
+	if count != nil {
+		filter.SkipCount = !*count
+	} else {
+		filter.SkipCount = filter.Cursor != nil
+	}
+
+	return filter, nil
+}
+
+// checkKnownParams は許可されていないクエリパラメータが含まれていればエラーを返します
+func checkKnownParams(query url.Values, allowed map[string]bool) error {
+	var unknown []string
+	for key := range query {
+		if !allowed[key] {
+			unknown = append(unknown, key)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return fmt.Errorf("Unknown query parameter: %s", strings.Join(unknown, ", "))
+	}
+	return nil
+}
+
+// parseIDList はカンマ区切り・複数指定のIDを解析します
+func parseIDList(values []string) ([]uint, error) {
+	var ids []uint
+	for _, item := range parseCommaSeparated(values) {
+		id, err := strconv.ParseUint(item, 10, 32)
+		if err != nil || id == 0 {
+			return nil, errors.New("Invalid ids parameter")
+		}
+		ids = append(ids, uint(id))
+	}
+	if len(ids) > maxFilterIDs {
+		return nil, fmt.Errorf("Invalid ids parameter: at most %d ids are allowed", maxFilterIDs)
+	}
+	return ids, nil
+}
+
+// parseBoolParam は真偽値のクエリパラメータを解析します。未指定の場合は nil を返します
+func parseBoolParam(query url.Values, name string) (*bool, error) {
+	value := query.Get(name)
+	if value == "" {
+		return nil, nil
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid %s parameter", name)
+	}
+	return &b, nil
+}
+
+// checkTimeRange は after と before の両方が指定された場合に範囲が空でないことを確認します
+func checkTimeRange(name string, after, before *time.Time) error {
+	if after != nil && before != nil && !after.Before(*before) {
+		return fmt.Errorf("%s_after must be earlier than %s_before", name, name)
+	}
+	return nil
+}
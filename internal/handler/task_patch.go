@@ -0,0 +1,116 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/ryory2/test-go-app-todo-go/internal/model"
+	"github.com/ryory2/test-go-app-todo-go/pkg/jsonpatch"
+)
+
+const (
+	mergePatchContentType = "application/merge-patch+json"
+	jsonPatchContentType  = "application/json-patch+json"
+)
+
+// errUnsupportedPatchType はパッチの Content-Type が未対応の場合のエラーです
+var errUnsupportedPatchType = errors.New("Unsupported Content-Type: use " + mergePatchContentType + " or " + jsonPatchContentType)
+
+// patchableTaskFields は PATCH で変更できるフィールドの JSON キーと構造体のフィールド名です。
+// project_id・parent_id・tags・assignees はそれぞれ専用のエンドポイントで変更します
+var patchableTaskFields = map[string]string{
+	"title":        "Title",
+	"description":  "Description",
+	"due_date":     "DueDate",
+	"is_completed": "IsCompleted",
+	"priority":     "Priority",
+	"rrule":        "RRule",
+}
+
+// patchTask はタスクの JSON 表現にパッチを適用し、適用後のタスクと変更されたフィールドの JSON キーを返します。
+// 変更できないフィールドが変更された場合はエラーを返します
+func patchTask(task *model.Task, contentType string, patch []byte) (*model.Task, []string, error) {
+	doc, err := json.Marshal(task)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var result []byte
+	switch contentType {
+	case mergePatchContentType, "application/json":
+		result, err = jsonpatch.MergePatch(doc, patch)
+	case jsonPatchContentType:
+		result, err = jsonpatch.Apply(doc, patch)
+	default:
+		return nil, nil, errUnsupportedPatchType
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("Invalid patch: %w", err)
+	}
+
+	// 変更されたフィールドを特定する
+	var before, after map[string]interface{}
+	if err := json.Unmarshal(doc, &before); err != nil {
+		return nil, nil, err
+	}
+	if err := json.Unmarshal(result, &after); err != nil {
+		return nil, nil, errors.New("patched document must be a JSON object")
+	}
+	var changed, readOnly []string
+	for key := range mergeKeys(before, after) {
+		if reflect.DeepEqual(before[key], after[key]) {
+			continue
+		}
+		if _, ok := patchableTaskFields[key]; ok {
+			changed = append(changed, key)
+		} else {
+			readOnly = append(readOnly, key)
+		}
+	}
+	if len(readOnly) > 0 {
+		sort.Strings(readOnly)
+		return nil, nil, fmt.Errorf("Fields cannot be patched: %s", strings.Join(readOnly, ", "))
+	}
+	sort.Strings(changed)
+
+	// 削除されたフィールド（null）はゼロ値になるよう、新しい構造体に読み込む
+	var patched model.Task
+	if err := json.Unmarshal(result, &patched); err != nil {
+		return nil, nil, fmt.Errorf("Invalid patched value: %v", err)
+	}
+	return &patched, changed, nil
+}
+
+// mergeKeys は2つのオブジェクトのキーの和集合を返します
+func mergeKeys(a, b map[string]interface{}) map[string]bool {
+	keys := make(map[string]bool, len(a))
+	for key := range a {
+		keys[key] = true
+	}
+	for key := range b {
+		keys[key] = true
+	}
+	return keys
+}
+
+// copyTaskField は JSON キーで指定されたフィールドを src から dst へコピーします
+func copyTaskField(dst, src *model.Task, key string) {
+	switch key {
+	case "title":
+		dst.Title = src.Title
+	case "description":
+		dst.Description = src.Description
+	case "due_date":
+		dst.DueDate = src.DueDate
+	case "is_completed":
+		dst.IsCompleted = src.IsCompleted
+	case "priority":
+		dst.Priority = src.Priority
+	case "rrule":
+		dst.RRule = src.RRule
+	}
+}
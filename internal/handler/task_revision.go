@@ -0,0 +1,179 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ryory2/test-go-app-todo-go/internal/model"
+	"github.com/ryory2/test-go-app-todo-go/internal/policy"
+)
+
+// GetTaskRevisionsハンドラー
+// HTTP: GET /tasks/{id}/revisions
+func (h *TaskHandler) GetTaskRevisions(c *gin.Context) {
+	task, ok := h.findTask(c)
+	if !ok {
+		return
+	}
+
+	// クエリパラメータを整数に変換
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if err != nil || limit <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit parameter"})
+		return
+	}
+
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid offset parameter"})
+		return
+	}
+
+	// リビジョンを新しい順に取得
+	revisions, total, err := h.tasks(c).GetRevisions(task.ID, limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve revisions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":  revisions,
+		"total": total,
+	})
+}
+
+// GetTaskRevisionハンドラー
+// HTTP: GET /tasks/{id}/revisions/{rev}
+func (h *TaskHandler) GetTaskRevision(c *gin.Context) {
+	task, ok := h.findTask(c)
+	if !ok {
+		return
+	}
+
+	revision, ok := h.findRevision(c, task.ID, c.Param("rev"))
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": revision})
+}
+
+// DiffTaskRevisionsハンドラー
+// HTTP: GET /tasks/{id}/revisions/diff?from={rev}&to={rev}
+//
+// 2つのリビジョン間で変更されたフィールドの変更前（from）と変更後（to）の値を返す
+func (h *TaskHandler) DiffTaskRevisions(c *gin.Context) {
+	task, ok := h.findTask(c)
+	if !ok {
+		return
+	}
+
+	if c.Query("from") == "" || c.Query("to") == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "from and to parameters are required"})
+		return
+	}
+	from, ok := h.findRevision(c, task.ID, c.Query("from"))
+	if !ok {
+		return
+	}
+	to, ok := h.findRevision(c, task.ID, c.Query("to"))
+	if !ok {
+		return
+	}
+
+	changes, err := diffFields(from, to)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compare revisions"})
+		return
+	}
+	// リビジョン自体の情報は差分に含めない
+	delete(changes, "revision")
+	delete(changes, "created_at")
+
+	c.JSON(http.StatusOK, gin.H{"data": gin.H{
+		"from":    from.Revision,
+		"to":      to.Revision,
+		"changes": changes,
+	}})
+}
+
+// RevertTaskハンドラー
+// HTTP: POST /tasks/{id}/revert
+//
+// 指定したリビジョンの内容（タイトル・説明・期限日・優先度・繰り返しルール）にタスクを戻す。
+// 戻した内容は新しいリビジョンとして保存される
+func (h *TaskHandler) RevertTask(c *gin.Context) {
+	task, ok := h.findTask(c)
+	if !ok {
+		return
+	}
+
+	// ロールの権限を確認
+	if !h.authorize(c, task.ID, policy.ActionEdit) {
+		return
+	}
+
+	// If-Match が指定されている場合は現在のバージョンと一致するかを確認
+	if !checkIfMatch(c, task) {
+		return
+	}
+	before := *task // 監査ログ用に変更前の状態を保持
+
+	var input struct {
+		Revision uint `json:"revision" validate:"required"`
+	}
+
+	// リクエストボディをバインド
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON provided"})
+		return
+	}
+
+	// 入力値のバリデーション
+	if err := h.validate.Struct(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// 戻すリビジョンを取得
+	revision, err := h.tasks(c).GetRevision(task.ID, input.Revision)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Revision not found"})
+		return
+	}
+
+	// リビジョンの内容をタスクに戻して更新
+	revision.ApplyTo(task)
+	task.UpdatedAt = time.Now()
+	if err := h.tasks(c).UpdateTask(task); err != nil {
+		if respondVersionConflict(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revert task"})
+		return
+	}
+
+	// 監査ログに記録
+	h.recordTaskAudit(c, model.AuditActionRevert, &before, task)
+
+	// 更新されたタスクを返す
+	respondTask(c, http.StatusOK, task)
+}
+
+// findRevision は文字列で指定されたリビジョン番号からタスクのリビジョンを取得します。失敗時はエラーレスポンスを書き込み false を返します
+func (h *TaskHandler) findRevision(c *gin.Context, taskID uint, value string) (*model.TaskRevision, bool) {
+	rev, err := strconv.ParseUint(value, 10, 0)
+	if err != nil || rev == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid revision"})
+		return nil, false
+	}
+
+	revision, err := h.tasks(c).GetRevision(taskID, uint(rev))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Revision not found"})
+		return nil, false
+	}
+	return revision, true
+}
@@ -3,6 +3,7 @@ package handler
 
 import (
 	"bytes"
+	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"net/http"
@@ -12,28 +13,107 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
+	"github.com/ryory2/test-go-app-todo-go/internal/middleware"
 	"github.com/ryory2/test-go-app-todo-go/internal/model"
 	"github.com/ryory2/test-go-app-todo-go/internal/repository"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
 )
 
 // setupTestHandler はテスト用の Gin エンジンとモックリポジトリをセットアップします。
 func setupTestHandler(t *testing.T) (*gin.Engine, *repository.MockTaskRepository) {
+	router, mockRepo, auditRepo := setupAuditedTestHandler(t)
+	auditRepo.On("CreateAuditLog", mock.Anything).Return(nil).Maybe()
+	return router, mockRepo
+}
+
+// testUserID はテストで認証済みとして扱うユーザーのIDです
+const testUserID uint = 1
+
+// testEventLag はテストで使う担当者の変更イベントの待ち時間です
+const testEventLag = 5 * time.Second
+
+// testTenantID はテストでリクエストのワークスペースとして扱うワークスペースのIDです
+const testTenantID uint = 2
+
+// authenticateAs は userID のユーザーを認証済みとして、testTenantID のワークスペースとともに
+// gin.Context に設定するテスト用のミドルウェアです
+func authenticateAs(userID uint) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		middleware.SetPrincipal(c, &middleware.Principal{UserID: userID})
+		middleware.SetTenant(c, &model.Workspace{ID: testTenantID, Slug: "test"}, model.WorkspaceRoleMember)
+		c.Next()
+	}
+}
+
+// setupAuditedTestHandler は監査ログのモックリポジトリも返す setupTestHandler です。
+// 認証済みのユーザーはすべてのタスク・プロジェクトの owner として扱います
+func setupAuditedTestHandler(t *testing.T) (*gin.Engine, *repository.MockTaskRepository, *repository.MockAuditRepository) {
+	router, mockRepo, auditRepo, memberRepo := setupMemberTestHandler(t)
+	memberRepo.On("GetTaskRole", mock.Anything, testUserID).Return(model.RoleOwner, nil).Maybe()
+	memberRepo.On("GetProjectRole", mock.Anything, testUserID).Return(model.RoleOwner, nil).Maybe()
+	return router, mockRepo, auditRepo
+}
+
+// setupMemberTestHandler はメンバーのモックリポジトリも返す setupAuditedTestHandler です。
+// ロールの期待動作は設定しないため、個別のテストで設定します
+func setupMemberTestHandler(t *testing.T) (*gin.Engine, *repository.MockTaskRepository, *repository.MockAuditRepository, *repository.MockMemberRepository) {
 	gin.SetMode(gin.TestMode)
 	mockRepo := new(repository.MockTaskRepository)
+	auditRepo := new(repository.MockAuditRepository)
+	memberRepo := new(repository.MockMemberRepository)
 	validate := validator.New()
-	handler := NewTaskHandler(mockRepo, validate)
+	handler := NewTaskHandler(mockRepo, auditRepo, memberRepo, validate, testEventLag)
 	router := gin.Default()
+	router.Use(middleware.RequestID())
+	router.Use(authenticateAs(testUserID))
 
 	// エンドポイントの登録
 	router.GET("/tasks", handler.GetTasks)
 	router.POST("/tasks", handler.CreateTask)
+	router.POST("/tasks/batch", handler.BatchTasks)
+	router.POST("/tasks/archive", handler.ArchiveCompletedTasks)
+	router.GET("/tasks/trash", handler.GetTrash)
+	router.DELETE("/tasks/trash", handler.EmptyTrash)
+	router.DELETE("/tasks/trash/:id", handler.PurgeTask)
+	router.GET("/tasks/:id", handler.GetTask)
 	router.PUT("/tasks/:id", handler.UpdateTask)
+	router.PATCH("/tasks/:id", handler.PatchTask)
 	router.DELETE("/tasks/:id", handler.DeleteTask)
 	router.PATCH("/tasks/:id/toggle", handler.ToggleTask)
-
-	return router, mockRepo
+	router.POST("/tasks/:id/restore", handler.RestoreTask)
+	router.POST("/tasks/:id/archive", handler.ArchiveTask)
+	router.POST("/tasks/:id/unarchive", handler.UnarchiveTask)
+	router.PUT("/tasks/:id/tags", handler.SetTaskTags)
+	router.PUT("/tasks/:id/parent", handler.SetTaskParent)
+	router.GET("/tasks/:id/children", handler.GetTaskChildren)
+	router.GET("/tasks/:id/subtree", handler.GetTaskSubtree)
+	router.GET("/tasks/:id/history", handler.GetTaskHistory)
+	router.GET("/tasks/:id/revisions", handler.GetTaskRevisions)
+	router.GET("/tasks/:id/revisions/diff", handler.DiffTaskRevisions)
+	router.GET("/tasks/:id/revisions/:rev", handler.GetTaskRevision)
+	router.POST("/tasks/:id/revert", handler.RevertTask)
+	router.GET("/tasks/:id/members", handler.GetTaskMembers)
+	router.PUT("/tasks/:id/members/:user_id", handler.SetTaskMember)
+	router.DELETE("/tasks/:id/members/:user_id", handler.RemoveTaskMember)
+	router.POST("/tasks/:id/assignees", handler.AssignTask)
+	router.DELETE("/tasks/:id/assignees/:user_id", handler.UnassignTask)
+	router.GET("/tasks/:id/comments", handler.GetTaskComments)
+	router.POST("/tasks/:id/comments", handler.CreateTaskComment)
+	router.PUT("/tasks/:id/comments/:comment_id", handler.UpdateTaskComment)
+	router.DELETE("/tasks/:id/comments/:comment_id", handler.DeleteTaskComment)
+	router.GET("/assignment-events", handler.GetAssignmentEvents)
+	router.POST("/undo/:token", handler.Undo)
+
+	// ワークスペース・認証済みのユーザーへの絞り込みと取り消しトークンの発行は個別のテストで確認する
+	mockRepo.On("ForTenant", testTenantID).Return().Maybe()
+	mockRepo.On("ForUser", testUserID).Return().Maybe()
+	auditRepo.On("ForTenant", testTenantID).Return().Maybe()
+	memberRepo.On("ForTenant", testTenantID).Return().Maybe()
+	mockRepo.On("CreateUndoToken", mock.Anything).Return(nil).Maybe()
+
+	return router, mockRepo, auditRepo, memberRepo
 }
 
 // TestGetTasks は GetTasks ハンドラーの正常動作をテストします。
@@ -58,7 +138,7 @@ func TestGetTasks(t *testing.T) {
 	total := int64(len(tasks))
 
 	// モックリポジトリの期待動作を設定
-	mockRepo.On("GetTasks", "all", 10, 0).Return(tasks, total, nil)
+	mockRepo.On("GetTasks", repository.TaskFilter{Status: "all", Limit: 10}).Return(&repository.TaskPage{Tasks: tasks, Total: total}, nil)
 
 	// テストリクエストを作成
 	req, err := http.NewRequest(http.MethodGet, "/tasks?status=all&limit=10&offset=0", nil)
@@ -236,7 +316,7 @@ func TestDeleteTask(t *testing.T) {
 
 	// モックリポジトリの期待動作を設定
 	mockRepo.On("GetTaskByID", uint(1)).Return(existingTask, nil)
-	mockRepo.On("DeleteTask", existingTask).Return(nil)
+	mockRepo.On("DeleteTask", existingTask).Return([]uint(nil), nil)
 
 	// テストリクエストを作成（DELETE /tasks/1）
 	req, err := http.NewRequest(http.MethodDelete, "/tasks/1", nil)
@@ -335,7 +415,7 @@ func TestGetTasks_RepositoryError(t *testing.T) {
 	router, mockRepo := setupTestHandler(t)
 
 	// リポジトリがエラーを返すように設定（nil ではなく空のスライスを返す）
-	mockRepo.On("GetTasks", "all", 10, 0).Return([]model.Task{}, int64(0), errors.New("database error"))
+	mockRepo.On("GetTasks", repository.TaskFilter{Status: "all", Limit: 10}).Return((*repository.TaskPage)(nil), errors.New("database error"))
 
 	// テストリクエストを作成
 	req, err := http.NewRequest(http.MethodGet, "/tasks?status=all&limit=10&offset=0", nil)
@@ -363,3 +443,1882 @@ func TestGetTasks_RepositoryError(t *testing.T) {
 	// モックリポジトリが期待通りに呼び出されたことを確認
 	mockRepo.AssertExpectations(t)
 }
+
+// TestGetTasks_TagFilter は GetTasks ハンドラーのタグ絞り込みをテストします。
+func TestGetTasks_TagFilter(t *testing.T) {
+	router, mockRepo := setupTestHandler(t)
+
+	// テストデータの準備
+	tasks := []model.Task{
+		{
+			ID:    1,
+			Title: "タスク1",
+			Tags:  []model.Tag{{ID: 1, Name: "backend"}, {ID: 2, Name: "urgent"}},
+		},
+	}
+
+	// カンマ区切りと複数指定の両方がタグ名のリストに展開されることを確認
+	mockRepo.On("GetTasks", repository.TaskFilter{Tags: []string{"backend", "urgent"}, MatchAllTags: true, Limit: 10}).Return(&repository.TaskPage{Tasks: tasks, Total: 1}, nil)
+
+	// テストリクエストを作成
+	req, err := http.NewRequest(http.MethodGet, "/tasks?tags=backend&tags=urgent&tag_match=all", nil)
+	assert.NoError(t, err)
+
+	// レスポンスを記録するためのレスポンスライターを作成
+	w := httptest.NewRecorder()
+
+	// リクエストをルーターに送信
+	router.ServeHTTP(w, req)
+
+	// レスポンスのステータスコードが 200 OK であることを確認
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// モックリポジトリが期待通りに呼び出されたことを確認
+	mockRepo.AssertExpectations(t)
+}
+
+// TestGetTasks_InvalidTagMatch は不正な tag_match 指定時に 400 を返すことをテストします。
+func TestGetTasks_InvalidTagMatch(t *testing.T) {
+	router, mockRepo := setupTestHandler(t)
+
+	// テストリクエストを作成
+	req, err := http.NewRequest(http.MethodGet, "/tasks?tags=backend,frontend&tag_match=some", nil)
+	assert.NoError(t, err)
+
+	// レスポンスを記録するためのレスポンスライターを作成
+	w := httptest.NewRecorder()
+
+	// リクエストをルーターに送信
+	router.ServeHTTP(w, req)
+
+	// レスポンスのステータスコードが 400 Bad Request であることを確認
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// リポジトリが呼び出されていないことを確認
+	mockRepo.AssertNotCalled(t, "GetTasks", mock.Anything)
+}
+
+// TestSetTaskTags_TagNotFound は存在しないタグを指定した場合に 400 を返すことをテストします。
+func TestSetTaskTags_TagNotFound(t *testing.T) {
+	router, mockRepo := setupTestHandler(t)
+
+	existingTask := &model.Task{ID: 1, Title: "タグ付けするタスク"}
+
+	// モックリポジトリの期待動作を設定
+	mockRepo.On("GetTaskByID", uint(1)).Return(existingTask, nil)
+	mockRepo.On("SetTaskTags", existingTask, []uint{1, 99}).Return(repository.ErrTagNotFound)
+
+	// テストリクエストを作成（PUT /tasks/1/tags）
+	req, err := http.NewRequest(http.MethodPut, "/tasks/1/tags", bytes.NewBufferString(`{"tag_ids":[1,99]}`))
+	assert.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+
+	// レスポンスを記録するためのレスポンスライターを作成
+	w := httptest.NewRecorder()
+
+	// リクエストをルーターに送信
+	router.ServeHTTP(w, req)
+
+	// レスポンスのステータスコードが 400 Bad Request であることを確認
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// モックリポジトリが期待通りに呼び出されたことを確認
+	mockRepo.AssertExpectations(t)
+}
+
+// TestCreateTask_ProjectNotFound は存在しないプロジェクトを指定した場合に 400 を返すことをテストします。
+func TestCreateTask_ProjectNotFound(t *testing.T) {
+	router, mockRepo := setupTestHandler(t)
+
+	// 外部キー制約違反を返すように設定
+	mockRepo.On("CreateTask", mock.AnythingOfType("*model.Task")).Return(gorm.ErrForeignKeyViolated)
+
+	// テストリクエストを作成（POST /tasks）
+	req, err := http.NewRequest(http.MethodPost, "/tasks", bytes.NewBufferString(`{"title":"タスク","project_id":99}`))
+	assert.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+
+	// レスポンスを記録するためのレスポンスライターを作成
+	w := httptest.NewRecorder()
+
+	// リクエストをルーターに送信
+	router.ServeHTTP(w, req)
+
+	// レスポンスのステータスコードが 400 Bad Request であることを確認
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// モックリポジトリが期待通りに呼び出されたことを確認
+	mockRepo.AssertExpectations(t)
+}
+
+// TestGetTaskSubtree は GetTaskSubtree ハンドラーがツリー構造を返すことをテストします。
+func TestGetTaskSubtree(t *testing.T) {
+	router, mockRepo := setupTestHandler(t)
+
+	// 1 → 2 → 3 と 1 → 4 のツリー
+	one, two := uint(1), uint(2)
+	progress := 50
+	tasks := []model.Task{
+		{ID: 1, Title: "親タスク", Progress: &progress},
+		{ID: 2, Title: "子タスク", ParentID: &one},
+		{ID: 3, Title: "孫タスク", ParentID: &two, IsCompleted: true},
+		{ID: 4, Title: "子タスク2", ParentID: &one, IsCompleted: true},
+	}
+
+	// モックリポジトリの期待動作を設定
+	mockRepo.On("GetSubtree", uint(1)).Return(tasks, nil)
+
+	// テストリクエストを作成（GET /tasks/1/subtree）
+	req, err := http.NewRequest(http.MethodGet, "/tasks/1/subtree", nil)
+	assert.NoError(t, err)
+
+	// レスポンスを記録するためのレスポンスライターを作成
+	w := httptest.NewRecorder()
+
+	// リクエストをルーターに送信
+	router.ServeHTTP(w, req)
+
+	// レスポンスのステータスコードが 200 OK であることを確認
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// レスポンスボディを解析
+	var response struct {
+		Data model.Task `json:"data"`
+	}
+	err = json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+
+	// 子タスクと孫タスクが入れ子になっていることを確認
+	assert.Equal(t, uint(1), response.Data.ID)
+	assert.Equal(t, 50, *response.Data.Progress)
+	if assert.Len(t, response.Data.Children, 2) {
+		assert.Equal(t, uint(2), response.Data.Children[0].ID)
+		assert.Len(t, response.Data.Children[0].Children, 1)
+		assert.Equal(t, uint(3), response.Data.Children[0].Children[0].ID)
+		assert.Equal(t, uint(4), response.Data.Children[1].ID)
+	}
+
+	// モックリポジトリが期待通りに呼び出されたことを確認
+	mockRepo.AssertExpectations(t)
+}
+
+// TestSetTaskParent_Cycle は子孫タスクの下へ移動しようとした場合に 400 を返すことをテストします。
+func TestSetTaskParent_Cycle(t *testing.T) {
+	router, mockRepo := setupTestHandler(t)
+
+	one := uint(1)
+	parent := &model.Task{ID: 1, Title: "親タスク"}
+	child := &model.Task{ID: 2, Title: "子タスク", ParentID: &one}
+
+	// モックリポジトリの期待動作を設定
+	mockRepo.On("GetTaskByID", uint(1)).Return(parent, nil)
+	mockRepo.On("GetTaskByID", uint(2)).Return(child, nil)
+	mockRepo.On("GetSubtree", uint(1)).Return([]model.Task{*parent, *child}, nil)
+
+	// テストリクエストを作成（PUT /tasks/1/parent）
+	req, err := http.NewRequest(http.MethodPut, "/tasks/1/parent", bytes.NewBufferString(`{"parent_id":2}`))
+	assert.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+
+	// レスポンスを記録するためのレスポンスライターを作成
+	w := httptest.NewRecorder()
+
+	// リクエストをルーターに送信
+	router.ServeHTTP(w, req)
+
+	// レスポンスのステータスコードが 400 Bad Request であることを確認
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// 親タスクが更新されていないことを確認
+	mockRepo.AssertNotCalled(t, "SetTaskParent", mock.Anything, mock.Anything)
+	mockRepo.AssertExpectations(t)
+}
+
+// TestDeleteTask_Cascade は children=cascade で子孫タスクごと削除されることをテストします。
+func TestDeleteTask_Cascade(t *testing.T) {
+	router, mockRepo := setupTestHandler(t)
+
+	existingTask := &model.Task{ID: 1, Title: "削除するタスク"}
+
+	// モックリポジトリの期待動作を設定
+	mockRepo.On("GetTaskByID", uint(1)).Return(existingTask, nil)
+	mockRepo.On("DeleteTaskTree", existingTask).Return(nil)
+
+	// テストリクエストを作成（DELETE /tasks/1?children=cascade）
+	req, err := http.NewRequest(http.MethodDelete, "/tasks/1?children=cascade", nil)
+	assert.NoError(t, err)
+
+	// レスポンスを記録するためのレスポンスライターを作成
+	w := httptest.NewRecorder()
+
+	// リクエストをルーターに送信
+	router.ServeHTTP(w, req)
+
+	// レスポンスのステータスコードが 200 OK であることを確認
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// 子タスクを付け替える通常の削除が呼ばれていないことを確認
+	mockRepo.AssertNotCalled(t, "DeleteTask", mock.Anything)
+	mockRepo.AssertExpectations(t)
+}
+
+// TestToggleTask_Cascade は cascade=true で子孫タスクも完了にするリポジトリ処理が呼ばれることをテストします。
+func TestToggleTask_Cascade(t *testing.T) {
+	router, mockRepo := setupTestHandler(t)
+
+	existingTask := &model.Task{ID: 1, Title: "親タスク"}
+
+	// モックリポジトリの期待動作を設定
+	mockRepo.On("GetTaskByID", uint(1)).Return(existingTask, nil)
+	mockRepo.On("GetSubtree", uint(1)).Return([]model.Task{*existingTask, {ID: 2, Title: "子タスク"}}, nil)
+	mockRepo.On("ToggleTaskCompletionCascade", existingTask).Return(nil).Run(func(args mock.Arguments) {
+		task := args.Get(0).(*model.Task)
+		task.IsCompleted = !task.IsCompleted
+	})
+
+	// テストリクエストを作成（PATCH /tasks/1/toggle?cascade=true）
+	req, err := http.NewRequest(http.MethodPatch, "/tasks/1/toggle?cascade=true", nil)
+	assert.NoError(t, err)
+
+	// レスポンスを記録するためのレスポンスライターを作成
+	w := httptest.NewRecorder()
+
+	// リクエストをルーターに送信
+	router.ServeHTTP(w, req)
+
+	// レスポンスのステータスコードが 200 OK であることを確認
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// モックリポジトリが期待通りに呼び出されたことを確認
+	mockRepo.AssertNotCalled(t, "ToggleTaskCompletion", mock.Anything)
+	mockRepo.AssertExpectations(t)
+}
+
+// TestCreateTask_InvalidRRule は不正な繰り返しルールを指定した場合に 400 を返すことをテストします。
+func TestCreateTask_InvalidRRule(t *testing.T) {
+	router, mockRepo := setupTestHandler(t)
+
+	inputs := []string{
+		// 未対応の頻度
+		`{"title":"タスク","due_date":"2025-01-06T00:00:00Z","rrule":"FREQ=HOURLY"}`,
+		// 期限日のない繰り返しタスク
+		`{"title":"タスク","rrule":"FREQ=WEEKLY"}`,
+	}
+
+	for _, input := range inputs {
+		// テストリクエストを作成（POST /tasks）
+		req, err := http.NewRequest(http.MethodPost, "/tasks", bytes.NewBufferString(input))
+		assert.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+
+		// リクエストをルーターに送信
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		// レスポンスのステータスコードが 400 Bad Request であることを確認
+		assert.Equal(t, http.StatusBadRequest, w.Code, input)
+	}
+
+	// リポジトリが呼び出されていないことを確認
+	mockRepo.AssertNotCalled(t, "CreateTask", mock.Anything)
+}
+
+// TestGetTasks_Occurrences は繰り返しタスクの発生予定が、一覧のページに含まれないタスクも含めて展開されることをテストします。
+func TestGetTasks_Occurrences(t *testing.T) {
+	router, mockRepo := setupTestHandler(t)
+
+	// 一覧のページには通常のタスクのみが含まれ、毎週月曜日の繰り返しタスクは別のページにある
+	tasks := []model.Task{
+		{ID: 2, Title: "単発タスク", DueDate: time.Date(2025, 1, 7, 0, 0, 0, 0, time.UTC)},
+	}
+	recurring := []model.Task{
+		{ID: 1, Title: "週次定例", DueDate: time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC), RRule: "FREQ=WEEKLY;BYDAY=MO"},
+	}
+
+	// モックリポジトリの期待動作を設定
+	mockRepo.On("GetTasks", repository.TaskFilter{Limit: 10}).Return(&repository.TaskPage{Tasks: tasks, Total: 2}, nil)
+	mockRepo.On("GetRecurringTasks", repository.TaskFilter{Limit: 10}, time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC).Add(-time.Nanosecond)).Return(recurring, nil)
+
+	// テストリクエストを作成
+	req, err := http.NewRequest(http.MethodGet, "/tasks?occurrences_from=2025-01-01&occurrences_to=2025-01-31", nil)
+	assert.NoError(t, err)
+
+	// レスポンスを記録するためのレスポンスライターを作成
+	w := httptest.NewRecorder()
+
+	// リクエストをルーターに送信
+	router.ServeHTTP(w, req)
+
+	// レスポンスのステータスコードが 200 OK であることを確認
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// レスポンスボディを解析
+	var response struct {
+		Occurrences []model.Occurrence `json:"occurrences"`
+	}
+	err = json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+
+	// タスク自身の期限日（1/6）を除いた1月中の月曜日が展開されることを確認
+	if assert.Len(t, response.Occurrences, 3) {
+		assert.Equal(t, uint(1), response.Occurrences[0].TaskID)
+		assert.Equal(t, time.Date(2025, 1, 13, 0, 0, 0, 0, time.UTC), response.Occurrences[0].DueDate)
+		assert.Equal(t, time.Date(2025, 1, 27, 0, 0, 0, 0, time.UTC), response.Occurrences[2].DueDate)
+	}
+
+	// モックリポジトリが期待通りに呼び出されたことを確認
+	mockRepo.AssertExpectations(t)
+}
+
+// TestGetTasks_Sort は sort パラメータが並び順のキーに変換されることをテストします。
+func TestGetTasks_Sort(t *testing.T) {
+	router, mockRepo := setupTestHandler(t)
+
+	// 優先度の降順、期限日の昇順
+	expected := []repository.SortField{
+		{Field: "priority", Desc: true},
+		{Field: "due_date", Desc: false},
+	}
+	mockRepo.On("GetTasks", repository.TaskFilter{Sort: expected, Limit: 10}).Return(&repository.TaskPage{Tasks: []model.Task{}}, nil)
+
+	// テストリクエストを作成
+	req, err := http.NewRequest(http.MethodGet, "/tasks?sort=-priority,due_date", nil)
+	assert.NoError(t, err)
+
+	// リクエストをルーターに送信
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// レスポンスのステータスコードが 200 OK であることを確認
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// モックリポジトリが期待通りに呼び出されたことを確認
+	mockRepo.AssertExpectations(t)
+}
+
+// TestGetTasks_InvalidSort は未対応の並び替えキーを指定した場合に 400 を返すことをテストします。
+func TestGetTasks_InvalidSort(t *testing.T) {
+	router, mockRepo := setupTestHandler(t)
+
+	for _, sort := range []string{"description", "priority,-priority"} {
+		// テストリクエストを作成
+		req, err := http.NewRequest(http.MethodGet, "/tasks?sort="+sort, nil)
+		assert.NoError(t, err)
+
+		// リクエストをルーターに送信
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		// レスポンスのステータスコードが 400 Bad Request であることを確認
+		assert.Equal(t, http.StatusBadRequest, w.Code, sort)
+	}
+
+	// リポジトリが呼び出されていないことを確認
+	mockRepo.AssertNotCalled(t, "GetTasks", mock.Anything)
+}
+
+// TestGetTasks_Search は q パラメータがキーワード検索としてリポジトリに渡されることをテストします。
+func TestGetTasks_Search(t *testing.T) {
+	router, mockRepo := setupTestHandler(t)
+
+	tasks := []model.Task{{ID: 3, Title: "請求書の送付"}}
+
+	// 前後の空白は取り除かれることを確認
+	mockRepo.On("GetTasks", repository.TaskFilter{Query: "請求書", Limit: 10}).Return(&repository.TaskPage{Tasks: tasks, Total: 1}, nil)
+
+	// テストリクエストを作成
+	req, err := http.NewRequest(http.MethodGet, "/tasks?q=%20%E8%AB%8B%E6%B1%82%E6%9B%B8%20", nil)
+	assert.NoError(t, err)
+
+	// リクエストをルーターに送信
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// レスポンスのステータスコードが 200 OK であることを確認
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// モックリポジトリが期待通りに呼び出されたことを確認
+	mockRepo.AssertExpectations(t)
+}
+
+// TestGetTasks_Filters は日時範囲・ID・真偽値の絞り込み条件がリポジトリに渡されることをテストします。
+func TestGetTasks_Filters(t *testing.T) {
+	router, mockRepo := setupTestHandler(t)
+
+	dueBefore := time.Date(2024, 12, 1, 0, 0, 0, 0, time.UTC)
+	createdAfter := time.Date(2024, 11, 1, 0, 0, 0, 0, time.UTC).AddDate(0, 0, 1).Add(-time.Nanosecond)
+	overdue := true
+	hasDescription := false
+
+	// モックリポジトリの期待動作を設定
+	mockRepo.On("GetTasks", repository.TaskFilter{
+		IDs:            []uint{1, 2, 3},
+		DueBefore:      &dueBefore,
+		CreatedAfter:   &createdAfter,
+		Overdue:        &overdue,
+		HasDescription: &hasDescription,
+		Limit:          10,
+	}).Return(&repository.TaskPage{Tasks: []model.Task{}}, nil)
+
+	// テストリクエストを作成
+	req, err := http.NewRequest(http.MethodGet, "/tasks?ids=1,2&ids=3&due_before=2024-12-01&created_after=2024-11-01&overdue=true&has_description=false", nil)
+	assert.NoError(t, err)
+
+	// リクエストをルーターに送信
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// レスポンスのステータスコードが 200 OK であることを確認
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// モックリポジトリが期待通りに呼び出されたことを確認
+	mockRepo.AssertExpectations(t)
+}
+
+// TestGetTasks_InvalidFilters は未知・不正なクエリパラメータを指定した場合に 400 を返すことをテストします。
+func TestGetTasks_InvalidFilters(t *testing.T) {
+	router, mockRepo := setupTestHandler(t)
+
+	tests := []struct {
+		query    string
+		expected string
+	}{
+		{"foo=bar", "Unknown query parameter: foo"},
+		{"status=done", "Invalid status parameter"},
+		{"archived=yes", "Invalid archived parameter"},
+		{"due_before=2024-13-01", "Invalid due_before parameter"},
+		{"overdue=maybe", "Invalid overdue parameter"},
+		{"ids=1,abc", "Invalid ids parameter"},
+		{"assignee=someone", "Invalid assignee parameter"},
+		{"created_after=2024-12-01&created_before=2024-11-01", "created_after must be earlier than created_before"},
+		{"limit=10&limit=20", "Duplicate query parameter: limit"},
+	}
+
+	for _, tt := range tests {
+		req, err := http.NewRequest(http.MethodGet, "/tasks?"+tt.query, nil)
+		assert.NoError(t, err)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		// レスポンスのステータスコードが 400 Bad Request であることを確認
+		assert.Equal(t, http.StatusBadRequest, w.Code, tt.query)
+
+		var response map[string]interface{}
+		err = json.Unmarshal(w.Body.Bytes(), &response)
+		assert.NoError(t, err)
+		assert.Equal(t, tt.expected, response["error"], tt.query)
+	}
+
+	// リポジトリが呼び出されていないことを確認
+	mockRepo.AssertNotCalled(t, "GetTasks", mock.Anything)
+}
+
+// TestGetTasks_Cursor はカーソル指定時にキーセットページングの条件が渡され、全件数が省略されることをテストします。
+func TestGetTasks_Cursor(t *testing.T) {
+	router, mockRepo := setupTestHandler(t)
+
+	sortFields := []repository.SortField{{Field: "title"}}
+	token := base64.RawURLEncoding.EncodeToString([]byte(`{"s":"title","v":["買い物","7"]}`))
+	cursor, err := repository.DecodeCursor(token, sortFields)
+	assert.NoError(t, err)
+
+	tasks := []model.Task{{ID: 8, Title: "掃除"}}
+
+	// モックリポジトリの期待動作を設定
+	mockRepo.On("GetTasks", repository.TaskFilter{Sort: sortFields, Limit: 1, Cursor: cursor, SkipCount: true}).
+		Return(&repository.TaskPage{Tasks: tasks, NextCursor: "next", PrevCursor: "prev"}, nil)
+
+	// テストリクエストを作成
+	req, err := http.NewRequest(http.MethodGet, "/tasks?sort=title&limit=1&cursor="+token, nil)
+	assert.NoError(t, err)
+
+	// リクエストをルーターに送信
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// レスポンスのステータスコードが 200 OK であることを確認
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// 前後のカーソルが含まれ、total が省略されていることを確認
+	var response map[string]interface{}
+	err = json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, "next", response["next_cursor"])
+	assert.Equal(t, "prev", response["prev_cursor"])
+	assert.NotContains(t, response, "total")
+
+	// モックリポジトリが期待通りに呼び出されたことを確認
+	mockRepo.AssertExpectations(t)
+}
+
+// TestGetTasks_InvalidCursor は不正なカーソルや並び順の異なるカーソルを指定した場合に 400 を返すことをテストします。
+func TestGetTasks_InvalidCursor(t *testing.T) {
+	router, mockRepo := setupTestHandler(t)
+
+	token := base64.RawURLEncoding.EncodeToString([]byte(`{"s":"title","v":["買い物","7"]}`))
+	queries := []string{
+		"cursor=not-a-cursor",
+		"sort=-title&cursor=" + token,
+		"sort=title&offset=10&cursor=" + token,
+	}
+
+	for _, query := range queries {
+		req, err := http.NewRequest(http.MethodGet, "/tasks?"+query, nil)
+		assert.NoError(t, err)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		// レスポンスのステータスコードが 400 Bad Request であることを確認
+		assert.Equal(t, http.StatusBadRequest, w.Code, query)
+	}
+
+	// リポジトリが呼び出されていないことを確認
+	mockRepo.AssertNotCalled(t, "GetTasks", mock.Anything)
+}
+
+// TestPatchTask_MergePatch はマージパッチで指定したフィールドのみが変更されることをテストします。
+func TestPatchTask_MergePatch(t *testing.T) {
+	router, mockRepo := setupTestHandler(t)
+
+	dueDate := time.Date(2024, 12, 1, 0, 0, 0, 0, time.UTC)
+	existingTask := &model.Task{ID: 1, Title: "元のタスク", Description: "元の詳細", DueDate: dueDate, IsCompleted: true, Priority: model.PriorityHigh}
+
+	// モックリポジトリの期待動作を設定（タイトル以外は変更されない）
+	mockRepo.On("GetTaskByID", uint(1)).Return(existingTask, nil)
+	mockRepo.On("UpdateTask", mock.MatchedBy(func(t *model.Task) bool {
+		return t.Title == "新しいタイトル" &&
+			t.Description == "元の詳細" &&
+			t.DueDate.Equal(dueDate) &&
+			t.IsCompleted &&
+			t.Priority == model.PriorityHigh
+	})).Return(nil)
+
+	// テストリクエストを作成（PATCH /tasks/1）
+	req, err := http.NewRequest(http.MethodPatch, "/tasks/1", bytes.NewBufferString(`{"title":"新しいタイトル"}`))
+	assert.NoError(t, err)
+	req.Header.Set("Content-Type", "application/merge-patch+json")
+
+	// リクエストをルーターに送信
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// レスポンスのステータスコードが 200 OK であることを確認
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// モックリポジトリが期待通りに呼び出されたことを確認
+	mockRepo.AssertExpectations(t)
+}
+
+// TestPatchTask_JSONPatch は JSON Patch の操作が適用されることをテストします。
+func TestPatchTask_JSONPatch(t *testing.T) {
+	router, mockRepo := setupTestHandler(t)
+
+	existingTask := &model.Task{ID: 1, Title: "タスク", Description: "詳細", Priority: model.PriorityLow}
+
+	// モックリポジトリの期待動作を設定
+	mockRepo.On("GetTaskByID", uint(1)).Return(existingTask, nil)
+	mockRepo.On("UpdateTask", mock.MatchedBy(func(t *model.Task) bool {
+		return t.Title == "タスク" && t.Description == "" && t.Priority == model.PriorityUrgent
+	})).Return(nil)
+
+	patch := `[
+		{"op":"test","path":"/priority","value":"low"},
+		{"op":"replace","path":"/priority","value":"urgent"},
+		{"op":"remove","path":"/description"}
+	]`
+	req, err := http.NewRequest(http.MethodPatch, "/tasks/1", bytes.NewBufferString(patch))
+	assert.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json-patch+json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// レスポンスのステータスコードが 200 OK であることを確認
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	mockRepo.AssertExpectations(t)
+}
+
+// TestPatchTask_Errors はパッチの内容に応じたエラーレスポンスをテストします。
+func TestPatchTask_Errors(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		status      int
+	}{
+		{"変更できないフィールド", "application/merge-patch+json", `{"id":2}`, http.StatusBadRequest},
+		{"変更したフィールドの検証エラー", "application/merge-patch+json", `{"title":null}`, http.StatusBadRequest},
+		{"型の不一致", "application/merge-patch+json", `{"is_completed":"yes"}`, http.StatusBadRequest},
+		{"test 操作の不一致", "application/json-patch+json", `[{"op":"test","path":"/title","value":"別のタイトル"}]`, http.StatusConflict},
+		{"不正な JSON Patch", "application/json-patch+json", `[{"op":"replace","path":"/unknown","value":1}]`, http.StatusBadRequest},
+		{"未対応の Content-Type", "text/plain", `title=x`, http.StatusUnsupportedMediaType},
+	}
+
+	for _, tt := range tests {
+		router, mockRepo := setupTestHandler(t)
+		mockRepo.On("GetTaskByID", uint(1)).Return(&model.Task{ID: 1, Title: "タスク"}, nil)
+
+		req, err := http.NewRequest(http.MethodPatch, "/tasks/1", bytes.NewBufferString(tt.body))
+		assert.NoError(t, err)
+		req.Header.Set("Content-Type", tt.contentType)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, tt.status, w.Code, tt.name)
+
+		// 更新が呼び出されていないことを確認
+		mockRepo.AssertNotCalled(t, "UpdateTask", mock.Anything)
+	}
+}
+
+// TestUpdateTask_IfMatch は If-Match が現在の ETag と一致しない場合に 412 を返すことをテストします。
+func TestUpdateTask_IfMatch(t *testing.T) {
+	router, mockRepo := setupTestHandler(t)
+
+	existingTask := &model.Task{ID: 1, Title: "タスク", Version: 3}
+
+	// モックリポジトリの期待動作を設定
+	mockRepo.On("GetTaskByID", uint(1)).Return(existingTask, nil)
+
+	// 古いバージョンの ETag を指定して更新
+	req, err := http.NewRequest(http.MethodPut, "/tasks/1", bytes.NewBufferString(`{"title":"更新"}`))
+	assert.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("If-Match", `"1-2"`)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// レスポンスのステータスコードが 412 Precondition Failed であることを確認
+	assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+	assert.Equal(t, `"1-3"`, w.Header().Get("ETag"))
+
+	// 更新が呼び出されていないことを確認
+	mockRepo.AssertNotCalled(t, "UpdateTask", mock.Anything)
+	mockRepo.AssertExpectations(t)
+}
+
+// TestDeleteTask_IfMatch は If-Match が一致する場合に削除されることをテストします。
+func TestDeleteTask_IfMatch(t *testing.T) {
+	router, mockRepo := setupTestHandler(t)
+
+	existingTask := &model.Task{ID: 1, Title: "タスク", Version: 3}
+
+	// モックリポジトリの期待動作を設定
+	mockRepo.On("GetTaskByID", uint(1)).Return(existingTask, nil)
+	mockRepo.On("DeleteTask", existingTask).Return([]uint(nil), nil)
+
+	req, err := http.NewRequest(http.MethodDelete, "/tasks/1", nil)
+	assert.NoError(t, err)
+	req.Header.Set("If-Match", `"1-2", "1-3"`)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// レスポンスのステータスコードが 200 OK であることを確認
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	mockRepo.AssertExpectations(t)
+}
+
+// TestToggleTask_VersionConflict は読み込み後に他の更新があった場合に 409 を返すことをテストします。
+func TestToggleTask_VersionConflict(t *testing.T) {
+	router, mockRepo := setupTestHandler(t)
+
+	existingTask := &model.Task{ID: 1, Title: "タスク", Version: 3}
+
+	// モックリポジトリの期待動作を設定
+	mockRepo.On("GetTaskByID", uint(1)).Return(existingTask, nil)
+	mockRepo.On("ToggleTaskCompletion", existingTask).Return(repository.ErrVersionConflict)
+
+	req, err := http.NewRequest(http.MethodPatch, "/tasks/1/toggle", nil)
+	assert.NoError(t, err)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// レスポンスのステータスコードが 409 Conflict であることを確認
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	mockRepo.AssertExpectations(t)
+}
+
+// TestGetTasks_IfNoneMatch は If-None-Match が一覧の ETag と一致する場合に 304 を返すことをテストします。
+func TestGetTasks_IfNoneMatch(t *testing.T) {
+	router, mockRepo := setupTestHandler(t)
+
+	tasks := []model.Task{{ID: 1, Title: "タスク", Version: 1}}
+
+	// モックリポジトリの期待動作を設定
+	mockRepo.On("GetTasks", repository.TaskFilter{Limit: 10}).Return(&repository.TaskPage{Tasks: tasks, Total: 1}, nil)
+
+	// 1回目のリクエストで ETag を取得
+	req, err := http.NewRequest(http.MethodGet, "/tasks", nil)
+	assert.NoError(t, err)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	etag := w.Header().Get("ETag")
+	assert.NotEmpty(t, etag)
+
+	// 2回目は If-None-Match を指定
+	req, err = http.NewRequest(http.MethodGet, "/tasks", nil)
+	assert.NoError(t, err)
+	req.Header.Set("If-None-Match", etag)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// レスポンスのステータスコードが 304 Not Modified であることを確認
+	assert.Equal(t, http.StatusNotModified, w.Code)
+	assert.Empty(t, w.Body.String())
+
+	mockRepo.AssertExpectations(t)
+}
+
+// TestGetTask は GetTask ハンドラーが ETag 付きでタスクを返し、If-None-Match で 304 を返すことをテストします。
+func TestGetTask(t *testing.T) {
+	router, mockRepo := setupTestHandler(t)
+
+	task := &model.Task{ID: 1, Title: "タスク", Description: "詳細", Version: 2}
+
+	// モックリポジトリの期待動作を設定
+	mockRepo.On("GetTaskByID", uint(1)).Return(task, nil)
+
+	// テストリクエストを作成（GET /tasks/1）
+	req, err := http.NewRequest(http.MethodGet, "/tasks/1", nil)
+	assert.NoError(t, err)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// レスポンスのステータスコードが 200 OK であり、ETag が設定されていることを確認
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `"1-2"`, w.Header().Get("ETag"))
+
+	// If-None-Match が一致する場合は 304 Not Modified
+	req, err = http.NewRequest(http.MethodGet, "/tasks/1", nil)
+	assert.NoError(t, err)
+	req.Header.Set("If-None-Match", `"1-2"`)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotModified, w.Code)
+
+	mockRepo.AssertExpectations(t)
+}
+
+// TestGetTask_NotFound は存在しないタスクを取得した場合に 404 を返すことをテストします。
+func TestGetTask_NotFound(t *testing.T) {
+	router, mockRepo := setupTestHandler(t)
+
+	// モックリポジトリの期待動作を設定
+	mockRepo.On("GetTaskByID", uint(99)).Return((*model.Task)(nil), gorm.ErrRecordNotFound)
+
+	req, err := http.NewRequest(http.MethodGet, "/tasks/99", nil)
+	assert.NoError(t, err)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// レスポンスのステータスコードが 404 Not Found であることを確認
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	mockRepo.AssertExpectations(t)
+}
+
+// TestGetTask_Fields は fields パラメータで指定したフィールドのみが返されることをテストします。
+func TestGetTask_Fields(t *testing.T) {
+	router, mockRepo := setupTestHandler(t)
+
+	task := &model.Task{ID: 1, Title: "タスク", Description: "詳細", IsCompleted: true}
+
+	// モックリポジトリの期待動作を設定
+	mockRepo.On("GetTaskByID", uint(1)).Return(task, nil)
+
+	req, err := http.NewRequest(http.MethodGet, "/tasks/1?fields=title,is_completed", nil)
+	assert.NoError(t, err)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// レスポンスのステータスコードが 200 OK であることを確認
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// id と指定したフィールドのみが含まれることを確認
+	var response map[string]map[string]interface{}
+	err = json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"id": float64(1), "title": "タスク", "is_completed": true}, response["data"])
+
+	mockRepo.AssertExpectations(t)
+}
+
+// TestGetTasks_Fields は一覧でも fields パラメータが適用され、未知のフィールドは 400 になることをテストします。
+func TestGetTasks_Fields(t *testing.T) {
+	router, mockRepo := setupTestHandler(t)
+
+	tasks := []model.Task{{ID: 1, Title: "タスク1", Description: "詳細"}, {ID: 2, Title: "タスク2"}}
+
+	// モックリポジトリの期待動作を設定
+	mockRepo.On("GetTasks", repository.TaskFilter{Limit: 10}).Return(&repository.TaskPage{Tasks: tasks, Total: 2}, nil)
+
+	req, err := http.NewRequest(http.MethodGet, "/tasks?fields=title", nil)
+	assert.NoError(t, err)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// レスポンスのステータスコードが 200 OK であることを確認
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response map[string]interface{}
+	err = json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"id": float64(1), "title": "タスク1"},
+		map[string]interface{}{"id": float64(2), "title": "タスク2"},
+	}, response["data"])
+
+	// 未知のフィールドは 400 Bad Request
+	req, err = http.NewRequest(http.MethodGet, "/tasks?fields=title,password", nil)
+	assert.NoError(t, err)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	mockRepo.AssertExpectations(t)
+}
+
+// TestBatchTasks は一括操作の各操作が実行され、操作ごとの結果が返されることをテストします。
+func TestBatchTasks(t *testing.T) {
+	router, mockRepo := setupTestHandler(t)
+
+	pending := &model.Task{ID: 1, Title: "未完了", Version: 1}
+	obsolete := &model.Task{ID: 2, Title: "不要", Version: 1}
+
+	// モックリポジトリの期待動作を設定
+	mockRepo.On("Transaction").Return()
+	mockRepo.On("CreateTask", mock.MatchedBy(func(t *model.Task) bool { return t.Title == "新規" })).Return(nil)
+	mockRepo.On("GetTaskByID", uint(1)).Return(pending, nil)
+	mockRepo.On("ToggleTaskCompletion", pending).Return(nil)
+	mockRepo.On("GetTaskByID", uint(2)).Return(obsolete, nil)
+	mockRepo.On("DeleteTask", obsolete).Return([]uint(nil), nil)
+
+	body := `{"operations":[
+		{"op":"create","task":{"title":"新規"}},
+		{"op":"complete","id":1},
+		{"op":"delete","id":2,"if_match":"\"2-1\""}
+	]}`
+	req, err := http.NewRequest(http.MethodPost, "/tasks/batch", bytes.NewBufferString(body))
+	assert.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// レスポンスのステータスコードが 200 OK であることを確認
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// 操作ごとのステータスを確認
+	var response struct {
+		Data []batchResult `json:"data"`
+	}
+	err = json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	if assert.Len(t, response.Data, 3) {
+		assert.Equal(t, http.StatusCreated, response.Data[0].Status)
+		assert.Equal(t, http.StatusOK, response.Data[1].Status)
+		assert.Equal(t, http.StatusOK, response.Data[2].Status)
+	}
+
+	mockRepo.AssertExpectations(t)
+}
+
+// TestBatchTasks_Atomic は atomic モードで失敗した操作があった場合に全体が失敗として返されることをテストします。
+func TestBatchTasks_Atomic(t *testing.T) {
+	router, mockRepo := setupTestHandler(t)
+
+	task := &model.Task{ID: 1, Title: "タスク", Version: 1}
+
+	// モックリポジトリの期待動作を設定
+	mockRepo.On("Transaction").Return()
+	mockRepo.On("GetTaskByID", uint(1)).Return(task, nil)
+	mockRepo.On("ToggleTaskCompletion", task).Return(nil)
+	mockRepo.On("GetTaskByID", uint(99)).Return((*model.Task)(nil), gorm.ErrRecordNotFound)
+
+	body := `{"operations":[{"op":"complete","id":1},{"op":"delete","id":99},{"op":"delete","id":1}]}`
+	req, err := http.NewRequest(http.MethodPost, "/tasks/batch", bytes.NewBufferString(body))
+	assert.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// 失敗した操作のステータスコード（404 Not Found）が返されることを確認
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	var response struct {
+		Data []batchResult `json:"data"`
+	}
+	err = json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	if assert.Len(t, response.Data, 3) {
+		assert.Equal(t, http.StatusFailedDependency, response.Data[0].Status)
+		assert.Equal(t, http.StatusNotFound, response.Data[1].Status)
+		assert.Equal(t, http.StatusFailedDependency, response.Data[2].Status)
+	}
+
+	// 失敗以降の操作は実行されていないことを確認
+	mockRepo.AssertNotCalled(t, "DeleteTask", mock.Anything)
+	mockRepo.AssertExpectations(t)
+}
+
+// TestBatchTasks_Partial は partial モードで失敗した操作以外が実行されることをテストします。
+func TestBatchTasks_Partial(t *testing.T) {
+	router, mockRepo := setupTestHandler(t)
+
+	task := &model.Task{ID: 1, Title: "タスク", Version: 1}
+
+	// モックリポジトリの期待動作を設定
+	mockRepo.On("Transaction").Return()
+	mockRepo.On("GetTaskByID", uint(1)).Return(task, nil)
+	mockRepo.On("UpdateTask", mock.MatchedBy(func(t *model.Task) bool { return t.Title == "変更後" })).Return(nil)
+
+	body := `{"mode":"partial","operations":[{"op":"update","id":1,"task":{"title":""}},{"op":"update","id":1,"task":{"title":"変更後"}}]}`
+	req, err := http.NewRequest(http.MethodPost, "/tasks/batch", bytes.NewBufferString(body))
+	assert.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// レスポンスのステータスコードが 200 OK であることを確認
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response struct {
+		Data []batchResult `json:"data"`
+	}
+	err = json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	if assert.Len(t, response.Data, 2) {
+		assert.Equal(t, http.StatusBadRequest, response.Data[0].Status)
+		assert.Equal(t, http.StatusOK, response.Data[1].Status)
+	}
+
+	mockRepo.AssertExpectations(t)
+}
+
+// TestGetTrash は GetTrash ハンドラーがゴミ箱のタスクを返すことをテストします。
+func TestGetTrash(t *testing.T) {
+	router, mockRepo := setupTestHandler(t)
+
+	deletedAt := gorm.DeletedAt{Time: time.Now(), Valid: true}
+	tasks := []model.Task{{ID: 3, Title: "削除したタスク", DeletedAt: deletedAt}}
+
+	// モックリポジトリの期待動作を設定
+	mockRepo.On("GetDeletedTasks", 20, 0).Return(tasks, int64(1), nil)
+
+	// テストリクエストを作成（GET /tasks/trash?limit=20）
+	req, err := http.NewRequest(http.MethodGet, "/tasks/trash?limit=20", nil)
+	assert.NoError(t, err)
+
+	// レスポンスを記録するためのレスポンスライターを作成
+	w := httptest.NewRecorder()
+
+	// リクエストをルーターに送信
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// レスポンスボディを解析
+	var response map[string]interface{}
+	err = json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+
+	assert.Equal(t, float64(1), response["total"])
+	data := response["data"].([]interface{})
+	if assert.Len(t, data, 1) {
+		assert.NotNil(t, data[0].(map[string]interface{})["deleted_at"])
+	}
+
+	mockRepo.AssertExpectations(t)
+}
+
+// TestRestoreTask はゴミ箱のタスクを復元できることをテストします。
+func TestRestoreTask(t *testing.T) {
+	router, mockRepo := setupTestHandler(t)
+
+	deletedTask := &model.Task{ID: 3, Title: "削除したタスク", Version: 2}
+
+	// モックリポジトリの期待動作を設定
+	mockRepo.On("GetDeletedTaskByID", uint(3)).Return(deletedTask, nil)
+	mockRepo.On("RestoreTask", deletedTask).Return(nil)
+
+	// テストリクエストを作成（POST /tasks/3/restore）
+	req, err := http.NewRequest(http.MethodPost, "/tasks/3/restore", nil)
+	assert.NoError(t, err)
+
+	// レスポンスを記録するためのレスポンスライターを作成
+	w := httptest.NewRecorder()
+
+	// リクエストをルーターに送信
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `"3-2"`, w.Header().Get("ETag"))
+
+	mockRepo.AssertExpectations(t)
+}
+
+// TestRestoreTask_ProjectOwner は他のユーザーが所有するタスクでも、削除できるロールを持つユーザーは復元できることをテストします。
+func TestRestoreTask_ProjectOwner(t *testing.T) {
+	router, mockRepo, auditRepo, memberRepo := setupMemberTestHandler(t)
+
+	ownerID := uint(5)
+	deletedTask := &model.Task{ID: 3, Title: "共有されたタスク", OwnerID: &ownerID, Version: 2}
+
+	// モックリポジトリの期待動作を設定
+	mockRepo.On("GetDeletedTaskByID", uint(3)).Return(deletedTask, nil)
+	mockRepo.On("RestoreTask", deletedTask).Return(nil)
+	memberRepo.On("GetTaskRole", uint(3), testUserID).Return(model.RoleOwner, nil)
+	auditRepo.On("CreateAuditLog", mock.Anything).Return(nil)
+
+	// テストリクエストを作成（POST /tasks/3/restore）
+	req, err := http.NewRequest(http.MethodPost, "/tasks/3/restore", nil)
+	assert.NoError(t, err)
+
+	// リクエストをルーターに送信
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockRepo.AssertExpectations(t)
+}
+
+// TestRestoreTask_Editor は削除できないロールでは復元できないことをテストします。
+func TestRestoreTask_Editor(t *testing.T) {
+	router, mockRepo, _, memberRepo := setupMemberTestHandler(t)
+
+	ownerID := uint(5)
+	mockRepo.On("GetDeletedTaskByID", uint(3)).Return(&model.Task{ID: 3, Title: "共有されたタスク", OwnerID: &ownerID}, nil)
+	memberRepo.On("GetTaskRole", uint(3), testUserID).Return(model.RoleEditor, nil)
+
+	req, err := http.NewRequest(http.MethodPost, "/tasks/3/restore", nil)
+	assert.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	mockRepo.AssertNotCalled(t, "RestoreTask", mock.Anything)
+}
+
+// TestRestoreTask_NotInTrash はゴミ箱にないタスクの復元が 404 になることをテストします。
+func TestRestoreTask_NotInTrash(t *testing.T) {
+	router, mockRepo := setupTestHandler(t)
+
+	// モックリポジトリの期待動作を設定
+	mockRepo.On("GetDeletedTaskByID", uint(1)).Return((*model.Task)(nil), gorm.ErrRecordNotFound)
+
+	// テストリクエストを作成（POST /tasks/1/restore）
+	req, err := http.NewRequest(http.MethodPost, "/tasks/1/restore", nil)
+	assert.NoError(t, err)
+
+	// レスポンスを記録するためのレスポンスライターを作成
+	w := httptest.NewRecorder()
+
+	// リクエストをルーターに送信
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Contains(t, w.Body.String(), "Task not found in trash")
+
+	mockRepo.AssertNotCalled(t, "RestoreTask", mock.Anything)
+	mockRepo.AssertExpectations(t)
+}
+
+// TestPurgeTask はゴミ箱のタスクを完全に削除できることをテストします。
+func TestPurgeTask(t *testing.T) {
+	router, mockRepo := setupTestHandler(t)
+
+	deletedTask := &model.Task{ID: 3, Title: "削除したタスク"}
+
+	// モックリポジトリの期待動作を設定
+	mockRepo.On("GetDeletedTaskByID", uint(3)).Return(deletedTask, nil)
+	mockRepo.On("PurgeTask", deletedTask).Return(nil)
+
+	// テストリクエストを作成（DELETE /tasks/trash/3）
+	req, err := http.NewRequest(http.MethodDelete, "/tasks/trash/3", nil)
+	assert.NoError(t, err)
+
+	// レスポンスを記録するためのレスポンスライターを作成
+	w := httptest.NewRecorder()
+
+	// リクエストをルーターに送信
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "Task purged successfully")
+
+	mockRepo.AssertExpectations(t)
+}
+
+// TestEmptyTrash はゴミ箱を空にし、削除したタスクごとに監査ログに記録することをテストします。
+func TestEmptyTrash(t *testing.T) {
+	router, mockRepo, auditRepo := setupAuditedTestHandler(t)
+
+	// モックリポジトリの期待動作を設定
+	purged := []model.Task{{ID: 1}, {ID: 2}, {ID: 3}, {ID: 4}}
+	mockRepo.On("PurgeDeletedTasks", mock.AnythingOfType("time.Time")).Return(purged, nil)
+	auditRepo.On("CreateAuditLog", mock.MatchedBy(func(entry *model.AuditLog) bool {
+		return entry.Action == model.AuditActionPurge
+	})).Return(nil).Times(4)
+
+	// テストリクエストを作成（DELETE /tasks/trash）
+	req, err := http.NewRequest(http.MethodDelete, "/tasks/trash", nil)
+	assert.NoError(t, err)
+
+	// レスポンスを記録するためのレスポンスライターを作成
+	w := httptest.NewRecorder()
+
+	// リクエストをルーターに送信
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// レスポンスボディを解析
+	var response map[string]interface{}
+	err = json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, float64(4), response["purged"])
+
+	mockRepo.AssertExpectations(t)
+	auditRepo.AssertExpectations(t)
+}
+
+// TestGetTasks_Archived は archived パラメータがアーカイブ済みタスクの絞り込み条件として渡されることをテストします。
+func TestGetTasks_Archived(t *testing.T) {
+	router, mockRepo := setupTestHandler(t)
+
+	// モックリポジトリの期待動作を設定
+	mockRepo.On("GetTasks", repository.TaskFilter{Archived: "only", Limit: 10}).
+		Return(&repository.TaskPage{Tasks: []model.Task{}}, nil)
+
+	// テストリクエストを作成（GET /tasks?archived=only）
+	req, err := http.NewRequest(http.MethodGet, "/tasks?archived=only", nil)
+	assert.NoError(t, err)
+
+	// リクエストをルーターに送信
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	mockRepo.AssertExpectations(t)
+}
+
+// TestArchiveTask はタスクをアーカイブできることをテストします。
+func TestArchiveTask(t *testing.T) {
+	router, mockRepo := setupTestHandler(t)
+
+	existingTask := &model.Task{ID: 1, Title: "完了したタスク", IsCompleted: true, Version: 3}
+
+	// モックリポジトリの期待動作を設定
+	mockRepo.On("GetTaskByID", uint(1)).Return(existingTask, nil)
+	mockRepo.On("SetTaskArchived", existingTask, true).Return(nil)
+
+	// テストリクエストを作成（POST /tasks/1/archive）
+	req, err := http.NewRequest(http.MethodPost, "/tasks/1/archive", nil)
+	assert.NoError(t, err)
+	req.Header.Set("If-Match", `"1-3"`)
+
+	// リクエストをルーターに送信
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	mockRepo.AssertExpectations(t)
+}
+
+// TestUnarchiveTask_NotArchived はアーカイブされていないタスクのアーカイブ解除では更新しないことをテストします。
+func TestUnarchiveTask_NotArchived(t *testing.T) {
+	router, mockRepo := setupTestHandler(t)
+
+	existingTask := &model.Task{ID: 1, Title: "タスク", Version: 1}
+
+	// モックリポジトリの期待動作を設定
+	mockRepo.On("GetTaskByID", uint(1)).Return(existingTask, nil)
+
+	// テストリクエストを作成（POST /tasks/1/unarchive）
+	req, err := http.NewRequest(http.MethodPost, "/tasks/1/unarchive", nil)
+	assert.NoError(t, err)
+
+	// リクエストをルーターに送信
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	mockRepo.AssertNotCalled(t, "SetTaskArchived", mock.Anything, mock.Anything)
+	mockRepo.AssertExpectations(t)
+}
+
+// TestArchiveCompletedTasks は完了済みのタスクを一括でアーカイブし、タスクごとに監査ログに記録することをテストします。
+func TestArchiveCompletedTasks(t *testing.T) {
+	router, mockRepo, auditRepo := setupAuditedTestHandler(t)
+
+	// 完了から30日以上経過したタスクが対象になることを確認
+	archivedAt := time.Now()
+	var changes []repository.TaskChange
+	for id := uint(1); id <= 5; id++ {
+		changes = append(changes, repository.TaskChange{
+			Before: &model.Task{ID: id, IsCompleted: true, Version: 1},
+			After:  &model.Task{ID: id, IsCompleted: true, ArchivedAt: &archivedAt, Version: 2},
+		})
+	}
+	mockRepo.On("ArchiveCompletedTasks", mock.MatchedBy(func(before time.Time) bool {
+		expected := time.Now().AddDate(0, 0, -30)
+		return before.Sub(expected).Abs() < time.Minute
+	})).Return(changes, nil)
+	auditRepo.On("CreateAuditLog", mock.MatchedBy(func(entry *model.AuditLog) bool {
+		_, archived := entry.Changes["archived_at"]
+		return entry.Action == model.AuditActionArchive && archived
+	})).Return(nil).Times(5)
+
+	// テストリクエストを作成（POST /tasks/archive）
+	body := []byte(`{"older_than_days": 30}`)
+	req, err := http.NewRequest(http.MethodPost, "/tasks/archive", bytes.NewBuffer(body))
+	assert.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+
+	// リクエストをルーターに送信
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// レスポンスボディを解析
+	var response map[string]interface{}
+	err = json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, float64(5), response["archived"])
+
+	mockRepo.AssertExpectations(t)
+	auditRepo.AssertExpectations(t)
+}
+
+// TestArchiveCompletedTasks_MissingDays は older_than_days が未指定の場合に 400 を返すことをテストします。
+func TestArchiveCompletedTasks_MissingDays(t *testing.T) {
+	router, mockRepo := setupTestHandler(t)
+
+	// テストリクエストを作成（POST /tasks/archive）
+	req, err := http.NewRequest(http.MethodPost, "/tasks/archive", bytes.NewBufferString(`{}`))
+	assert.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+
+	// リクエストをルーターに送信
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	mockRepo.AssertNotCalled(t, "ArchiveCompletedTasks", mock.Anything)
+}
+
+// TestGetTaskHistory は GetTaskHistory ハンドラーがタスクの完了履歴を返すことをテストします。
+func TestGetTaskHistory(t *testing.T) {
+	router, mockRepo := setupTestHandler(t)
+
+	completedAt := time.Date(2024, 12, 1, 9, 0, 0, 0, time.UTC)
+	existingTask := &model.Task{ID: 1, Title: "タスク", IsCompleted: true, CompletedAt: &completedAt}
+	events := []model.CompletionEvent{
+		{ID: 1, TaskID: 1, Action: model.CompletionActionCompleted, CreatedAt: completedAt.Add(-48 * time.Hour)},
+		{ID: 2, TaskID: 1, Action: model.CompletionActionReopened, CreatedAt: completedAt.Add(-24 * time.Hour)},
+		{ID: 3, TaskID: 1, Action: model.CompletionActionCompleted, CreatedAt: completedAt},
+	}
+
+	// モックリポジトリの期待動作を設定
+	mockRepo.On("GetTaskByID", uint(1)).Return(existingTask, nil)
+	mockRepo.On("GetCompletionHistory", uint(1)).Return(events, nil)
+
+	// テストリクエストを作成（GET /tasks/1/history）
+	req, err := http.NewRequest(http.MethodGet, "/tasks/1/history", nil)
+	assert.NoError(t, err)
+
+	// リクエストをルーターに送信
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// レスポンスボディを解析
+	var response struct {
+		Data []model.CompletionEvent `json:"data"`
+	}
+	err = json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, events, response.Data)
+
+	mockRepo.AssertExpectations(t)
+}
+
+// TestGetTaskHistory_NotFound は存在しないタスクの履歴を取得した場合に 404 を返すことをテストします。
+func TestGetTaskHistory_NotFound(t *testing.T) {
+	router, mockRepo := setupTestHandler(t)
+
+	// モックリポジトリの期待動作を設定
+	mockRepo.On("GetTaskByID", uint(99)).Return((*model.Task)(nil), gorm.ErrRecordNotFound)
+
+	// テストリクエストを作成（GET /tasks/99/history）
+	req, err := http.NewRequest(http.MethodGet, "/tasks/99/history", nil)
+	assert.NoError(t, err)
+
+	// リクエストをルーターに送信
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	mockRepo.AssertNotCalled(t, "GetCompletionHistory", mock.Anything)
+}
+
+// TestGetTaskRevisions はタスクのリビジョン一覧を返すことをテストします。
+func TestGetTaskRevisions(t *testing.T) {
+	router, mockRepo := setupTestHandler(t)
+
+	existingTask := &model.Task{ID: 1, Title: "現在のタイトル", Version: 3}
+	revisions := []model.TaskRevision{
+		{TaskID: 1, Revision: 3, Title: "現在のタイトル"},
+		{TaskID: 1, Revision: 1, Title: "最初のタイトル"},
+	}
+
+	// モックリポジトリの期待動作を設定
+	mockRepo.On("GetTaskByID", uint(1)).Return(existingTask, nil)
+	mockRepo.On("GetRevisions", uint(1), 10, 0).Return(revisions, int64(2), nil)
+
+	// テストリクエストを作成（GET /tasks/1/revisions）
+	req, err := http.NewRequest(http.MethodGet, "/tasks/1/revisions", nil)
+	assert.NoError(t, err)
+
+	// リクエストをルーターに送信
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// レスポンスボディを解析
+	var response map[string]interface{}
+	err = json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, float64(2), response["total"])
+
+	mockRepo.AssertExpectations(t)
+}
+
+// TestDiffTaskRevisions は2つのリビジョン間で変更されたフィールドのみを返すことをテストします。
+func TestDiffTaskRevisions(t *testing.T) {
+	router, mockRepo := setupTestHandler(t)
+
+	existingTask := &model.Task{ID: 1, Title: "新しいタイトル", Version: 3}
+	from := &model.TaskRevision{TaskID: 1, Revision: 1, Title: "古いタイトル", Description: "説明", Priority: model.PriorityLow, CreatedAt: time.Now().Add(-time.Hour)}
+	to := &model.TaskRevision{TaskID: 1, Revision: 3, Title: "新しいタイトル", Description: "説明", Priority: model.PriorityHigh, CreatedAt: time.Now()}
+
+	// モックリポジトリの期待動作を設定
+	mockRepo.On("GetTaskByID", uint(1)).Return(existingTask, nil)
+	mockRepo.On("GetRevision", uint(1), uint(1)).Return(from, nil)
+	mockRepo.On("GetRevision", uint(1), uint(3)).Return(to, nil)
+
+	// テストリクエストを作成（GET /tasks/1/revisions/diff?from=1&to=3）
+	req, err := http.NewRequest(http.MethodGet, "/tasks/1/revisions/diff?from=1&to=3", nil)
+	assert.NoError(t, err)
+
+	// リクエストをルーターに送信
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// レスポンスボディを解析
+	var response struct {
+		Data struct {
+			From    uint                         `json:"from"`
+			To      uint                         `json:"to"`
+			Changes map[string]model.FieldChange `json:"changes"`
+		} `json:"data"`
+	}
+	err = json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, uint(1), response.Data.From)
+	assert.Equal(t, uint(3), response.Data.To)
+	assert.Equal(t, map[string]model.FieldChange{
+		"title":    {Before: "古いタイトル", After: "新しいタイトル"},
+		"priority": {Before: "low", After: "high"},
+	}, response.Data.Changes)
+
+	mockRepo.AssertExpectations(t)
+}
+
+// TestRevertTask は指定したリビジョンの内容でタスクを更新することをテストします。
+func TestRevertTask(t *testing.T) {
+	router, mockRepo := setupTestHandler(t)
+
+	existingTask := &model.Task{ID: 1, Title: "新しいタイトル", Description: "新しい説明", IsCompleted: true, Version: 3}
+	revision := &model.TaskRevision{TaskID: 1, Revision: 1, Title: "古いタイトル", Description: "古い説明", Priority: model.PriorityNone}
+
+	// モックリポジトリの期待動作を設定
+	mockRepo.On("GetTaskByID", uint(1)).Return(existingTask, nil)
+	mockRepo.On("GetRevision", uint(1), uint(1)).Return(revision, nil)
+	mockRepo.On("UpdateTask", mock.MatchedBy(func(task *model.Task) bool {
+		// 完了状態はリビジョンの対象外のため変更しない
+		return task.Title == "古いタイトル" && task.Description == "古い説明" && task.IsCompleted
+	})).Return(nil)
+
+	// テストリクエストを作成（POST /tasks/1/revert）
+	req, err := http.NewRequest(http.MethodPost, "/tasks/1/revert", bytes.NewBufferString(`{"revision": 1}`))
+	assert.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+
+	// リクエストをルーターに送信
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	mockRepo.AssertExpectations(t)
+}
+
+// TestRevertTask_RevisionNotFound は存在しないリビジョンを指定した場合に 404 を返すことをテストします。
+func TestRevertTask_RevisionNotFound(t *testing.T) {
+	router, mockRepo := setupTestHandler(t)
+
+	existingTask := &model.Task{ID: 1, Title: "タスク", Version: 3}
+
+	// モックリポジトリの期待動作を設定
+	mockRepo.On("GetTaskByID", uint(1)).Return(existingTask, nil)
+	mockRepo.On("GetRevision", uint(1), uint(7)).Return((*model.TaskRevision)(nil), gorm.ErrRecordNotFound)
+
+	// テストリクエストを作成（POST /tasks/1/revert）
+	req, err := http.NewRequest(http.MethodPost, "/tasks/1/revert", bytes.NewBufferString(`{"revision": 7}`))
+	assert.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+
+	// リクエストをルーターに送信
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Contains(t, w.Body.String(), "Revision not found")
+
+	mockRepo.AssertNotCalled(t, "UpdateTask", mock.Anything)
+}
+
+// TestUpdateTask_IssuesUndoToken はタスクの更新時に変更前の状態を保持した取り消しトークンを返すことをテストします。
+func TestUpdateTask_IssuesUndoToken(t *testing.T) {
+	router, mockRepo := setupTestHandler(t)
+
+	existingTask := &model.Task{ID: 1, Title: "元のタスク", Version: 1}
+
+	// モックリポジトリの期待動作を設定
+	mockRepo.On("GetTaskByID", uint(1)).Return(existingTask, nil)
+	mockRepo.On("UpdateTask", mock.AnythingOfType("*model.Task")).Return(nil).Run(func(args mock.Arguments) {
+		args.Get(0).(*model.Task).Version++
+	})
+
+	// テストリクエストを作成（PUT /tasks/1）
+	req, err := http.NewRequest(http.MethodPut, "/tasks/1", bytes.NewBufferString(`{"title": "更新されたタスク"}`))
+	assert.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+
+	// リクエストをルーターに送信
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// レスポンスボディを解析
+	var response struct {
+		Undo struct {
+			Token     string    `json:"token"`
+			ExpiresAt time.Time `json:"expires_at"`
+		} `json:"undo"`
+	}
+	err = json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, response.Undo.Token)
+	assert.WithinDuration(t, time.Now().Add(undoTokenTTL), response.Undo.ExpiresAt, 5*time.Second)
+
+	// トークンはハッシュのみを保存し、操作後のバージョンと操作前の状態を保持する
+	mockRepo.AssertCalled(t, "CreateUndoToken", mock.MatchedBy(func(token *model.UndoToken) bool {
+		return token.TokenHash == hashUndoToken(response.Undo.Token) &&
+			token.Action == model.UndoActionUpdate && token.TaskID == 1 && token.Version == 2 &&
+			token.Snapshot.Title == "元のタスク"
+	}))
+	mockRepo.AssertExpectations(t)
+}
+
+// TestDeleteTask_IssuesUndoToken は親を付け替えた子タスクとして、リポジトリが付け替えた子タスクを取り消しトークンに記録することをテストします。
+func TestDeleteTask_IssuesUndoToken(t *testing.T) {
+	router, mockRepo := setupTestHandler(t)
+
+	task := &model.Task{ID: 1, Title: "削除するタスク", Version: 1}
+
+	// モックリポジトリの期待動作を設定（子タスク 3 はユーザーが参照できない）
+	mockRepo.On("GetTaskByID", uint(1)).Return(task, nil)
+	mockRepo.On("DeleteTask", task).Return([]uint{2, 3}, nil)
+
+	// テストリクエストを作成（DELETE /tasks/1）
+	req, err := http.NewRequest(http.MethodDelete, "/tasks/1", nil)
+	assert.NoError(t, err)
+
+	// リクエストをルーターに送信
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	mockRepo.AssertCalled(t, "CreateUndoToken", mock.MatchedBy(func(token *model.UndoToken) bool {
+		return token.Action == model.UndoActionDelete && token.TaskID == 1 && assert.ObjectsAreEqual([]uint{2, 3}, token.ChildIDs)
+	}))
+	mockRepo.AssertNotCalled(t, "GetChildren", mock.Anything)
+}
+
+// TestUndo_Update は取り消しトークンで更新前の内容にタスクを戻すことをテストします。
+func TestUndo_Update(t *testing.T) {
+	router, mockRepo := setupTestHandler(t)
+
+	token := &model.UndoToken{
+		Action:    model.UndoActionUpdate,
+		TaskID:    1,
+		Version:   2,
+		Snapshot:  &model.Task{ID: 1, Title: "元のタスク", Priority: model.PriorityNone},
+		ExpiresAt: time.Now().Add(time.Minute),
+	}
+	existingTask := &model.Task{ID: 1, Title: "更新されたタスク", Priority: model.PriorityHigh, Version: 2}
+
+	// モックリポジトリの期待動作を設定
+	mockRepo.On("GetUndoToken", hashUndoToken("abc")).Return(token, nil)
+	mockRepo.On("Transaction").Return()
+	mockRepo.On("UseUndoToken", token).Return(nil)
+	mockRepo.On("GetTaskByID", uint(1)).Return(existingTask, nil)
+	mockRepo.On("UpdateTask", mock.MatchedBy(func(task *model.Task) bool {
+		return task.Title == "元のタスク" && task.Priority == model.PriorityNone
+	})).Return(nil)
+
+	// テストリクエストを作成（POST /undo/abc）
+	req, err := http.NewRequest(http.MethodPost, "/undo/abc", nil)
+	assert.NoError(t, err)
+
+	// リクエストをルーターに送信
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	mockRepo.AssertExpectations(t)
+}
+
+// TestUndo_Delete は取り消しトークンで削除したタスクを復元し、付け替えた子タスク（参照できないものを含む）を元に戻すことをテストします。
+func TestUndo_Delete(t *testing.T) {
+	router, mockRepo := setupTestHandler(t)
+
+	token := &model.UndoToken{
+		Action:    model.UndoActionDelete,
+		TaskID:    1,
+		Version:   1,
+		Snapshot:  &model.Task{ID: 1, Title: "削除したタスク"},
+		ChildIDs:  []uint{2, 3},
+		ExpiresAt: time.Now().Add(time.Minute),
+	}
+	deletedTask := &model.Task{ID: 1, Title: "削除したタスク", Version: 1}
+
+	// モックリポジトリの期待動作を設定
+	mockRepo.On("GetUndoToken", hashUndoToken("abc")).Return(token, nil)
+	mockRepo.On("Transaction").Return()
+	mockRepo.On("UseUndoToken", token).Return(nil)
+	mockRepo.On("GetDeletedTaskByID", uint(1)).Return(deletedTask, nil)
+	mockRepo.On("RestoreTask", deletedTask).Return(nil)
+	// 削除前の親（なし）のままの子タスクのみを戻すのはリポジトリが行う
+	mockRepo.On("ReattachChildren", deletedTask, []uint{2, 3}, (*uint)(nil)).Return(nil)
+
+	// テストリクエストを作成（POST /undo/abc）
+	req, err := http.NewRequest(http.MethodPost, "/undo/abc", nil)
+	assert.NoError(t, err)
+
+	// リクエストをルーターに送信
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	mockRepo.AssertExpectations(t)
+	mockRepo.AssertNotCalled(t, "GetTaskByID", mock.Anything)
+}
+
+// TestUndo_TaskChanged は操作の後にタスクが変更されていた場合に 409 を返すことをテストします。
+func TestUndo_TaskChanged(t *testing.T) {
+	router, mockRepo := setupTestHandler(t)
+
+	token := &model.UndoToken{
+		Action:    model.UndoActionToggle,
+		TaskID:    1,
+		Version:   2,
+		Snapshot:  &model.Task{ID: 1},
+		ExpiresAt: time.Now().Add(time.Minute),
+	}
+
+	// モックリポジトリの期待動作を設定
+	mockRepo.On("GetUndoToken", hashUndoToken("abc")).Return(token, nil)
+	mockRepo.On("Transaction").Return()
+	mockRepo.On("UseUndoToken", token).Return(nil)
+	mockRepo.On("GetTaskByID", uint(1)).Return(&model.Task{ID: 1, IsCompleted: true, Version: 3}, nil)
+
+	// テストリクエストを作成（POST /undo/abc）
+	req, err := http.NewRequest(http.MethodPost, "/undo/abc", nil)
+	assert.NoError(t, err)
+
+	// リクエストをルーターに送信
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	mockRepo.AssertNotCalled(t, "ToggleTaskCompletion", mock.Anything)
+}
+
+// TestToggleTask_RecurringIssuesUndoToken は繰り返しタスクを完了にした際に、生成した次回のタスクを取り消しトークンに記録することをテストします。
+func TestToggleTask_RecurringIssuesUndoToken(t *testing.T) {
+	router, mockRepo := setupTestHandler(t)
+
+	existingTask := &model.Task{ID: 1, Title: "繰り返しタスク", RRule: "FREQ=DAILY", DueDate: time.Now(), Version: 1}
+
+	// モックリポジトリの期待動作を設定
+	mockRepo.On("GetTaskByID", uint(1)).Return(existingTask, nil)
+	mockRepo.On("ToggleTaskCompletion", existingTask).Return(nil).Run(func(args mock.Arguments) {
+		task := args.Get(0).(*model.Task)
+		task.IsCompleted = true
+		task.Version++
+		task.NextOccurrence = &model.Task{ID: 5, RecurrenceOfID: &task.ID}
+	})
+
+	// テストリクエストを作成（PATCH /tasks/1/toggle）
+	req, err := http.NewRequest(http.MethodPatch, "/tasks/1/toggle", nil)
+	assert.NoError(t, err)
+
+	// リクエストをルーターに送信
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	mockRepo.AssertCalled(t, "CreateUndoToken", mock.MatchedBy(func(token *model.UndoToken) bool {
+		return token.Action == model.UndoActionToggle && token.TaskID == 1 && token.Version == 2 &&
+			token.NextOccurrenceID != nil && *token.NextOccurrenceID == 5
+	}))
+}
+
+// TestUpdateTask_RecurringIssuesUndoToken は更新で繰り返しタスクを完了にした際に、生成した次回のタスクを取り消しトークンに記録することをテストします。
+func TestUpdateTask_RecurringIssuesUndoToken(t *testing.T) {
+	router, mockRepo := setupTestHandler(t)
+
+	existingTask := &model.Task{ID: 1, Title: "繰り返しタスク", RRule: "FREQ=DAILY", DueDate: time.Now(), Version: 1}
+
+	// モックリポジトリの期待動作を設定（完了にした場合はリポジトリが次回のタスクを生成する）
+	mockRepo.On("GetTaskByID", uint(1)).Return(existingTask, nil)
+	mockRepo.On("UpdateTask", mock.MatchedBy(func(task *model.Task) bool { return task.IsCompleted })).Return(nil).Run(func(args mock.Arguments) {
+		task := args.Get(0).(*model.Task)
+		task.Version++
+		task.NextOccurrence = &model.Task{ID: 5, RecurrenceOfID: &task.ID}
+	})
+
+	// テストリクエストを作成（PUT /tasks/1）
+	req, err := http.NewRequest(http.MethodPut, "/tasks/1", bytes.NewBufferString(`{"title": "繰り返しタスク", "is_completed": true}`))
+	assert.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+
+	// リクエストをルーターに送信
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	mockRepo.AssertCalled(t, "CreateUndoToken", mock.MatchedBy(func(token *model.UndoToken) bool {
+		return token.Action == model.UndoActionUpdate && token.TaskID == 1 &&
+			token.NextOccurrenceID != nil && *token.NextOccurrenceID == 5
+	}))
+}
+
+// TestUndo_UpdateRecurring は更新による繰り返しタスクの完了を取り消した際に、生成した次回のタスクを削除することをテストします。
+func TestUndo_UpdateRecurring(t *testing.T) {
+	router, mockRepo := setupTestHandler(t)
+
+	nextID := uint(5)
+	token := &model.UndoToken{
+		Action:           model.UndoActionUpdate,
+		TaskID:           1,
+		Version:          2,
+		Snapshot:         &model.Task{ID: 1, Title: "繰り返しタスク", RRule: "FREQ=DAILY"},
+		ExpiresAt:        time.Now().Add(time.Minute),
+		NextOccurrenceID: &nextID,
+	}
+	existingTask := &model.Task{ID: 1, Title: "繰り返しタスク", RRule: "FREQ=DAILY", IsCompleted: true, Version: 2}
+
+	// モックリポジトリの期待動作を設定
+	mockRepo.On("GetUndoToken", hashUndoToken("abc")).Return(token, nil)
+	mockRepo.On("Transaction").Return()
+	mockRepo.On("UseUndoToken", token).Return(nil)
+	mockRepo.On("GetTaskByID", uint(1)).Return(existingTask, nil)
+	mockRepo.On("UpdateTask", mock.MatchedBy(func(task *model.Task) bool { return !task.IsCompleted })).Return(nil)
+	mockRepo.On("PurgeOccurrence", nextID).Return(nil)
+
+	// テストリクエストを作成（POST /undo/abc）
+	req, err := http.NewRequest(http.MethodPost, "/undo/abc", nil)
+	assert.NoError(t, err)
+
+	// リクエストをルーターに送信
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	mockRepo.AssertExpectations(t)
+}
+
+// TestUndo_ToggleRecurring は繰り返しタスクの完了を取り消した際に、生成した次回のタスクを削除することをテストします。
+func TestUndo_ToggleRecurring(t *testing.T) {
+	nextID := uint(5)
+	tests := []struct {
+		name     string
+		purgeErr error
+		expected int
+	}{
+		{"次回のタスクを削除", nil, http.StatusOK},
+		{"次回のタスクが変更されていた", repository.ErrVersionConflict, http.StatusConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router, mockRepo := setupTestHandler(t)
+
+			token := &model.UndoToken{
+				Action:           model.UndoActionToggle,
+				TaskID:           1,
+				Version:          2,
+				Snapshot:         &model.Task{ID: 1},
+				ExpiresAt:        time.Now().Add(time.Minute),
+				NextOccurrenceID: &nextID,
+			}
+			existingTask := &model.Task{ID: 1, RRule: "FREQ=DAILY", IsCompleted: true, Version: 2}
+
+			// モックリポジトリの期待動作を設定
+			mockRepo.On("GetUndoToken", hashUndoToken("abc")).Return(token, nil)
+			mockRepo.On("Transaction").Return()
+			mockRepo.On("UseUndoToken", token).Return(nil)
+			mockRepo.On("GetTaskByID", uint(1)).Return(existingTask, nil)
+			mockRepo.On("ToggleTaskCompletion", existingTask).Return(nil)
+			mockRepo.On("PurgeOccurrence", nextID).Return(tt.purgeErr)
+
+			// テストリクエストを作成（POST /undo/abc）
+			req, err := http.NewRequest(http.MethodPost, "/undo/abc", nil)
+			assert.NoError(t, err)
+
+			// リクエストをルーターに送信
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expected, w.Code)
+
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+// TestUndo_Gone は使用済み・期限切れのトークンに 410 を、存在しないトークンに 404 を返すことをテストします。
+func TestUndo_Gone(t *testing.T) {
+	router, mockRepo := setupTestHandler(t)
+
+	usedAt := time.Now()
+
+	// モックリポジトリの期待動作を設定
+	mockRepo.On("GetUndoToken", hashUndoToken("used")).Return(&model.UndoToken{UsedAt: &usedAt, ExpiresAt: time.Now().Add(time.Minute)}, nil)
+	mockRepo.On("GetUndoToken", hashUndoToken("expired")).Return(&model.UndoToken{ExpiresAt: time.Now().Add(-time.Second)}, nil)
+	mockRepo.On("GetUndoToken", hashUndoToken("unknown")).Return((*model.UndoToken)(nil), gorm.ErrRecordNotFound)
+
+	tests := []struct {
+		token    string
+		expected int
+	}{
+		{"used", http.StatusGone},
+		{"expired", http.StatusGone},
+		{"unknown", http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		req, err := http.NewRequest(http.MethodPost, "/undo/"+tt.token, nil)
+		assert.NoError(t, err)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, tt.expected, w.Code, tt.token)
+	}
+
+	mockRepo.AssertNotCalled(t, "UseUndoToken", mock.Anything)
+}
+
+// TestGetTasks_ScopedToUser はタスクの一覧を認証済みのユーザーが所有するタスクに絞り込むことをテストします。
+func TestGetTasks_ScopedToUser(t *testing.T) {
+	router, mockRepo := setupTestHandler(t)
+
+	// モックリポジトリの期待動作を設定
+	mockRepo.On("GetTasks", mock.AnythingOfType("repository.TaskFilter")).Return(&repository.TaskPage{}, nil)
+
+	// テストリクエストを作成（GET /tasks）
+	req, err := http.NewRequest(http.MethodGet, "/tasks", nil)
+	assert.NoError(t, err)
+
+	// リクエストをルーターに送信
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	mockRepo.AssertCalled(t, "ForUser", testUserID)
+}
+
+// TestGetTasks_ScopedToTenant はタスクの一覧をリクエストのワークスペースのタスクに絞り込むことをテストします。
+func TestGetTasks_ScopedToTenant(t *testing.T) {
+	router, mockRepo, auditRepo, memberRepo := setupMemberTestHandler(t)
+
+	// モックリポジトリの期待動作を設定
+	mockRepo.On("GetTasks", mock.AnythingOfType("repository.TaskFilter")).Return(&repository.TaskPage{}, nil)
+
+	// テストリクエストを作成（GET /tasks）
+	req, err := http.NewRequest(http.MethodGet, "/tasks", nil)
+	assert.NoError(t, err)
+
+	// リクエストをルーターに送信
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	mockRepo.AssertCalled(t, "ForTenant", testTenantID)
+	auditRepo.AssertNotCalled(t, "ForTenant", mock.Anything)
+	memberRepo.AssertNotCalled(t, "ForTenant", mock.Anything)
+}
+
+// TestDeleteTask_ScopedToTenant はタスクの権限の確認と監査ログの記録をリクエストのワークスペースで行うことをテストします。
+func TestDeleteTask_ScopedToTenant(t *testing.T) {
+	router, mockRepo, auditRepo := setupAuditedTestHandler(t)
+
+	// テストデータの準備
+	task := &model.Task{ID: 1, Title: "削除するタスク"}
+
+	// モックリポジトリの期待動作を設定
+	mockRepo.On("GetTaskByID", uint(1)).Return(task, nil)
+	mockRepo.On("Transaction").Return(nil)
+	mockRepo.On("DeleteTask", task).Return([]uint(nil), nil)
+	auditRepo.On("CreateAuditLog", mock.Anything).Return(nil)
+
+	// テストリクエストを作成（DELETE /tasks/1）
+	req, err := http.NewRequest(http.MethodDelete, "/tasks/1", nil)
+	assert.NoError(t, err)
+
+	// リクエストをルーターに送信
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	mockRepo.AssertCalled(t, "ForTenant", testTenantID)
+	auditRepo.AssertCalled(t, "ForTenant", testTenantID)
+}
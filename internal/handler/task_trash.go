@@ -0,0 +1,133 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ryory2/test-go-app-todo-go/internal/model"
+	"github.com/ryory2/test-go-app-todo-go/internal/policy"
+)
+
+// GetTrashハンドラー
+// HTTP: GET /tasks/trash
+func (h *TaskHandler) GetTrash(c *gin.Context) {
+	// クエリパラメータを整数に変換
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if err != nil || limit <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit parameter"})
+		return
+	}
+
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid offset parameter"})
+		return
+	}
+
+	// ゴミ箱のタスクを削除日時の新しい順に取得
+	tasks, total, err := h.tasks(c).GetDeletedTasks(limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve tasks"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":  tasks,
+		"total": total,
+	})
+}
+
+// RestoreTaskハンドラー
+// HTTP: POST /tasks/{id}/restore
+//
+// 子孫タスクごと削除（children=cascade）されたタスクは、同時に削除された子孫タスクもあわせて復元する
+func (h *TaskHandler) RestoreTask(c *gin.Context) {
+	task, ok := h.findDeletedTask(c)
+	if !ok {
+		return
+	}
+
+	// ロールの権限を確認
+	if !h.authorize(c, task.ID, policy.ActionDelete) {
+		return
+	}
+
+	before := *task // 監査ログ用に変更前の状態を保持
+
+	// タスクを復元
+	if err := h.tasks(c).RestoreTask(task); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to restore task"})
+		return
+	}
+
+	// 監査ログに記録
+	h.recordTaskAudit(c, model.AuditActionRestore, &before, task)
+
+	respondTask(c, http.StatusOK, task)
+}
+
+// PurgeTaskハンドラー
+// HTTP: DELETE /tasks/trash/{id}
+func (h *TaskHandler) PurgeTask(c *gin.Context) {
+	task, ok := h.findDeletedTask(c)
+	if !ok {
+		return
+	}
+
+	// ロールの権限を確認
+	if !h.authorize(c, task.ID, policy.ActionDelete) {
+		return
+	}
+
+	// ゴミ箱のタスクを完全に削除
+	if err := h.tasks(c).PurgeTask(task); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to purge task"})
+		return
+	}
+
+	// 監査ログに記録
+	h.recordTaskAudit(c, model.AuditActionPurge, task, nil)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Task purged successfully"})
+}
+
+// EmptyTrashハンドラー
+// HTTP: DELETE /tasks/trash
+func (h *TaskHandler) EmptyTrash(c *gin.Context) {
+	// ゴミ箱のタスクをすべて完全に削除
+	purged, err := h.tasks(c).PurgeDeletedTasks(time.Now())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to empty trash"})
+		return
+	}
+
+	// 削除したタスクごとに監査ログに記録
+	for i := range purged {
+		h.recordTaskAudit(c, model.AuditActionPurge, &purged[i], nil)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Trash emptied successfully",
+		"purged":  len(purged),
+	})
+}
+
+// findDeletedTask はURLパラメータのIDからゴミ箱のタスクを取得します。失敗時はエラーレスポンスを書き込み false を返します
+func (h *TaskHandler) findDeletedTask(c *gin.Context) (*model.Task, bool) {
+	// URLパラメータからIDを取得
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid task ID"})
+		return nil, false
+	}
+
+	// ゴミ箱のタスクを取得
+	task, err := h.tasks(c).GetDeletedTaskByID(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found in trash"})
+		return nil, false
+	}
+	return task, true
+}
@@ -0,0 +1,190 @@
+package handler
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ryory2/test-go-app-todo-go/internal/model"
+	"github.com/ryory2/test-go-app-todo-go/internal/policy"
+	"github.com/ryory2/test-go-app-todo-go/internal/repository"
+)
+
+// undoTokenTTL は取り消しトークンの有効期間です
+const undoTokenTTL = time.Minute
+
+// errTaskChanged は取り消し対象の操作の後にタスクが変更されていた場合のエラーです
+var errTaskChanged = newRequestError(http.StatusConflict, "Task has changed since the operation; it cannot be undone")
+
+// Undoハンドラー
+// HTTP: POST /undo/{token}
+//
+// 削除・完了状態の切り替え・更新のレスポンスで返した取り消しトークンの操作を取り消す。
+// 操作の後にタスクが変更されていた場合は 409 Conflict を返す
+func (h *TaskHandler) Undo(c *gin.Context) {
+	// トークンを取得
+	token, err := h.tasks(c).GetUndoToken(hashUndoToken(c.Param("token")))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Undo token not found"})
+		return
+	}
+	if token.UsedAt != nil {
+		c.JSON(http.StatusGone, gin.H{"error": "Undo token has already been used"})
+		return
+	}
+	if token.IsExpired(time.Now()) {
+		c.JSON(http.StatusGone, gin.H{"error": "Undo token has expired"})
+		return
+	}
+
+	// ロールの権限を確認（削除の取り消しは削除と同じ権限が必要）
+	action := policy.ActionEdit
+	if token.Action == model.UndoActionDelete {
+		action = policy.ActionDelete
+	}
+	if !h.authorize(c, token.TaskID, action) {
+		return
+	}
+
+	var before, task *model.Task
+	err = h.tasks(c).Transaction(func(tx repository.TaskRepository) error {
+		// 同時に同じトークンが使われた場合は1回のみ取り消す
+		if err := tx.UseUndoToken(token); err != nil {
+			if errors.Is(err, repository.ErrUndoTokenUsed) {
+				return newRequestError(http.StatusGone, "Undo token has already been used")
+			}
+			return err
+		}
+		var err error
+		before, task, err = undoOperation(tx, token)
+		return err
+	})
+	if err != nil {
+		respondError(c, err, "Failed to undo operation")
+		return
+	}
+
+	// 監査ログに記録
+	h.recordTaskAudit(c, model.AuditActionUndo, before, task)
+
+	respondTask(c, http.StatusOK, task)
+}
+
+// undoOperation はトークンの操作を取り消し、取り消し前と取り消し後のタスクを返します
+func undoOperation(tx repository.TaskRepository, token *model.UndoToken) (*model.Task, *model.Task, error) {
+	snapshot := token.Snapshot
+
+	if token.Action == model.UndoActionDelete {
+		// ゴミ箱から復元する（復元・完全削除済みの場合は取り消せない）
+		task, err := tx.GetDeletedTaskByID(token.TaskID)
+		if err != nil || task.Version != token.Version {
+			return nil, nil, errTaskChanged
+		}
+		before := *task
+		if err := tx.RestoreTask(task); err != nil {
+			return nil, nil, err
+		}
+		// 削除時に付け替えた子タスクを元に戻す（その後に移動された子タスクはそのままにする）
+		if err := tx.ReattachChildren(task, token.ChildIDs, snapshot.ParentID); err != nil {
+			return nil, nil, err
+		}
+		return &before, task, nil
+	}
+
+	task, err := tx.GetTaskByID(token.TaskID)
+	if err != nil || task.Version != token.Version {
+		return nil, nil, errTaskChanged
+	}
+	before := *task
+
+	switch token.Action {
+	case model.UndoActionToggle:
+		if err := tx.ToggleTaskCompletion(task); err != nil {
+			return nil, nil, err
+		}
+		// 一緒に完了にした子孫タスクを未完了に戻す
+		for _, id := range token.ChildIDs {
+			child, err := tx.GetTaskByID(id)
+			if err != nil || !child.IsCompleted {
+				continue
+			}
+			if err := tx.ToggleTaskCompletion(child); err != nil {
+				return nil, nil, err
+			}
+		}
+	case model.UndoActionUpdate:
+		task.Title = snapshot.Title
+		task.Description = snapshot.Description
+		task.DueDate = snapshot.DueDate
+		task.IsCompleted = snapshot.IsCompleted
+		task.Priority = snapshot.Priority
+		task.RRule = snapshot.RRule
+		task.UpdatedAt = time.Now()
+		if err := tx.UpdateTask(task); err != nil {
+			return nil, nil, err
+		}
+	default:
+		return nil, nil, errors.New("unknown undo action: " + token.Action)
+	}
+	// 完了にした際に生成した次回のタスクを削除する（残すと以降の完了で次回のタスクが生成されない）
+	if token.NextOccurrenceID != nil {
+		if err := tx.PurgeOccurrence(*token.NextOccurrenceID); err != nil {
+			if errors.Is(err, repository.ErrVersionConflict) {
+				return nil, nil, errTaskChanged
+			}
+			return nil, nil, err
+		}
+	}
+	return &before, task, nil
+}
+
+// issueUndoToken は操作を取り消すためのトークンを発行し、レスポンスに含める値を返します。
+// before は操作前、after は操作後のタスクです。発行に失敗した場合は操作を取り消さず、nil を返します
+func (h *TaskHandler) issueUndoToken(c *gin.Context, action string, before, after *model.Task, childIDs []uint) gin.H {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		log.Printf("Failed to generate undo token: %v", err)
+		return nil
+	}
+	value := base64.RawURLEncoding.EncodeToString(b)
+
+	token := &model.UndoToken{
+		TokenHash: hashUndoToken(value),
+		Action:    action,
+		TaskID:    after.ID,
+		Version:   after.Version,
+		Snapshot:  before,
+		ChildIDs:  childIDs,
+		ExpiresAt: time.Now().Add(undoTokenTTL),
+	}
+	if after.NextOccurrence != nil {
+		token.NextOccurrenceID = &after.NextOccurrence.ID
+	}
+	if err := h.tasks(c).CreateUndoToken(token); err != nil {
+		log.Printf("Failed to create undo token (action=%s task=%d): %v", action, after.ID, err)
+		return nil
+	}
+	return gin.H{"token": value, "expires_at": token.ExpiresAt}
+}
+
+// respondTaskWithUndo は ETag ヘッダーを付けて単一のタスクと取り消しトークンを返します
+func respondTaskWithUndo(c *gin.Context, task *model.Task, undo gin.H) {
+	c.Header("ETag", taskETag(task))
+	body := gin.H{"data": task}
+	if undo != nil {
+		body["undo"] = undo
+	}
+	c.JSON(http.StatusOK, body)
+}
+
+// hashUndoToken は保存・検索に使う取り消しトークンのハッシュを返します
+func hashUndoToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,212 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/ryory2/test-go-app-todo-go/internal/middleware"
+	"github.com/ryory2/test-go-app-todo-go/internal/model"
+	"github.com/ryory2/test-go-app-todo-go/internal/repository"
+	"gorm.io/gorm"
+)
+
+// workspaceMemberInput はワークスペースのメンバーを追加・変更するリクエストボディです
+type workspaceMemberInput struct {
+	Role string `json:"role" validate:"required,oneof=admin member"`
+}
+
+// WorkspaceHandler構造体
+type WorkspaceHandler struct {
+	repo     repository.WorkspaceRepository
+	validate *validator.Validate
+}
+
+// NewWorkspaceHandler関数
+func NewWorkspaceHandler(repo repository.WorkspaceRepository, validate *validator.Validate) *WorkspaceHandler {
+	return &WorkspaceHandler{
+		repo:     repo,
+		validate: validate,
+	}
+}
+
+// GetWorkspacesハンドラー
+// HTTP: GET /workspaces
+//
+// 認証済みのユーザーがメンバーになっているワークスペースを返す
+func (h *WorkspaceHandler) GetWorkspaces(c *gin.Context) {
+	workspaces, err := h.repo.GetWorkspaces(currentUserID(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve workspaces"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": workspaces})
+}
+
+// CreateWorkspaceハンドラー
+// HTTP: POST /workspaces
+//
+// ワークスペースを作成し、作成したユーザーをその管理者にする
+func (h *WorkspaceHandler) CreateWorkspace(c *gin.Context) {
+	var input struct {
+		Slug string `json:"slug" validate:"required,min=2,max=63,hostname_rfc1123,excludes=."`
+		Name string `json:"name" validate:"required,max=100"`
+	}
+
+	// リクエストボディをバインド
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON provided"})
+		return
+	}
+
+	// 入力値のバリデーション（スラッグはサブドメインとして使えるものに限る）
+	input.Slug = strings.ToLower(strings.TrimSpace(input.Slug))
+	if err := h.validate.Struct(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// ワークスペースを作成
+	workspace := model.Workspace{Slug: input.Slug, Name: input.Name}
+	if err := h.repo.CreateWorkspace(&workspace, currentUserID(c)); err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Workspace slug is already taken"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create workspace"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"data": workspace})
+}
+
+// GetWorkspaceハンドラー
+// HTTP: GET /workspace
+//
+// リクエストのワークスペースを返す
+func (h *WorkspaceHandler) GetWorkspace(c *gin.Context) {
+	workspace, ok := middleware.GetWorkspace(c)
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Workspace not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": workspace})
+}
+
+// UpdateWorkspaceハンドラー
+// HTTP: PUT /workspace
+//
+// リクエストのワークスペースの名前を変更する（スラッグは変更できない）
+func (h *WorkspaceHandler) UpdateWorkspace(c *gin.Context) {
+	workspace, ok := middleware.GetWorkspace(c)
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Workspace not found"})
+		return
+	}
+
+	var input struct {
+		Name string `json:"name" validate:"required,max=100"`
+	}
+
+	// リクエストボディをバインド
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON provided"})
+		return
+	}
+
+	// 入力値のバリデーション
+	if err := h.validate.Struct(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// ワークスペースを更新
+	updated := *workspace
+	updated.Name = input.Name
+	if err := h.repo.UpdateWorkspace(&updated); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update workspace"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": updated})
+}
+
+// GetWorkspaceMembersハンドラー
+// HTTP: GET /workspace/members
+func (h *WorkspaceHandler) GetWorkspaceMembers(c *gin.Context) {
+	members, err := h.repo.GetWorkspaceMembers(currentTenantID(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve members"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": members})
+}
+
+// SetWorkspaceMemberハンドラー
+// HTTP: PUT /workspace/members/{user_id}
+//
+// ユーザーをワークスペースに追加する（追加済みの場合はロールを変更する）
+func (h *WorkspaceHandler) SetWorkspaceMember(c *gin.Context) {
+	userID, ok := parseMemberUserID(c)
+	if !ok {
+		return
+	}
+
+	var input workspaceMemberInput
+
+	// リクエストボディをバインド
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON provided"})
+		return
+	}
+
+	// 入力値のバリデーション
+	if err := h.validate.Struct(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// メンバーを追加・変更
+	member := model.WorkspaceMember{WorkspaceID: currentTenantID(c), UserID: userID, Role: input.Role}
+	if err := h.repo.SetWorkspaceMember(&member); err != nil {
+		respondWorkspaceMemberError(c, err, "Failed to update member")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": member})
+}
+
+// RemoveWorkspaceMemberハンドラー
+// HTTP: DELETE /workspace/members/{user_id}
+func (h *WorkspaceHandler) RemoveWorkspaceMember(c *gin.Context) {
+	userID, ok := parseMemberUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.repo.RemoveWorkspaceMember(currentTenantID(c), userID); err != nil {
+		respondWorkspaceMemberError(c, err, "Failed to remove member")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Member removed successfully"})
+}
+
+// respondWorkspaceMemberError はワークスペースのメンバーの変更に失敗した場合のエラーレスポンスを書き込みます
+func respondWorkspaceMemberError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Member not found"})
+	case errors.Is(err, gorm.ErrForeignKeyViolated):
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+	case errors.Is(err, repository.ErrLastAdmin):
+		c.JSON(http.StatusConflict, gin.H{"error": "Workspace must have at least one admin"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
@@ -0,0 +1,165 @@
+// internal/handler/workspace_test.go
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/ryory2/test-go-app-todo-go/internal/model"
+	"github.com/ryory2/test-go-app-todo-go/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+// setupWorkspaceTestHandler はテスト用の Gin エンジンとモックワークスペースリポジトリをセットアップします。
+func setupWorkspaceTestHandler(t *testing.T) (*gin.Engine, *repository.MockWorkspaceRepository) {
+	gin.SetMode(gin.TestMode)
+	mockRepo := new(repository.MockWorkspaceRepository)
+	validate := validator.New()
+	handler := NewWorkspaceHandler(mockRepo, validate)
+	router := gin.Default()
+	router.Use(authenticateAs(testUserID))
+
+	// エンドポイントの登録
+	router.GET("/workspaces", handler.GetWorkspaces)
+	router.POST("/workspaces", handler.CreateWorkspace)
+	router.GET("/workspace", handler.GetWorkspace)
+	router.PUT("/workspace", handler.UpdateWorkspace)
+	router.GET("/workspace/members", handler.GetWorkspaceMembers)
+	router.PUT("/workspace/members/:user_id", handler.SetWorkspaceMember)
+	router.DELETE("/workspace/members/:user_id", handler.RemoveWorkspaceMember)
+
+	return router, mockRepo
+}
+
+// TestCreateWorkspace はワークスペースを作成し、作成したユーザーを管理者にすることをテストします。
+func TestCreateWorkspace(t *testing.T) {
+	router, mockRepo := setupWorkspaceTestHandler(t)
+
+	// モックリポジトリの期待動作を設定
+	mockRepo.On("CreateWorkspace", mock.MatchedBy(func(ws *model.Workspace) bool {
+		return ws.Slug == "acme" && ws.Name == "Acme"
+	}), testUserID).Return(nil).Run(func(args mock.Arguments) {
+		args.Get(0).(*model.Workspace).ID = 7
+	})
+
+	// テストリクエストを作成（POST /workspaces）
+	req, err := http.NewRequest(http.MethodPost, "/workspaces", bytes.NewBufferString(`{"slug":" ACME ","name":"Acme"}`))
+	assert.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+
+	// リクエストをルーターに送信
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	var response map[string]map[string]interface{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, float64(7), response["data"]["id"])
+	assert.Equal(t, "acme", response["data"]["slug"])
+
+	mockRepo.AssertExpectations(t)
+}
+
+// TestCreateWorkspace_Invalid はサブドメインとして使えないスラッグ・重複したスラッグのエラーをテストします。
+func TestCreateWorkspace_Invalid(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		duplicated bool
+		wantStatus int
+	}{
+		{name: "ドットを含む", body: `{"slug":"a.b","name":"A"}`, wantStatus: http.StatusBadRequest},
+		{name: "記号を含む", body: `{"slug":"a_b","name":"A"}`, wantStatus: http.StatusBadRequest},
+		{name: "名前がない", body: `{"slug":"acme"}`, wantStatus: http.StatusBadRequest},
+		{name: "重複", body: `{"slug":"acme","name":"Acme"}`, duplicated: true, wantStatus: http.StatusConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router, mockRepo := setupWorkspaceTestHandler(t)
+			if tt.duplicated {
+				mockRepo.On("CreateWorkspace", mock.Anything, testUserID).Return(gorm.ErrDuplicatedKey)
+			}
+
+			req, err := http.NewRequest(http.MethodPost, "/workspaces", bytes.NewBufferString(tt.body))
+			assert.NoError(t, err)
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if !tt.duplicated {
+				mockRepo.AssertNotCalled(t, "CreateWorkspace", mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+// TestUpdateWorkspace はリクエストのワークスペースの名前を変更することをテストします。
+func TestUpdateWorkspace(t *testing.T) {
+	router, mockRepo := setupWorkspaceTestHandler(t)
+
+	// モックリポジトリの期待動作を設定
+	mockRepo.On("UpdateWorkspace", mock.MatchedBy(func(ws *model.Workspace) bool {
+		return ws.ID == testTenantID && ws.Slug == "test" && ws.Name == "新しい名前"
+	})).Return(nil)
+
+	// テストリクエストを作成（PUT /workspace）
+	req, err := http.NewRequest(http.MethodPut, "/workspace", bytes.NewBufferString(`{"name":"新しい名前"}`))
+	assert.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+
+	// リクエストをルーターに送信
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockRepo.AssertExpectations(t)
+}
+
+// TestSetWorkspaceMember はリクエストのワークスペースにメンバーを追加することをテストします。
+func TestSetWorkspaceMember(t *testing.T) {
+	router, mockRepo := setupWorkspaceTestHandler(t)
+
+	// モックリポジトリの期待動作を設定
+	mockRepo.On("SetWorkspaceMember", &model.WorkspaceMember{WorkspaceID: testTenantID, UserID: 5, Role: model.WorkspaceRoleMember}).Return(nil)
+
+	// テストリクエストを作成（PUT /workspace/members/5）
+	req, err := http.NewRequest(http.MethodPut, "/workspace/members/5", bytes.NewBufferString(`{"role":"member"}`))
+	assert.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+
+	// リクエストをルーターに送信
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockRepo.AssertExpectations(t)
+}
+
+// TestRemoveWorkspaceMember_LastAdmin は最後の管理者を削除しようとした場合に 409 を返すことをテストします。
+func TestRemoveWorkspaceMember_LastAdmin(t *testing.T) {
+	router, mockRepo := setupWorkspaceTestHandler(t)
+
+	// モックリポジトリの期待動作を設定
+	mockRepo.On("RemoveWorkspaceMember", testTenantID, testUserID).Return(repository.ErrLastAdmin)
+
+	// テストリクエストを作成（DELETE /workspace/members/1）
+	req, err := http.NewRequest(http.MethodDelete, "/workspace/members/1", nil)
+	assert.NoError(t, err)
+
+	// リクエストをルーターに送信
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	mockRepo.AssertExpectations(t)
+}
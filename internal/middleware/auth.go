@@ -0,0 +1,185 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ryory2/test-go-app-todo-go/internal/auth"
+	"github.com/ryory2/test-go-app-todo-go/internal/model"
+	"github.com/ryory2/test-go-app-todo-go/internal/repository"
+)
+
+// lastUsedInterval は個人用アクセストークンの最終使用日時を更新する最小間隔です（リクエストごとの書き込みを避ける）
+const lastUsedInterval = time.Minute
+
+// Principal は認証済みのリクエストの主体です
+type Principal struct {
+	UserID uint
+	Email  string
+	// SessionID はアクセストークンを発行したログインセッションのID
+	SessionID uint
+	// TokenID はアクセストークンの jti。ログアウト時に失効リストへ登録する
+	TokenID   string
+	ExpiresAt time.Time
+	// PersonalAccessTokenID は個人用アクセストークンで認証した場合のトークンのID
+	PersonalAccessTokenID uint
+	// Scopes は個人用アクセストークンのスコープ（ログインによる認証の場合は nil で、すべて許可する）
+	Scopes []string
+}
+
+// HasScope は主体が scope の操作を許可されているかどうかを返します
+func (p *Principal) HasScope(scope string) bool {
+	return p.Scopes == nil || model.HasScope(p.Scopes, scope)
+}
+
+// Authenticate は Authorization: Bearer ヘッダーのトークンを検証し、認証済みの主体を gin.Context に設定します。
+// JWT のアクセストークンと個人用アクセストークン（todo_pat_ で始まる）のどちらも受け付けます。
+// トークンがない、無効、または失効済みの場合は 401 を返します
+func Authenticate(tokens *auth.TokenManager, users repository.UserRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token, ok := bearerToken(c)
+		if !ok {
+			abortUnauthorized(c, "Authentication required")
+			return
+		}
+
+		if strings.HasPrefix(token, model.PersonalAccessTokenPrefix) {
+			authenticatePersonalAccessToken(c, users, token)
+			return
+		}
+
+		claims, err := tokens.ParseAccessToken(token)
+		if err != nil {
+			abortUnauthorized(c, "Invalid or expired token")
+			return
+		}
+
+		// ログアウトなどで失効させたトークンは受け付けない
+		revoked, err := users.IsTokenRevoked(claims.ID)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to authenticate"})
+			return
+		}
+		if revoked {
+			abortUnauthorized(c, "Token has been revoked")
+			return
+		}
+
+		userID, _ := claims.UserID()
+		SetPrincipal(c, &Principal{
+			UserID:    userID,
+			Email:     claims.Email,
+			SessionID: claims.SessionID,
+			TokenID:   claims.ID,
+			ExpiresAt: claims.ExpiresAt.Time,
+		})
+		c.Next()
+	}
+}
+
+// authenticatePersonalAccessToken は個人用アクセストークンを検証し、そのスコープを持つ主体を設定します
+func authenticatePersonalAccessToken(c *gin.Context, users repository.UserRepository, token string) {
+	pat, err := users.GetPersonalAccessToken(HashToken(token))
+	if err != nil {
+		abortUnauthorized(c, "Invalid or expired token")
+		return
+	}
+	now := time.Now()
+	if pat.IsExpired(now) {
+		abortUnauthorized(c, "Invalid or expired token")
+		return
+	}
+
+	// 最終使用日時を記録（失敗してもリクエストは続ける）
+	if pat.LastUsedAt == nil || now.Sub(*pat.LastUsedAt) >= lastUsedInterval {
+		if err := users.TouchPersonalAccessToken(pat, now); err != nil {
+			log.Printf("Failed to update last used time of personal access token %d: %v", pat.ID, err)
+		}
+	}
+
+	SetPrincipal(c, &Principal{
+		UserID:                pat.UserID,
+		Email:                 pat.User.Email,
+		ExpiresAt:             pat.ExpiresAt,
+		PersonalAccessTokenID: pat.ID,
+		Scopes:                pat.Scopes,
+	})
+	c.Next()
+}
+
+// RequireScope は主体が scope を持たない場合に 403 を返すミドルウェアです
+func RequireScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal, ok := GetPrincipal(c)
+		if !ok || !principal.HasScope(scope) {
+			abortInsufficientScope(c, scope)
+			return
+		}
+		c.Next()
+	}
+}
+
+// RequireMethodScope は参照系のメソッド（GET・HEAD）には read を、それ以外には write のスコープを要求するミドルウェアです
+func RequireMethodScope(read, write string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope := write
+		if c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead {
+			scope = read
+		}
+		principal, ok := GetPrincipal(c)
+		if !ok || !principal.HasScope(scope) {
+			abortInsufficientScope(c, scope)
+			return
+		}
+		c.Next()
+	}
+}
+
+// SetPrincipal は認証済みの主体を gin.Context に設定します
+func SetPrincipal(c *gin.Context, principal *Principal) {
+	c.Set(PrincipalKey, principal)
+	c.Set(UserIDKey, principal.UserID)
+	c.Set(ActorKey, principal.Email)
+}
+
+// GetPrincipal は gin.Context に設定された認証済みの主体を返します
+func GetPrincipal(c *gin.Context) (*Principal, bool) {
+	value, ok := c.Get(PrincipalKey)
+	if !ok {
+		return nil, false
+	}
+	principal, ok := value.(*Principal)
+	return principal, ok
+}
+
+// HashToken は保存・検索に使うトークンの SHA-256 ハッシュを返します
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// bearerToken は Authorization ヘッダーから Bearer トークンを取り出します
+func bearerToken(c *gin.Context) (string, bool) {
+	scheme, token, ok := strings.Cut(c.GetHeader("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") || strings.TrimSpace(token) == "" {
+		return "", false
+	}
+	return strings.TrimSpace(token), true
+}
+
+// abortUnauthorized は 401 Unauthorized のレスポンスを書き込み、以降のハンドラーを実行しません
+func abortUnauthorized(c *gin.Context, message string) {
+	c.Header("WWW-Authenticate", "Bearer")
+	c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": message})
+}
+
+// abortInsufficientScope は 403 Forbidden のレスポンスを書き込み、以降のハンドラーを実行しません
+func abortInsufficientScope(c *gin.Context, scope string) {
+	c.Header("WWW-Authenticate", `Bearer error="insufficient_scope", scope="`+scope+`"`)
+	c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Token does not have the required scope: " + scope})
+}
@@ -0,0 +1,169 @@
+// internal/middleware/auth_test.go
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ryory2/test-go-app-todo-go/internal/auth"
+	"github.com/ryory2/test-go-app-todo-go/internal/model"
+	"github.com/ryory2/test-go-app-todo-go/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+func setupAuthRouter(t *testing.T) (*gin.Engine, *auth.TokenManager, *repository.MockUserRepository) {
+	gin.SetMode(gin.TestMode)
+	tokens, err := auth.NewTokenManager(auth.Options{
+		Algorithm: auth.AlgorithmHS256,
+		Secret:    []byte("0123456789abcdef0123456789abcdef"),
+		Issuer:    "todo",
+		AccessTTL: time.Minute,
+	})
+	assert.NoError(t, err)
+	users := new(repository.MockUserRepository)
+
+	router := gin.New()
+	router.Use(Authenticate(tokens, users))
+	router.GET("/", func(c *gin.Context) {
+		principal, _ := GetPrincipal(c)
+		c.String(http.StatusOK, fmt.Sprintf("%d %s %d %s", c.GetUint(UserIDKey), c.GetString(ActorKey), principal.SessionID, principal.TokenID))
+	})
+	return router, tokens, users
+}
+
+// TestAuthenticate は有効なアクセストークンの場合に認証済みの主体を gin.Context に設定することをテストします。
+func TestAuthenticate(t *testing.T) {
+	router, tokens, users := setupAuthRouter(t)
+
+	token, claims, err := tokens.IssueAccessToken(&model.User{ID: 3, Email: "alice@example.com"}, 5)
+	assert.NoError(t, err)
+	users.On("IsTokenRevoked", claims.ID).Return(false, nil)
+
+	req, err := http.NewRequest(http.MethodGet, "/", nil)
+	assert.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "3 alice@example.com 5 "+claims.ID, w.Body.String())
+}
+
+// TestAuthenticate_Unauthorized はトークンがない・不正・失効済みの場合に 401 を返すことをテストします。
+func TestAuthenticate_Unauthorized(t *testing.T) {
+	router, tokens, users := setupAuthRouter(t)
+
+	revoked, claims, err := tokens.IssueAccessToken(&model.User{ID: 3}, 5)
+	assert.NoError(t, err)
+	users.On("IsTokenRevoked", claims.ID).Return(true, nil)
+
+	for _, header := range []string{"", "Basic dXNlcjpwYXNz", "Bearer ", "Bearer not-a-token", "Bearer " + revoked} {
+		req, err := http.NewRequest(http.MethodGet, "/", nil)
+		assert.NoError(t, err)
+		if header != "" {
+			req.Header.Set("Authorization", header)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code, header)
+		assert.Equal(t, "Bearer", w.Header().Get("WWW-Authenticate"), header)
+	}
+
+	users.AssertNumberOfCalls(t, "IsTokenRevoked", 1)
+	users.AssertNotCalled(t, "IsTokenRevoked", mock.MatchedBy(func(jti string) bool { return jti != claims.ID }))
+}
+
+// TestAuthenticate_PersonalAccessToken は個人用アクセストークンで認証し、最終使用日時を記録することをテストします。
+func TestAuthenticate_PersonalAccessToken(t *testing.T) {
+	router, _, users := setupAuthRouter(t)
+
+	value := model.PersonalAccessTokenPrefix + "secret"
+	pat := &model.PersonalAccessToken{
+		ID:        7,
+		UserID:    3,
+		User:      model.User{ID: 3, Email: "alice@example.com"},
+		Scopes:    []string{model.ScopeTasksRead},
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+	users.On("GetPersonalAccessToken", HashToken(value)).Return(pat, nil)
+	users.On("TouchPersonalAccessToken", pat, mock.AnythingOfType("time.Time")).Return(nil).Once()
+
+	req, err := http.NewRequest(http.MethodGet, "/", nil)
+	assert.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer "+value)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "3 alice@example.com 0 ", w.Body.String())
+	users.AssertExpectations(t)
+	users.AssertNotCalled(t, "IsTokenRevoked", mock.Anything)
+}
+
+// TestAuthenticate_PersonalAccessTokenExpired は有効期限切れ・未登録の個人用アクセストークンの場合に 401 を返すことをテストします。
+func TestAuthenticate_PersonalAccessTokenExpired(t *testing.T) {
+	router, _, users := setupAuthRouter(t)
+
+	expired := model.PersonalAccessTokenPrefix + "expired"
+	users.On("GetPersonalAccessToken", HashToken(expired)).Return(&model.PersonalAccessToken{ID: 7, UserID: 3, ExpiresAt: time.Now().Add(-time.Minute)}, nil)
+	unknown := model.PersonalAccessTokenPrefix + "unknown"
+	users.On("GetPersonalAccessToken", HashToken(unknown)).Return((*model.PersonalAccessToken)(nil), gorm.ErrRecordNotFound)
+
+	for _, token := range []string{expired, unknown} {
+		req, err := http.NewRequest(http.MethodGet, "/", nil)
+		assert.NoError(t, err)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code, token)
+	}
+	users.AssertNotCalled(t, "TouchPersonalAccessToken", mock.Anything, mock.Anything)
+}
+
+// TestRequireMethodScope はメソッドに応じて個人用アクセストークンのスコープを確認することをテストします。
+func TestRequireMethodScope(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name     string
+		scopes   []string
+		method   string
+		expected int
+	}{
+		{"login session", nil, http.MethodPost, http.StatusOK},
+		{"read scope get", []string{model.ScopeTasksRead}, http.MethodGet, http.StatusOK},
+		{"read scope post", []string{model.ScopeTasksRead}, http.MethodPost, http.StatusForbidden},
+		{"write scope get", []string{model.ScopeTasksWrite}, http.MethodGet, http.StatusOK},
+		{"write scope delete", []string{model.ScopeTasksWrite}, http.MethodDelete, http.StatusOK},
+		{"admin scope post", []string{model.ScopeAdmin}, http.MethodPost, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.Use(func(c *gin.Context) {
+				SetPrincipal(c, &Principal{UserID: 1, Scopes: tt.scopes})
+			})
+			router.Use(RequireMethodScope(model.ScopeTasksRead, model.ScopeTasksWrite))
+			router.Handle(tt.method, "/", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+			req, err := http.NewRequest(tt.method, "/", nil)
+			assert.NoError(t, err)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expected, w.Code)
+			if tt.expected == http.StatusForbidden {
+				assert.Contains(t, w.Header().Get("WWW-Authenticate"), `error="insufficient_scope"`)
+			}
+		})
+	}
+}
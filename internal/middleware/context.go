@@ -0,0 +1,19 @@
+package middleware
+
+// gin.Context に値を保存するキー
+const (
+	// RequestIDKey はリクエストID（string）のキーです
+	RequestIDKey = "request_id"
+	// ActorKey は操作したユーザーの識別子（string）のキーです。認証済みのリクエストでのみ設定されます
+	ActorKey = "actor"
+	// UserIDKey は認証済みのユーザーのID（uint）のキーです
+	UserIDKey = "user_id"
+	// PrincipalKey は認証済みの主体（*Principal）のキーです
+	PrincipalKey = "principal"
+	// TenantIDKey はリクエストのワークスペースのID（uint）のキーです
+	TenantIDKey = "tenant_id"
+	// WorkspaceKey はリクエストのワークスペース（*model.Workspace）のキーです
+	WorkspaceKey = "workspace"
+	// WorkspaceRoleKey はワークスペースでのユーザーのロール（string）のキーです
+	WorkspaceRoleKey = "workspace_role"
+)
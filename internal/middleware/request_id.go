@@ -0,0 +1,38 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"regexp"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequestIDHeader はリクエストIDを受け渡すヘッダーです
+const RequestIDHeader = "X-Request-ID"
+
+// validRequestID はクライアントが指定できるリクエストIDの形式です
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._-]{1,100}$`)
+
+// RequestID はリクエストごとにIDを割り当て、gin.Context とレスポンスヘッダーに設定します。
+// クライアントが X-Request-ID を指定した場合は、形式が正しければその値を引き継ぎます
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(RequestIDHeader)
+		if !validRequestID.MatchString(id) {
+			id = newRequestID()
+		}
+		c.Set(RequestIDKey, id)
+		c.Header(RequestIDHeader, id)
+		c.Next()
+	}
+}
+
+// newRequestID はランダムな32桁の16進数のリクエストIDを作成します
+func newRequestID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,65 @@
+// internal/middleware/request_id_test.go
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupRequestIDRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(RequestID())
+	router.GET("/", func(c *gin.Context) {
+		c.String(http.StatusOK, c.GetString(RequestIDKey))
+	})
+	return router
+}
+
+// TestRequestID はリクエストIDが生成され、コンテキストとレスポンスヘッダーに設定されることをテストします。
+func TestRequestID(t *testing.T) {
+	router := setupRequestIDRouter()
+
+	req, err := http.NewRequest(http.MethodGet, "/", nil)
+	assert.NoError(t, err)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	id := w.Header().Get(RequestIDHeader)
+	assert.Len(t, id, 32)
+	assert.Equal(t, id, w.Body.String())
+}
+
+// TestRequestID_FromHeader はクライアントが指定したリクエストIDを引き継ぎ、不正な値は置き換えることをテストします。
+func TestRequestID_FromHeader(t *testing.T) {
+	router := setupRequestIDRouter()
+
+	tests := []struct {
+		header string
+		keep   bool
+	}{
+		{"req-123.abc_DEF", true},
+		{"bad id\nwith newline", false},
+		{string(make([]byte, 101)), false},
+	}
+
+	for _, tt := range tests {
+		req, err := http.NewRequest(http.MethodGet, "/", nil)
+		assert.NoError(t, err)
+		req.Header.Set(RequestIDHeader, tt.header)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		id := w.Header().Get(RequestIDHeader)
+		if tt.keep {
+			assert.Equal(t, tt.header, id)
+		} else {
+			assert.NotEqual(t, tt.header, id)
+			assert.Len(t, id, 32)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package middleware
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ryory2/test-go-app-todo-go/internal/model"
+	"github.com/ryory2/test-go-app-todo-go/internal/repository"
+	"gorm.io/gorm"
+)
+
+// WorkspaceHeader はリクエストのワークスペースを指定するヘッダーです
+const WorkspaceHeader = "X-Workspace"
+
+// ResolveTenant はリクエストのワークスペースを特定し、gin.Context に設定するミドルウェアです。
+// ワークスペースは X-Workspace ヘッダー、baseDomain のサブドメイン（acme.example.com の acme）、
+// defaultSlug の順に決定します。Authenticate の後に使用し、ユーザーがメンバーでないワークスペースには
+// （defaultSlug のワークスペースを含めて）403 を返します
+func ResolveTenant(workspaces repository.WorkspaceRepository, baseDomain, defaultSlug string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		slug := workspaceSlug(c, baseDomain, defaultSlug)
+		if slug == "" {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Workspace is required"})
+			return
+		}
+
+		workspace, err := workspaces.GetWorkspaceBySlug(slug)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Workspace not found"})
+				return
+			}
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to resolve workspace"})
+			return
+		}
+
+		// ワークスペースのメンバーであることを確認
+		role, err := workspaces.GetWorkspaceRole(workspace.ID, c.GetUint(UserIDKey))
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to resolve workspace"})
+			return
+		}
+		if role == "" {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "You are not a member of this workspace", "reason": "not_a_workspace_member"})
+			return
+		}
+
+		SetTenant(c, workspace, role)
+		c.Next()
+	}
+}
+
+// RequireWorkspaceAdmin はワークスペースの管理者でない場合に 403 を返すミドルウェアです（ResolveTenant の後に使用する）
+func RequireWorkspaceAdmin() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetString(WorkspaceRoleKey) != model.WorkspaceRoleAdmin {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Workspace admin role is required"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// SetTenant はリクエストのワークスペースとそのワークスペースでのロールを gin.Context に設定します
+func SetTenant(c *gin.Context, workspace *model.Workspace, role string) {
+	c.Set(TenantIDKey, workspace.ID)
+	c.Set(WorkspaceKey, workspace)
+	c.Set(WorkspaceRoleKey, role)
+}
+
+// GetWorkspace は gin.Context に設定されたリクエストのワークスペースを返します
+func GetWorkspace(c *gin.Context) (*model.Workspace, bool) {
+	value, ok := c.Get(WorkspaceKey)
+	if !ok {
+		return nil, false
+	}
+	workspace, ok := value.(*model.Workspace)
+	return workspace, ok
+}
+
+// workspaceSlug はリクエストで指定されたワークスペースのスラッグを返します
+func workspaceSlug(c *gin.Context, baseDomain, defaultSlug string) string {
+	if slug := strings.TrimSpace(c.GetHeader(WorkspaceHeader)); slug != "" {
+		return strings.ToLower(slug)
+	}
+	if baseDomain != "" {
+		host := c.Request.Host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		sub, ok := strings.CutSuffix(strings.ToLower(host), "."+strings.ToLower(baseDomain))
+		if ok && sub != "" && !strings.Contains(sub, ".") {
+			return sub
+		}
+	}
+	return defaultSlug
+}
@@ -0,0 +1,173 @@
+// internal/middleware/tenant_test.go
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ryory2/test-go-app-todo-go/internal/model"
+	"github.com/ryory2/test-go-app-todo-go/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func setupTenantRouter(t *testing.T, baseDomain, defaultSlug string) (*gin.Engine, *repository.MockWorkspaceRepository) {
+	gin.SetMode(gin.TestMode)
+	workspaces := new(repository.MockWorkspaceRepository)
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		SetPrincipal(c, &Principal{UserID: 3})
+		c.Next()
+	})
+	router.Use(ResolveTenant(workspaces, baseDomain, defaultSlug))
+	router.GET("/", func(c *gin.Context) {
+		c.String(http.StatusOK, fmt.Sprintf("%d %s", c.GetUint(TenantIDKey), c.GetString(WorkspaceRoleKey)))
+	})
+	router.GET("/admin", RequireWorkspaceAdmin(), func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+	return router, workspaces
+}
+
+// TestResolveTenant_Header は X-Workspace ヘッダーで指定したワークスペースを gin.Context に設定することをテストします。
+func TestResolveTenant_Header(t *testing.T) {
+	router, workspaces := setupTenantRouter(t, "example.com", "default")
+	workspaces.On("GetWorkspaceBySlug", "acme").Return(&model.Workspace{ID: 7, Slug: "acme"}, nil)
+	workspaces.On("GetWorkspaceRole", uint(7), uint(3)).Return(model.WorkspaceRoleAdmin, nil)
+
+	req, err := http.NewRequest(http.MethodGet, "/", nil)
+	assert.NoError(t, err)
+	req.Host = "other.example.com"
+	req.Header.Set(WorkspaceHeader, "ACME")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "7 admin", w.Body.String())
+}
+
+// TestResolveTenant_Subdomain はヘッダーがない場合にサブドメインからワークスペースを特定することをテストします。
+func TestResolveTenant_Subdomain(t *testing.T) {
+	router, workspaces := setupTenantRouter(t, "example.com", "default")
+	workspaces.On("GetWorkspaceBySlug", "acme").Return(&model.Workspace{ID: 7, Slug: "acme"}, nil)
+	workspaces.On("GetWorkspaceRole", uint(7), uint(3)).Return(model.WorkspaceRoleMember, nil)
+
+	req, err := http.NewRequest(http.MethodGet, "/", nil)
+	assert.NoError(t, err)
+	req.Host = "acme.example.com:8080"
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "7 member", w.Body.String())
+}
+
+// TestResolveTenant_Default はワークスペースが指定されていない場合に既定のワークスペースを使うことをテストします。
+func TestResolveTenant_Default(t *testing.T) {
+	router, workspaces := setupTenantRouter(t, "example.com", "default")
+	workspaces.On("GetWorkspaceBySlug", "default").Return(&model.Workspace{ID: 1, Slug: "default"}, nil)
+	workspaces.On("GetWorkspaceRole", uint(1), uint(3)).Return(model.WorkspaceRoleMember, nil)
+
+	req, err := http.NewRequest(http.MethodGet, "/", nil)
+	assert.NoError(t, err)
+	req.Host = "example.com"
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "1 member", w.Body.String())
+}
+
+// TestResolveTenant_Errors はワークスペースを特定できない・メンバーでない場合のエラーをテストします。
+func TestResolveTenant_Errors(t *testing.T) {
+	tests := []struct {
+		name       string
+		slug       string
+		defaultWS  string
+		setup      func(workspaces *repository.MockWorkspaceRepository)
+		wantStatus int
+	}{
+		{
+			name:       "指定なしで既定のワークスペースもない",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "存在しないワークスペース",
+			slug: "missing",
+			setup: func(workspaces *repository.MockWorkspaceRepository) {
+				workspaces.On("GetWorkspaceBySlug", "missing").Return((*model.Workspace)(nil), gorm.ErrRecordNotFound)
+			},
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name: "メンバーでないワークスペース",
+			slug: "acme",
+			setup: func(workspaces *repository.MockWorkspaceRepository) {
+				workspaces.On("GetWorkspaceBySlug", "acme").Return(&model.Workspace{ID: 7, Slug: "acme"}, nil)
+				workspaces.On("GetWorkspaceRole", uint(7), uint(3)).Return("", nil)
+			},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:      "メンバーでない既定のワークスペース",
+			defaultWS: "default",
+			setup: func(workspaces *repository.MockWorkspaceRepository) {
+				workspaces.On("GetWorkspaceBySlug", "default").Return(&model.Workspace{ID: 1, Slug: "default"}, nil)
+				workspaces.On("GetWorkspaceRole", uint(1), uint(3)).Return("", nil)
+			},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name: "リポジトリのエラー",
+			slug: "acme",
+			setup: func(workspaces *repository.MockWorkspaceRepository) {
+				workspaces.On("GetWorkspaceBySlug", "acme").Return((*model.Workspace)(nil), errors.New("db error"))
+			},
+			wantStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router, workspaces := setupTenantRouter(t, "", tt.defaultWS)
+			if tt.setup != nil {
+				tt.setup(workspaces)
+			}
+
+			req, err := http.NewRequest(http.MethodGet, "/", nil)
+			assert.NoError(t, err)
+			if tt.slug != "" {
+				req.Header.Set(WorkspaceHeader, tt.slug)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+		})
+	}
+}
+
+// TestRequireWorkspaceAdmin はワークスペースの管理者でない場合に 403 を返すことをテストします。
+func TestRequireWorkspaceAdmin(t *testing.T) {
+	for role, want := range map[string]int{
+		model.WorkspaceRoleAdmin:  http.StatusNoContent,
+		model.WorkspaceRoleMember: http.StatusForbidden,
+	} {
+		router, workspaces := setupTenantRouter(t, "", "")
+		workspaces.On("GetWorkspaceBySlug", "acme").Return(&model.Workspace{ID: 7, Slug: "acme"}, nil)
+		workspaces.On("GetWorkspaceRole", uint(7), uint(3)).Return(role, nil)
+
+		req, err := http.NewRequest(http.MethodGet, "/admin", nil)
+		assert.NoError(t, err)
+		req.Header.Set(WorkspaceHeader, "acme")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, want, w.Code, role)
+	}
+}
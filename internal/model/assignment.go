@@ -0,0 +1,31 @@
+package model
+
+import "time"
+
+// AssignmentAction はタスクの担当者の変化の種類です
+type AssignmentAction string
+
+const (
+	AssignmentActionAssigned   AssignmentAction = "assigned"
+	AssignmentActionUnassigned AssignmentAction = "unassigned"
+)
+
+// TaskAssignee はタスクの担当者です
+type TaskAssignee struct {
+	TaskID    uint      `json:"task_id" gorm:"primaryKey"`
+	UserID    uint      `json:"user_id" gorm:"primaryKey"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// AssignmentEvent はタスクの担当者の追加・解除のイベントです。
+// 通知などの他の機能は作成日時・ID の昇順に読み出し、処理済みのイベントより後のイベントを処理します
+type AssignmentEvent struct {
+	ID     uint             `json:"id" gorm:"primaryKey"`
+	TaskID uint             `json:"task_id"`
+	UserID uint             `json:"user_id"` // 担当者に追加・解除されたユーザー
+	Action AssignmentAction `json:"action"`
+	// ActorID は担当者を変更したユーザー（ユーザーが削除された場合は nil）
+	ActorID *uint `json:"actor_id"`
+	// CreatedAt はデータベースが行の追加時に設定する（取得の順序をアプリケーションサーバーの時計に依存させない）
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime:false;default:clock_timestamp()"`
+}
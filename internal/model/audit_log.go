@@ -0,0 +1,42 @@
+package model
+
+import "time"
+
+// 監査ログの操作の種類
+const (
+	AuditActionCreate    = "create"
+	AuditActionUpdate    = "update"
+	AuditActionDelete    = "delete"
+	AuditActionToggle    = "toggle"
+	AuditActionTags      = "tags"
+	AuditActionParent    = "parent"
+	AuditActionArchive   = "archive"
+	AuditActionUnarchive = "unarchive"
+	AuditActionRestore   = "restore"
+	AuditActionPurge     = "purge"
+	AuditActionRevert    = "revert"
+	AuditActionUndo      = "undo"
+	AuditActionAssign    = "assign"
+	AuditActionUnassign  = "unassign"
+	AuditActionProject   = "project"
+)
+
+// AuditLog はリソースの変更操作の監査ログです。記録後に更新・削除することはできません
+type AuditLog struct {
+	ID           uint                   `json:"id" gorm:"primaryKey"`
+	TenantID     uint                   `json:"-"` // 操作したワークスペース
+	Action       string                 `json:"action"`
+	ResourceType string                 `json:"resource_type"`
+	ResourceID   uint                   `json:"resource_id"`
+	Actor        string                 `json:"actor"`
+	RequestID    string                 `json:"request_id"`
+	ClientIP     string                 `json:"client_ip"`
+	Changes      map[string]FieldChange `json:"changes" gorm:"serializer:json"` // 変更されたフィールド（JSON のキー）ごとの変更前後の値
+	CreatedAt    time.Time              `json:"created_at"`
+}
+
+// FieldChange はフィールドの変更前後の値です（作成時の Before・削除時の After は null）
+type FieldChange struct {
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
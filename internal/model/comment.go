@@ -0,0 +1,16 @@
+package model
+
+import "time"
+
+// Comment はタスクへのコメントです。本文は Markdown のまま保存し、表示する側で変換します
+type Comment struct {
+	ID       uint   `json:"id" gorm:"primaryKey"`
+	TaskID   uint   `json:"task_id"`
+	AuthorID uint   `json:"author_id"`
+	Author   *User  `json:"author,omitempty"`
+	Body     string `json:"body"`
+	// EditedAt は本文を最後に編集した日時（編集していない場合は nil）
+	EditedAt  *time.Time `json:"edited_at"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
@@ -0,0 +1,19 @@
+package model
+
+import "time"
+
+// CompletionAction はタスクの完了状態の変化の種類です
+type CompletionAction string
+
+const (
+	CompletionActionCompleted CompletionAction = "completed"
+	CompletionActionReopened  CompletionAction = "reopened"
+)
+
+// CompletionEvent はタスクの完了・再オープンの履歴です
+type CompletionEvent struct {
+	ID        uint             `json:"id" gorm:"primaryKey"`
+	TaskID    uint             `json:"task_id"`
+	Action    CompletionAction `json:"action"`
+	CreatedAt time.Time        `json:"created_at"`
+}
@@ -0,0 +1,55 @@
+package model
+
+import "time"
+
+// タスク・プロジェクトのメンバーのロール（権限の強い順）
+const (
+	RoleOwner     = "owner"
+	RoleEditor    = "editor"
+	RoleCommenter = "commenter"
+	RoleViewer    = "viewer"
+)
+
+// roleRanks はロールの強さです（大きいほど多くの操作を許可する）
+var roleRanks = map[string]int{
+	RoleViewer:    1,
+	RoleCommenter: 2,
+	RoleEditor:    3,
+	RoleOwner:     4,
+}
+
+// RoleRank はロールの強さを返します（ロールがない・不明な場合は 0）
+func RoleRank(role string) int {
+	return roleRanks[role]
+}
+
+// HighestRole はロールのうち最も強いものを返します（ロールがない場合は空文字列）
+func HighestRole(roles ...string) string {
+	highest := ""
+	for _, role := range roles {
+		if RoleRank(role) > RoleRank(highest) {
+			highest = role
+		}
+	}
+	return highest
+}
+
+// ProjectMember はプロジェクトのメンバーです。プロジェクト内のすべてのタスクにロールの権限を持ちます
+type ProjectMember struct {
+	ProjectID uint      `json:"project_id" gorm:"primaryKey"`
+	UserID    uint      `json:"user_id" gorm:"primaryKey"`
+	User      *User     `json:"user,omitempty"`
+	Role      string    `json:"role"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TaskMember はタスクを共有されたメンバーです。タスクの所有者は owner のロールを持つものとして扱います
+type TaskMember struct {
+	TaskID    uint      `json:"task_id" gorm:"primaryKey"`
+	UserID    uint      `json:"user_id" gorm:"primaryKey"`
+	User      *User     `json:"user,omitempty"`
+	Role      string    `json:"role"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
@@ -0,0 +1,46 @@
+package model
+
+import (
+	"slices"
+	"time"
+)
+
+// 個人用アクセストークンに付与できるスコープ
+const (
+	ScopeTasksRead  = "tasks:read"
+	ScopeTasksWrite = "tasks:write"
+	ScopeAdmin      = "admin"
+)
+
+// PersonalAccessTokenPrefix は個人用アクセストークンの先頭に付ける文字列です（JWT のアクセストークンと区別する）
+const PersonalAccessTokenPrefix = "todo_pat_"
+
+// PersonalAccessToken は CI やスクリプトなど、ログインせずに API を利用するためのトークンです。
+// トークン自体は保存せず、SHA-256 のハッシュのみを保存します
+type PersonalAccessToken struct {
+	ID     uint   `json:"id" gorm:"primaryKey"`
+	UserID uint   `json:"-"`
+	User   User   `json:"-"`
+	Name   string `json:"name"`
+	// TokenPrefix は一覧でトークンを見分けるための先頭部分
+	TokenPrefix string     `json:"token_prefix"`
+	TokenHash   string     `json:"-" gorm:"uniqueIndex"`
+	Scopes      []string   `json:"scopes" gorm:"serializer:json"`
+	ExpiresAt   time.Time  `json:"expires_at"`
+	LastUsedAt  *time.Time `json:"last_used_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// IsExpired はトークンの有効期限が切れているかどうかを返します
+func (t *PersonalAccessToken) IsExpired(now time.Time) bool {
+	return !now.Before(t.ExpiresAt)
+}
+
+// HasScope はスコープの一覧が scope を満たすかどうかを返します。
+// admin はすべてのスコープを、tasks:write は tasks:read を含みます
+func HasScope(scopes []string, scope string) bool {
+	if slices.Contains(scopes, ScopeAdmin) || slices.Contains(scopes, scope) {
+		return true
+	}
+	return scope == ScopeTasksRead && slices.Contains(scopes, ScopeTasksWrite)
+}
@@ -0,0 +1,18 @@
+package model
+
+import "time"
+
+type Project struct {
+	ID          uint       `json:"id" gorm:"primaryKey"`
+	TenantID    uint       `json:"-" gorm:"<-:create"` // 所属するワークスペース（作成後は変更しない）
+	Name        string     `json:"name" validate:"required,max=100"`
+	Description string     `json:"description" validate:"omitempty,max=500"`
+	ArchivedAt  *time.Time `json:"archived_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// IsArchived はプロジェクトがアーカイブ済みかどうかを返します
+func (p *Project) IsArchived() bool {
+	return p.ArchivedAt != nil
+}
@@ -0,0 +1,12 @@
+package model
+
+import "time"
+
+// RevokedToken は有効期限前に無効にしたアクセストークン（失効リスト）です
+type RevokedToken struct {
+	// JTI はアクセストークンの jti クレーム
+	JTI string `gorm:"column:jti;primaryKey"`
+	// ExpiresAt はアクセストークンの有効期限。期限を過ぎた行は失効リストから削除できる
+	ExpiresAt time.Time
+	CreatedAt time.Time
+}
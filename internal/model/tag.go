@@ -0,0 +1,12 @@
+package model
+
+import "time"
+
+type Tag struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	TenantID  uint      `json:"-" gorm:"<-:create"`        // 所属するワークスペース（作成後は変更しない）
+	OwnerID   *uint     `json:"owner_id" gorm:"<-:create"` // 作成したユーザー（所有者の記録を始める前に作成したタグは nil）
+	Name      string    `json:"name" validate:"required,max=50"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
@@ -1,13 +1,108 @@
 package model
 
-import "time"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Priority はタスクの優先度です
+type Priority string
+
+const (
+	PriorityNone   Priority = "none"
+	PriorityLow    Priority = "low"
+	PriorityMedium Priority = "medium"
+	PriorityHigh   Priority = "high"
+	PriorityUrgent Priority = "urgent"
+)
+
+// Rank は優先度の高さを数値で返します（none が 0、urgent が 4）
+func (p Priority) Rank() int {
+	switch p {
+	case PriorityLow:
+		return 1
+	case PriorityMedium:
+		return 2
+	case PriorityHigh:
+		return 3
+	case PriorityUrgent:
+		return 4
+	default:
+		return 0
+	}
+}
 
 type Task struct {
-	ID          uint      `json:"id" gorm:"primaryKey"`
-	Title       string    `json:"title" validate:"required,max=100"`
-	Description string    `json:"description" validate:"omitempty,max=500"`
-	DueDate     time.Time `json:"due_date" validate:"omitempty"`
-	IsCompleted bool      `json:"is_completed"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID             uint       `json:"id" gorm:"primaryKey"`
+	TenantID       uint       `json:"-" gorm:"<-:create"` // 所属するワークスペース（作成後は変更しない）
+	Title          string     `json:"title" validate:"required,max=100"`
+	Description    string     `json:"description" validate:"omitempty,max=500"`
+	DueDate        time.Time  `json:"due_date" validate:"omitempty"`
+	IsCompleted    bool       `json:"is_completed"`
+	CompletedAt    *time.Time `json:"completed_at"` // 最後に完了にした日時（未完了の場合は nil）
+	Priority       Priority   `json:"priority" validate:"omitempty,oneof=none low medium high urgent"`
+	ProjectID      *uint      `json:"project_id"`
+	ParentID       *uint      `json:"parent_id"`
+	RRule          string     `json:"rrule" gorm:"column:rrule" validate:"omitempty,max=255"`
+	RecurrenceOfID *uint      `json:"recurrence_of_id,omitempty"`
+	OwnerID        *uint      `json:"owner_id"` // 作成したユーザー（所有者）
+	Tags           []Tag      `json:"tags,omitempty" gorm:"many2many:tasks_tags;"`
+	Assignees      []User     `json:"assignees,omitempty" gorm:"many2many:task_assignees;"`
+	ArchivedAt     *time.Time `json:"archived_at"`                       // アーカイブした日時（完了状態とは独立。アーカイブ済みのタスクは一覧から除外される）
+	Version        uint       `json:"version" gorm:"not null;default:1"` // 楽観的ロック用（更新のたびに1ずつ増える）
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+	// DeletedAt はゴミ箱に移動した日時（論理削除）。gorm のクエリでは自動的に除外される
+	DeletedAt gorm.DeletedAt `json:"deleted_at" gorm:"index"`
+
+	// Progress は子タスクの完了率（0〜100）。子タスクを持たない場合は nil
+	Progress *int `json:"progress,omitempty" gorm:"-"`
+	// CommentCount はタスクへのコメントの件数。一覧・単体の取得時にのみ設定される
+	CommentCount int `json:"comment_count" gorm:"-"`
+	// Children はサブツリー取得時にのみ設定される子タスク
+	Children []Task `json:"children,omitempty" gorm:"-"`
+	// NextOccurrence は繰り返しタスクを完了にした際に生成した次回のタスク。生成した操作の直後にのみ設定される
+	NextOccurrence *Task `json:"-" gorm:"-"`
+}
+
+// IsArchived はタスクがアーカイブ済みかどうかを返します
+func (t *Task) IsArchived() bool {
+	return t.ArchivedAt != nil
+}
+
+// Occurrence は繰り返しタスクの未生成の発生予定です
+type Occurrence struct {
+	TaskID  uint      `json:"task_id"`
+	Title   string    `json:"title"`
+	DueDate time.Time `json:"due_date"`
+}
+
+// BuildTaskTree はフラットなタスクの一覧から rootID を根とするツリーを組み立てます
+func BuildTaskTree(tasks []Task, rootID uint) *Task {
+	byParent := make(map[uint][]Task)
+	var root *Task
+	for i := range tasks {
+		if tasks[i].ID == rootID {
+			root = &tasks[i]
+			continue
+		}
+		if tasks[i].ParentID != nil {
+			byParent[*tasks[i].ParentID] = append(byParent[*tasks[i].ParentID], tasks[i])
+		}
+	}
+	if root == nil {
+		return nil
+	}
+
+	var attach func(task *Task)
+	attach = func(task *Task) {
+		task.Children = byParent[task.ID]
+		for i := range task.Children {
+			attach(&task.Children[i])
+		}
+	}
+	tree := *root
+	attach(&tree)
+	return &tree
 }
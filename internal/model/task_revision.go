@@ -0,0 +1,38 @@
+package model
+
+import "time"
+
+// TaskRevision はタスクの更新ごとに保存される内容（タイトル・説明など）のスナップショットです
+type TaskRevision struct {
+	ID          uint      `json:"-" gorm:"primaryKey"`
+	TaskID      uint      `json:"task_id"`
+	Revision    uint      `json:"revision"` // 保存時のタスクのバージョン
+	Title       string    `json:"title"`
+	Description string    `json:"description"`
+	DueDate     time.Time `json:"due_date"`
+	Priority    Priority  `json:"priority"`
+	RRule       string    `json:"rrule" gorm:"column:rrule"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// NewTaskRevision はタスクの現在の内容からリビジョンを作成します
+func NewTaskRevision(task *Task) *TaskRevision {
+	return &TaskRevision{
+		TaskID:      task.ID,
+		Revision:    task.Version,
+		Title:       task.Title,
+		Description: task.Description,
+		DueDate:     task.DueDate,
+		Priority:    task.Priority,
+		RRule:       task.RRule,
+	}
+}
+
+// ApplyTo はリビジョンの内容をタスクに書き戻します
+func (r *TaskRevision) ApplyTo(task *Task) {
+	task.Title = r.Title
+	task.Description = r.Description
+	task.DueDate = r.DueDate
+	task.Priority = r.Priority
+	task.RRule = r.RRule
+}
@@ -0,0 +1,34 @@
+package model
+
+import "time"
+
+// 取り消しできる操作の種類
+const (
+	UndoActionUpdate = "update"
+	UndoActionToggle = "toggle"
+	UndoActionDelete = "delete"
+)
+
+// UndoToken は直前の操作を取り消すためのトークンです。トークン自体は保存せず、SHA-256 のハッシュのみを保存します
+type UndoToken struct {
+	ID        uint   `gorm:"primaryKey"`
+	TokenHash string `gorm:"uniqueIndex"`
+	Action    string
+	TaskID    uint
+	// Version は操作後のタスクのバージョン。取り消し時に一致しない場合はタスクが変更されたとみなす
+	Version uint
+	// Snapshot は操作前のタスク
+	Snapshot *Task `gorm:"serializer:json"`
+	// ChildIDs は操作の影響を受けた他のタスク（delete で付け替えた子タスク、toggle で完了にした子孫タスク）
+	ChildIDs  []uint `gorm:"serializer:json"`
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+	CreatedAt time.Time
+	// NextOccurrenceID は toggle・update で繰り返しタスクを完了にした際に生成した次回のタスク（取り消し時に削除する）
+	NextOccurrenceID *uint
+}
+
+// IsExpired はトークンの有効期限が切れているかどうかを返します
+func (u *UndoToken) IsExpired(now time.Time) bool {
+	return !now.Before(u.ExpiresAt)
+}
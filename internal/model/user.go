@@ -0,0 +1,50 @@
+package model
+
+import (
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// LegacyOwnerEmail はユーザー管理を導入する前から存在するタスクを所有する、移行用のユーザーのメールアドレスです。
+// パスワードを持たないためログインできず、ClaimLegacyData で登録済みのユーザーに引き継ぎます
+const LegacyOwnerEmail = "legacy-owner@localhost"
+
+type User struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	Email        string    `json:"email" gorm:"uniqueIndex"`
+	Name         string    `json:"name"`
+	PasswordHash string    `json:"-"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// SetPassword はパスワードを bcrypt でハッシュ化して設定します
+func (u *User) SetPassword(password string) error {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	u.PasswordHash = string(hash)
+	return nil
+}
+
+// CheckPassword はパスワードがハッシュと一致するかどうかを返します
+func (u *User) CheckPassword(password string) bool {
+	return bcrypt.CompareHashAndPassword([]byte(u.PasswordHash), []byte(password)) == nil
+}
+
+// Session はログインセッションです。セッションのリフレッシュトークン自体は保存せず、SHA-256 のハッシュのみを保存します
+type Session struct {
+	ID        uint   `gorm:"primaryKey"`
+	TokenHash string `gorm:"uniqueIndex"`
+	UserID    uint
+	User      User
+	ExpiresAt time.Time
+	CreatedAt time.Time
+}
+
+// IsExpired はセッションの有効期限が切れているかどうかを返します
+func (s *Session) IsExpired(now time.Time) bool {
+	return !now.Before(s.ExpiresAt)
+}
@@ -0,0 +1,30 @@
+package model
+
+import "time"
+
+// ワークスペースのメンバーのロール
+const (
+	// WorkspaceRoleAdmin はワークスペースの設定とメンバーを管理できるロールです
+	WorkspaceRoleAdmin  = "admin"
+	WorkspaceRoleMember = "member"
+)
+
+// Workspace はデータを分離する単位（テナント）です。タスク・タグ・プロジェクトなどはいずれか1つのワークスペースに属します
+type Workspace struct {
+	ID uint `json:"id" gorm:"primaryKey"`
+	// Slug はリクエストのヘッダーやサブドメインでワークスペースを指定する識別子
+	Slug      string    `json:"slug" gorm:"uniqueIndex"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// WorkspaceMember はワークスペースを利用できるユーザーです
+type WorkspaceMember struct {
+	WorkspaceID uint      `json:"workspace_id" gorm:"primaryKey"`
+	UserID      uint      `json:"user_id" gorm:"primaryKey"`
+	User        *User     `json:"user,omitempty"`
+	Role        string    `json:"role"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
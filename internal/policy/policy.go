@@ -0,0 +1,96 @@
+// Package policy はタスク・プロジェクトのメンバーのロールに基づいて操作を許可するかを判定します
+package policy
+
+import (
+	"fmt"
+
+	"github.com/ryory2/test-go-app-todo-go/internal/model"
+	"github.com/ryory2/test-go-app-todo-go/internal/repository"
+)
+
+// Action はロールで制御する操作の種類です
+type Action string
+
+const (
+	ActionView          Action = "view"
+	ActionComment       Action = "comment"
+	ActionEdit          Action = "edit"
+	ActionDelete        Action = "delete"
+	ActionManageMembers Action = "manage_members"
+)
+
+// 操作を拒否した理由（レスポンスの reason）
+const (
+	// ReasonNotMember はタスク・プロジェクトのメンバーではないことを表します
+	ReasonNotMember = "not_a_member"
+	// ReasonInsufficientRole はロールの権限が足りないことを表します
+	ReasonInsufficientRole = "insufficient_role"
+)
+
+// requiredRoles は操作ごとに必要な最小のロールです
+var requiredRoles = map[Action]string{
+	ActionView:          model.RoleViewer,
+	ActionComment:       model.RoleCommenter,
+	ActionEdit:          model.RoleEditor,
+	ActionDelete:        model.RoleOwner,
+	ActionManageMembers: model.RoleOwner,
+}
+
+// Denial は操作を拒否したことを表すエラーです
+type Denial struct {
+	Reason       string `json:"reason"`
+	Action       Action `json:"action"`
+	Role         string `json:"role,omitempty"`
+	RequiredRole string `json:"required_role"`
+}
+
+func (d *Denial) Error() string {
+	if d.Reason == ReasonNotMember {
+		return fmt.Sprintf("You are not a member; %s requires the %s role", d.Action, d.RequiredRole)
+	}
+	return fmt.Sprintf("The %s role cannot %s; the %s role is required", d.Role, d.Action, d.RequiredRole)
+}
+
+// Check は role のメンバーに action を許可するかを判定し、許可しない場合は *Denial を返します
+func Check(role string, action Action) error {
+	required, ok := requiredRoles[action]
+	if !ok {
+		return fmt.Errorf("unknown action: %s", action)
+	}
+	if role == "" {
+		return &Denial{Reason: ReasonNotMember, Action: action, RequiredRole: required}
+	}
+	if model.RoleRank(role) < model.RoleRank(required) {
+		return &Denial{Reason: ReasonInsufficientRole, Action: action, Role: role, RequiredRole: required}
+	}
+	return nil
+}
+
+// Enforcer はユーザーのロールを取得し、操作を許可するかを判定します
+type Enforcer struct {
+	members repository.MemberRepository
+}
+
+// NewEnforcer は members からロールを取得する Enforcer を作成します
+func NewEnforcer(members repository.MemberRepository) *Enforcer {
+	return &Enforcer{members: members}
+}
+
+// AuthorizeTask は userID のユーザーがタスクに対して action を実行できるかを判定します。
+// タスクの所有者・タスクのメンバー・タスクのプロジェクトのメンバーのロールのうち最も強いものを使います
+func (e *Enforcer) AuthorizeTask(userID, taskID uint, action Action) error {
+	role, err := e.members.GetTaskRole(taskID, userID)
+	if err != nil {
+		return err
+	}
+	return Check(role, action)
+}
+
+// AuthorizeProject は userID のユーザーがプロジェクトに対して action を実行できるかを判定します
+func (e *Enforcer) AuthorizeProject(userID, projectID uint, action Action) error {
+	role, err := e.members.GetProjectRole(projectID, userID)
+	if err != nil {
+		return err
+	}
+	return Check(role, action)
+}
@@ -0,0 +1,63 @@
+// internal/policy/policy_test.go
+package policy
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/ryory2/test-go-app-todo-go/internal/model"
+	"github.com/ryory2/test-go-app-todo-go/internal/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestCheck はロールごとに許可する操作をテストします。
+func TestCheck(t *testing.T) {
+	allowed := map[string][]Action{
+		model.RoleOwner:     {ActionView, ActionComment, ActionEdit, ActionDelete, ActionManageMembers},
+		model.RoleEditor:    {ActionView, ActionComment, ActionEdit},
+		model.RoleCommenter: {ActionView, ActionComment},
+		model.RoleViewer:    {ActionView},
+	}
+	actions := []Action{ActionView, ActionComment, ActionEdit, ActionDelete, ActionManageMembers}
+
+	for role, permitted := range allowed {
+		for _, action := range actions {
+			err := Check(role, action)
+			if slices.Contains(permitted, action) {
+				assert.NoError(t, err, "%s %s", role, action)
+				continue
+			}
+			var denial *Denial
+			if assert.True(t, errors.As(err, &denial), "%s %s", role, action) {
+				assert.Equal(t, ReasonInsufficientRole, denial.Reason)
+				assert.Equal(t, role, denial.Role)
+			}
+		}
+	}
+}
+
+// TestCheck_NotMember はロールがない場合に not_a_member の理由で拒否することをテストします。
+func TestCheck_NotMember(t *testing.T) {
+	err := Check("", ActionView)
+
+	var denial *Denial
+	assert.True(t, errors.As(err, &denial))
+	assert.Equal(t, &Denial{Reason: ReasonNotMember, Action: ActionView, RequiredRole: model.RoleViewer}, denial)
+}
+
+// TestEnforcer はリポジトリから取得したロールで判定することをテストします。
+func TestEnforcer(t *testing.T) {
+	members := new(repository.MockMemberRepository)
+	members.On("GetTaskRole", uint(1), uint(2)).Return(model.RoleEditor, nil)
+	members.On("GetProjectRole", uint(3), uint(2)).Return(model.RoleViewer, nil)
+	members.On("GetTaskRole", uint(4), uint(2)).Return("", errors.New("db error"))
+	enforcer := NewEnforcer(members)
+
+	assert.NoError(t, enforcer.AuthorizeTask(2, 1, ActionEdit))
+	assert.Error(t, enforcer.AuthorizeTask(2, 1, ActionDelete))
+	assert.Error(t, enforcer.AuthorizeProject(2, 3, ActionEdit))
+	assert.EqualError(t, enforcer.AuthorizeTask(2, 4, ActionView), "db error")
+
+	members.AssertExpectations(t)
+}
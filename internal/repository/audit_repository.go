@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/ryory2/test-go-app-todo-go/internal/model"
+	"gorm.io/gorm"
+)
+
+// AuditFilter は監査ログの絞り込み条件です（ゼロ値の条件は絞り込まない）
+type AuditFilter struct {
+	Action       string
+	ResourceType string
+	ResourceID   uint
+	Actor        string
+	RequestID    string
+	From         *time.Time
+	To           *time.Time
+	Limit        int
+	Offset       int
+}
+
+// AuditRepository は監査ログの記録と参照のみを提供します（更新・削除はできません）
+type AuditRepository interface {
+	CreateAuditLog(entry *model.AuditLog) error
+	GetAuditLogs(filter AuditFilter) ([]model.AuditLog, int64, error)
+	ForTenant(tenantID uint) AuditRepository
+}
+
+type auditRepository struct {
+	db *gorm.DB
+	// tenantID が設定されている場合は、そのワークスペースの監査ログのみを記録・参照する
+	tenantID *uint
+}
+
+func NewAuditRepository(db *gorm.DB) AuditRepository {
+	return &auditRepository{db: db}
+}
+
+// ForTenant は tenantID のワークスペースの監査ログのみを記録・参照するリポジトリを返します
+func (r *auditRepository) ForTenant(tenantID uint) AuditRepository {
+	return &auditRepository{db: r.db, tenantID: &tenantID}
+}
+
+func (r *auditRepository) CreateAuditLog(entry *model.AuditLog) error {
+	if r.tenantID != nil {
+		entry.TenantID = *r.tenantID
+	}
+	return r.db.Create(entry).Error
+}
+
+func (r *auditRepository) GetAuditLogs(filter AuditFilter) ([]model.AuditLog, int64, error) {
+	var entries []model.AuditLog
+	var total int64
+	query := r.db.Model(&model.AuditLog{}).Scopes(inTenant("audit_logs", r.tenantID))
+
+	conditions := []struct {
+		column string
+		value  interface{}
+		set    bool
+	}{
+		{"action", filter.Action, filter.Action != ""},
+		{"resource_type", filter.ResourceType, filter.ResourceType != ""},
+		{"resource_id", filter.ResourceID, filter.ResourceID != 0},
+		{"actor", filter.Actor, filter.Actor != ""},
+		{"request_id", filter.RequestID, filter.RequestID != ""},
+	}
+	for _, cond := range conditions {
+		if cond.set {
+			query = query.Where(cond.column+" = ?", cond.value)
+		}
+	}
+	if filter.From != nil {
+		query = query.Where("created_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("created_at < ?", *filter.To)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if err := query.Order("created_at DESC, id DESC").Limit(filter.Limit).Offset(filter.Offset).Find(&entries).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return entries, total, nil
+}
@@ -0,0 +1,143 @@
+package repository
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ryory2/test-go-app-todo-go/internal/model"
+	"gorm.io/gorm/clause"
+)
+
+// ErrInvalidCursor はカーソルが不正な場合、または並び順が作成時と異なる場合のエラーです
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Cursor はキーセットページングの位置です。並び替えキーの値と id を保持し、
+// Before が false の場合はその位置より後、true の場合は前のページを表します
+type Cursor struct {
+	Before bool
+	values []interface{}
+}
+
+// cursorPayload はカーソルのエンコード形式です
+type cursorPayload struct {
+	Sort   string   `json:"s"`
+	Values []string `json:"v"`
+	Before bool     `json:"b,omitempty"`
+}
+
+// newCursor はタスクの位置を指すカーソルを作成します
+func newCursor(fields []SortField, task *model.Task, before bool) *Cursor {
+	cursor := &Cursor{Before: before}
+	for _, field := range fields {
+		cursor.values = append(cursor.values, sortKeys[field.Field].value(task))
+	}
+	cursor.values = append(cursor.values, task.ID)
+	return cursor
+}
+
+// Encode はカーソルをクライアントに渡す不透明な文字列に変換します
+func (c *Cursor) Encode(fields []SortField) string {
+	payload := cursorPayload{Sort: FormatSort(fields), Before: c.Before}
+	for _, value := range c.values {
+		switch v := value.(type) {
+		case time.Time:
+			payload.Values = append(payload.Values, v.Format(time.RFC3339Nano))
+		case int:
+			payload.Values = append(payload.Values, strconv.Itoa(v))
+		case uint:
+			payload.Values = append(payload.Values, strconv.FormatUint(uint64(v), 10))
+		case string:
+			payload.Values = append(payload.Values, v)
+		}
+	}
+	data, _ := json.Marshal(payload)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeCursor はカーソル文字列を解析します。カーソル作成時と並び順が異なる場合は ErrInvalidCursor を返します
+func DecodeCursor(token string, fields []SortField) (*Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var payload cursorPayload
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return nil, ErrInvalidCursor
+	}
+	if payload.Sort != FormatSort(fields) || len(payload.Values) != len(fields)+1 {
+		return nil, ErrInvalidCursor
+	}
+
+	cursor := &Cursor{Before: payload.Before}
+	for i, field := range fields {
+		// キーの型はタスクのゼロ値から取り出した値の型で判断する
+		var value interface{}
+		switch sortKeys[field.Field].value(&model.Task{}).(type) {
+		case time.Time:
+			value, err = time.Parse(time.RFC3339Nano, payload.Values[i])
+		case int:
+			value, err = strconv.Atoi(payload.Values[i])
+		default:
+			value = payload.Values[i]
+		}
+		if err != nil {
+			return nil, ErrInvalidCursor
+		}
+		cursor.values = append(cursor.values, value)
+	}
+	id, err := strconv.ParseUint(payload.Values[len(fields)], 10, 32)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	cursor.values = append(cursor.values, uint(id))
+	return cursor, nil
+}
+
+// assignmentEventSort は担当者の変更イベントのカーソルの並び順（作成日時・id の昇順）です
+var assignmentEventSort = []SortField{{Field: "created_at"}}
+
+// NewAssignmentEventCursor は担当者の変更イベントの位置を指すカーソル文字列を返します
+func NewAssignmentEventCursor(event *model.AssignmentEvent) string {
+	cursor := &Cursor{values: []interface{}{event.CreatedAt, event.ID}}
+	return cursor.Encode(assignmentEventSort)
+}
+
+// DecodeAssignmentEventCursor は担当者の変更イベントのカーソル文字列を解析します
+func DecodeAssignmentEventCursor(token string) (*Cursor, error) {
+	return DecodeCursor(token, assignmentEventSort)
+}
+
+// where はカーソルの位置より後（Before の場合は前）の行に絞り込む条件を組み立てます。
+// (k1 > v1) OR (k1 = v1 AND k2 > v2) OR ... の形で、降順のキーは比較を逆にします
+func (c *Cursor) where(fields []SortField) clause.Expr {
+	exprs := make([]string, 0, len(fields)+1)
+	descs := make([]bool, 0, len(fields)+1)
+	for _, field := range fields {
+		exprs = append(exprs, sortKeys[field.Field].expr)
+		descs = append(descs, field.Desc)
+	}
+	exprs = append(exprs, "tasks.id")
+	descs = append(descs, false)
+
+	var conditions []string
+	var vars []interface{}
+	for i := range exprs {
+		var parts []string
+		for j := 0; j < i; j++ {
+			parts = append(parts, exprs[j]+" = ?")
+			vars = append(vars, c.values[j])
+		}
+		op := ">"
+		if descs[i] != c.Before {
+			op = "<"
+		}
+		parts = append(parts, exprs[i]+" "+op+" ?")
+		vars = append(vars, c.values[i])
+		conditions = append(conditions, "("+strings.Join(parts, " AND ")+")")
+	}
+	return clause.Expr{SQL: "(" + strings.Join(conditions, " OR ") + ")", Vars: vars}
+}
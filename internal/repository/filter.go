@@ -0,0 +1,149 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/ryory2/test-go-app-todo-go/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// TaskFilter はタスク一覧の絞り込み・並び替え・ページングの条件です
+type TaskFilter struct {
+	// Status は "completed" / "pending" で完了状態を絞り込みます（空または "all" は絞り込まない）
+	Status string
+	// Archived は "include" でアーカイブ済みのタスクも含め、"only" でアーカイブ済みのタスクのみに絞り込みます（空は除外する）
+	Archived     string
+	Tags         []string
+	MatchAllTags bool
+	Query        string
+	IDs          []uint
+	// AssigneeID はそのユーザーが担当者になっているタスクに、Unassigned は担当者がいないタスクに絞り込みます
+	AssigneeID *uint
+	Unassigned bool
+
+	DueBefore     *time.Time
+	DueAfter      *time.Time
+	CreatedBefore *time.Time
+	CreatedAfter  *time.Time
+	UpdatedBefore *time.Time
+	UpdatedAfter  *time.Time
+
+	// Overdue は期限日を過ぎた未完了のタスク（true）またはそれ以外（false）に絞り込みます
+	Overdue        *bool
+	HasDescription *bool
+
+	Sort   []SortField
+	Limit  int
+	Offset int
+	// Cursor が指定された場合はキーセットページングで位置より後（または前）のタスクを取得します
+	Cursor *Cursor
+	// SkipCount が true の場合は全件数を数えません
+	SkipCount bool
+}
+
+// TaskChange は一括で変更したタスクの変更前と変更後の状態です（削除した場合は After が nil）
+type TaskChange struct {
+	Before *model.Task
+	After  *model.Task
+}
+
+// TaskPage はタスク一覧の1ページ分の取得結果です
+type TaskPage struct {
+	Tasks []model.Task
+	// Total は条件に一致するタスクの全件数です（SkipCount の場合は 0）
+	Total int64
+	// NextCursor・PrevCursor は前後のページのカーソルです（ページがない場合は空）
+	NextCursor string
+	PrevCursor string
+}
+
+// apply は絞り込み条件をクエリに適用し、キーワード検索時のランキング式を返します
+func (f TaskFilter) apply(db, query *gorm.DB) (*gorm.DB, *clause.Expr) {
+	if f.Status == "completed" {
+		query = query.Where("tasks.is_completed = ?", true)
+	} else if f.Status == "pending" {
+		query = query.Where("tasks.is_completed = ?", false)
+	}
+
+	switch f.Archived {
+	case "include":
+	case "only":
+		query = query.Where("tasks.archived_at IS NOT NULL")
+	default:
+		query = query.Where("tasks.archived_at IS NULL")
+	}
+
+	if len(f.Tags) > 0 {
+		// タグ名に一致するタスクIDのサブクエリ
+		tagged := db.Table("tasks_tags").
+			Select("tasks_tags.task_id").
+			Joins("JOIN tags ON tags.id = tasks_tags.tag_id").
+			Where("tags.name IN ?", f.Tags).
+			Group("tasks_tags.task_id")
+		if f.MatchAllTags {
+			// 指定されたすべてのタグを持つタスクに絞り込む
+			tagged = tagged.Having("COUNT(DISTINCT tags.id) = ?", len(uniqueValues(f.Tags)))
+		}
+		query = query.Where("tasks.id IN (?)", tagged)
+	}
+
+	if f.AssigneeID != nil {
+		query = query.Where("tasks.id IN (?)", db.Model(&model.TaskAssignee{}).Select("task_id").Where("user_id = ?", *f.AssigneeID))
+	}
+	if f.Unassigned {
+		query = query.Where("NOT EXISTS (?)", db.Model(&model.TaskAssignee{}).Select("1").Where("task_assignees.task_id = tasks.id"))
+	}
+
+	if len(f.IDs) > 0 {
+		query = query.Where("tasks.id IN ?", f.IDs)
+	}
+
+	ranges := []struct {
+		column string
+		op     string
+		value  *time.Time
+	}{
+		{"tasks.due_date", "<", f.DueBefore},
+		{"tasks.due_date", ">", f.DueAfter},
+		{"tasks.created_at", "<", f.CreatedBefore},
+		{"tasks.created_at", ">", f.CreatedAfter},
+		{"tasks.updated_at", "<", f.UpdatedBefore},
+		{"tasks.updated_at", ">", f.UpdatedAfter},
+	}
+	for _, r := range ranges {
+		if r.value != nil {
+			query = query.Where(r.column+" "+r.op+" ?", *r.value)
+		}
+	}
+
+	if f.Overdue != nil {
+		// 期限日が未設定（NULL またはゼロ値）のタスクは期限切れとみなさない
+		now := time.Now()
+		today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+		overdue := gorm.Expr("tasks.is_completed = ? AND tasks.due_date > ? AND tasks.due_date < ?", false, time.Time{}, today)
+		if *f.Overdue {
+			query = query.Where(overdue)
+		} else {
+			query = query.Not(overdue)
+		}
+	}
+
+	if f.HasDescription != nil {
+		if *f.HasDescription {
+			query = query.Where("tasks.description IS NOT NULL AND tasks.description <> ''")
+		} else {
+			query = query.Where("tasks.description IS NULL OR tasks.description = ''")
+		}
+	}
+
+	// キーワード検索
+	var rank *clause.Expr
+	if f.Query != "" {
+		where, rankExpr := searchClauses(db, f.Query)
+		query = query.Where(where)
+		rank = &rankExpr
+	}
+
+	return query, rank
+}
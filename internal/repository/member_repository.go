@@ -0,0 +1,178 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/ryory2/test-go-app-todo-go/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrLastOwner は最後の owner を削除・変更しようとした場合のエラーです
+var ErrLastOwner = errors.New("cannot remove the last owner")
+
+// ErrNotWorkspaceMember はワークスペースのメンバーではないユーザーを追加しようとした場合のエラーです
+var ErrNotWorkspaceMember = errors.New("user is not a member of the workspace")
+
+type MemberRepository interface {
+	GetTaskRole(taskID, userID uint) (string, error)
+	GetProjectRole(projectID, userID uint) (string, error)
+	GetTaskMembers(taskID uint) ([]model.TaskMember, error)
+	SetTaskMember(member *model.TaskMember) error
+	RemoveTaskMember(taskID, userID uint) error
+	GetProjectMembers(projectID uint) ([]model.ProjectMember, error)
+	SetProjectMember(member *model.ProjectMember) error
+	RemoveProjectMember(projectID, userID uint) error
+	ForTenant(tenantID uint) MemberRepository
+}
+
+type memberRepository struct {
+	db *gorm.DB
+	// tenantID が設定されている場合は、そのワークスペースのタスク・プロジェクトのメンバーのみを参照する
+	tenantID *uint
+}
+
+func NewMemberRepository(db *gorm.DB) MemberRepository {
+	return &memberRepository{db: db}
+}
+
+// ForTenant は tenantID のワークスペースのタスク・プロジェクトのメンバーのみを参照するリポジトリを返します
+func (r *memberRepository) ForTenant(tenantID uint) MemberRepository {
+	return &memberRepository{db: r.db, tenantID: &tenantID}
+}
+
+func (r *memberRepository) GetTaskRole(taskID, userID uint) (string, error) {
+	// ゴミ箱のタスクも所有者は操作できる
+	var task model.Task
+	if err := r.db.Unscoped().Scopes(inTenant("tasks", r.tenantID)).Select("id", "owner_id", "project_id").First(&task, taskID).Error; err != nil {
+		return "", err
+	}
+	if task.OwnerID != nil && *task.OwnerID == userID {
+		return model.RoleOwner, nil
+	}
+
+	var roles []string
+	if err := r.db.Model(&model.TaskMember{}).Scopes(inTenant("task_members", r.tenantID)).Where("task_id = ? AND user_id = ?", taskID, userID).Pluck("role", &roles).Error; err != nil {
+		return "", err
+	}
+	if task.ProjectID != nil {
+		projectRole, err := r.GetProjectRole(*task.ProjectID, userID)
+		if err != nil {
+			return "", err
+		}
+		roles = append(roles, projectRole)
+	}
+	return model.HighestRole(roles...), nil
+}
+
+func (r *memberRepository) GetProjectRole(projectID, userID uint) (string, error) {
+	var roles []string
+	if err := r.db.Model(&model.ProjectMember{}).Scopes(inTenant("project_members", r.tenantID)).Where("project_id = ? AND user_id = ?", projectID, userID).Pluck("role", &roles).Error; err != nil {
+		return "", err
+	}
+	return model.HighestRole(roles...), nil
+}
+
+func (r *memberRepository) GetTaskMembers(taskID uint) ([]model.TaskMember, error) {
+	var members []model.TaskMember
+	if err := r.db.Preload("User").Scopes(inTenant("task_members", r.tenantID)).Where("task_id = ?", taskID).Order("user_id").Find(&members).Error; err != nil {
+		return nil, err
+	}
+	return members, nil
+}
+
+func (r *memberRepository) SetTaskMember(member *model.TaskMember) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := ensureWorkspaceMember(tx, r.tenantID, member.UserID); err != nil {
+			return err
+		}
+		return tx.Omit("User").Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "task_id"}, {Name: "user_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"role", "updated_at"}),
+		}).Create(member).Error
+	})
+}
+
+func (r *memberRepository) RemoveTaskMember(taskID, userID uint) error {
+	result := r.db.Where("task_id = ? AND user_id = ?", taskID, userID).Delete(&model.TaskMember{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *memberRepository) GetProjectMembers(projectID uint) ([]model.ProjectMember, error) {
+	var members []model.ProjectMember
+	if err := r.db.Preload("User").Scopes(inTenant("project_members", r.tenantID)).Where("project_id = ?", projectID).Order("user_id").Find(&members).Error; err != nil {
+		return nil, err
+	}
+	return members, nil
+}
+
+func (r *memberRepository) SetProjectMember(member *model.ProjectMember) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := ensureWorkspaceMember(tx, r.tenantID, member.UserID); err != nil {
+			return err
+		}
+		if member.Role != model.RoleOwner {
+			if err := ensureOtherOwner(tx, member.ProjectID, member.UserID); err != nil {
+				return err
+			}
+		}
+		return tx.Omit("User").Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "project_id"}, {Name: "user_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"role", "updated_at"}),
+		}).Create(member).Error
+	})
+}
+
+func (r *memberRepository) RemoveProjectMember(projectID, userID uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := ensureOtherOwner(tx, projectID, userID); err != nil {
+			return err
+		}
+		result := tx.Where("project_id = ? AND user_id = ?", projectID, userID).Delete(&model.ProjectMember{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return nil
+	})
+}
+
+// ensureOtherOwner は userID のメンバーが owner の場合に、プロジェクトに他の owner がいることを確認します
+func ensureOtherOwner(tx *gorm.DB, projectID, userID uint) error {
+	var owners []uint
+	err := tx.Model(&model.ProjectMember{}).Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("project_id = ? AND role = ?", projectID, model.RoleOwner).Pluck("user_id", &owners).Error
+	if err != nil {
+		return err
+	}
+	if len(owners) == 1 && owners[0] == userID {
+		return ErrLastOwner
+	}
+	return nil
+}
+
+// ensureWorkspaceMember は tenantID が設定されている場合に、ユーザーがそのワークスペースのメンバーであることを確認します
+func ensureWorkspaceMember(tx *gorm.DB, tenantID *uint, userID uint) error {
+	if tenantID == nil {
+		return nil
+	}
+	var userIDs []uint
+	// メンバーの削除と競合しないように行をロックする
+	err := tx.Model(&model.WorkspaceMember{}).Clauses(clause.Locking{Strength: "SHARE"}).
+		Where("workspace_id = ? AND user_id = ?", *tenantID, userID).Pluck("user_id", &userIDs).Error
+	if err != nil {
+		return err
+	}
+	if len(userIDs) == 0 {
+		return ErrNotWorkspaceMember
+	}
+	return nil
+}
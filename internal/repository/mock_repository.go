@@ -2,6 +2,8 @@
 package repository
 
 import (
+	"time"
+
 	"github.com/ryory2/test-go-app-todo-go/internal/model"
 	"github.com/stretchr/testify/mock"
 )
@@ -11,9 +13,14 @@ type MockTaskRepository struct {
 	mock.Mock
 }
 
-func (m *MockTaskRepository) GetTasks(status string, limit, offset int) ([]model.Task, int64, error) {
-	args := m.Called(status, limit, offset)
-	return args.Get(0).([]model.Task), args.Get(1).(int64), args.Error(2)
+func (m *MockTaskRepository) GetTasks(filter TaskFilter) (*TaskPage, error) {
+	args := m.Called(filter)
+	return args.Get(0).(*TaskPage), args.Error(1)
+}
+
+func (m *MockTaskRepository) GetRecurringTasks(filter TaskFilter, dueBefore time.Time) ([]model.Task, error) {
+	args := m.Called(filter, dueBefore)
+	return args.Get(0).([]model.Task), args.Error(1)
 }
 
 func (m *MockTaskRepository) CreateTask(task *model.Task) error {
@@ -31,12 +38,475 @@ func (m *MockTaskRepository) UpdateTask(task *model.Task) error {
 	return args.Error(0)
 }
 
-func (m *MockTaskRepository) DeleteTask(task *model.Task) error {
+func (m *MockTaskRepository) DeleteTask(task *model.Task) ([]uint, error) {
 	args := m.Called(task)
-	return args.Error(0)
+	return args.Get(0).([]uint), args.Error(1)
 }
 
 func (m *MockTaskRepository) ToggleTaskCompletion(task *model.Task) error {
 	args := m.Called(task)
 	return args.Error(0)
 }
+
+func (m *MockTaskRepository) SetTaskTags(task *model.Task, tagIDs []uint) error {
+	args := m.Called(task, tagIDs)
+	return args.Error(0)
+}
+
+func (m *MockTaskRepository) GetChildren(parentID uint) ([]model.Task, error) {
+	args := m.Called(parentID)
+	return args.Get(0).([]model.Task), args.Error(1)
+}
+
+func (m *MockTaskRepository) GetSubtree(rootID uint) ([]model.Task, error) {
+	args := m.Called(rootID)
+	return args.Get(0).([]model.Task), args.Error(1)
+}
+
+func (m *MockTaskRepository) SetTaskParent(task *model.Task, parentID *uint) error {
+	args := m.Called(task, parentID)
+	return args.Error(0)
+}
+
+func (m *MockTaskRepository) ReattachChildren(task *model.Task, childIDs []uint, from *uint) error {
+	args := m.Called(task, childIDs, from)
+	return args.Error(0)
+}
+
+func (m *MockTaskRepository) DeleteTaskTree(task *model.Task) error {
+	args := m.Called(task)
+	return args.Error(0)
+}
+
+func (m *MockTaskRepository) ToggleTaskCompletionCascade(task *model.Task) error {
+	args := m.Called(task)
+	return args.Error(0)
+}
+
+// ForUser は所有者で絞り込まず、モック自身を返します
+func (m *MockTaskRepository) ForUser(userID uint) TaskRepository {
+	m.Called(userID)
+	return m
+}
+
+// ForTenant はワークスペースで絞り込まず、モック自身を返します
+func (m *MockTaskRepository) ForTenant(tenantID uint) TaskRepository {
+	m.Called(tenantID)
+	return m
+}
+
+// Transaction はトランザクションを開始せず、モック自身を渡して fn を実行します
+func (m *MockTaskRepository) Transaction(fn func(repo TaskRepository) error) error {
+	m.Called()
+	return fn(m)
+}
+
+func (m *MockTaskRepository) GetDeletedTasks(limit, offset int) ([]model.Task, int64, error) {
+	args := m.Called(limit, offset)
+	return args.Get(0).([]model.Task), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockTaskRepository) GetDeletedTaskByID(id uint) (*model.Task, error) {
+	args := m.Called(id)
+	return args.Get(0).(*model.Task), args.Error(1)
+}
+
+func (m *MockTaskRepository) RestoreTask(task *model.Task) error {
+	args := m.Called(task)
+	return args.Error(0)
+}
+
+func (m *MockTaskRepository) PurgeTask(task *model.Task) error {
+	args := m.Called(task)
+	return args.Error(0)
+}
+
+func (m *MockTaskRepository) PurgeDeletedTasks(before time.Time) ([]model.Task, error) {
+	args := m.Called(before)
+	return args.Get(0).([]model.Task), args.Error(1)
+}
+
+func (m *MockTaskRepository) PurgeOccurrence(id uint) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockTaskRepository) SetTaskArchived(task *model.Task, archived bool) error {
+	args := m.Called(task, archived)
+	return args.Error(0)
+}
+
+func (m *MockTaskRepository) ArchiveCompletedTasks(before time.Time) ([]TaskChange, error) {
+	args := m.Called(before)
+	return args.Get(0).([]TaskChange), args.Error(1)
+}
+
+func (m *MockTaskRepository) GetCompletionHistory(taskID uint) ([]model.CompletionEvent, error) {
+	args := m.Called(taskID)
+	return args.Get(0).([]model.CompletionEvent), args.Error(1)
+}
+
+func (m *MockTaskRepository) GetRevisions(taskID uint, limit, offset int) ([]model.TaskRevision, int64, error) {
+	args := m.Called(taskID, limit, offset)
+	return args.Get(0).([]model.TaskRevision), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockTaskRepository) GetRevision(taskID, revision uint) (*model.TaskRevision, error) {
+	args := m.Called(taskID, revision)
+	return args.Get(0).(*model.TaskRevision), args.Error(1)
+}
+
+func (m *MockTaskRepository) CreateUndoToken(token *model.UndoToken) error {
+	args := m.Called(token)
+	return args.Error(0)
+}
+
+func (m *MockTaskRepository) GetUndoToken(tokenHash string) (*model.UndoToken, error) {
+	args := m.Called(tokenHash)
+	return args.Get(0).(*model.UndoToken), args.Error(1)
+}
+
+func (m *MockTaskRepository) UseUndoToken(token *model.UndoToken) error {
+	args := m.Called(token)
+	return args.Error(0)
+}
+
+func (m *MockTaskRepository) DeleteExpiredUndoTokens(before time.Time) (int64, error) {
+	args := m.Called(before)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockTaskRepository) AssignTask(task *model.Task, userIDs []uint, actorID uint) ([]model.AssignmentEvent, error) {
+	args := m.Called(task, userIDs, actorID)
+	return args.Get(0).([]model.AssignmentEvent), args.Error(1)
+}
+
+func (m *MockTaskRepository) UnassignTask(task *model.Task, userID, actorID uint) (*model.AssignmentEvent, error) {
+	args := m.Called(task, userID, actorID)
+	return args.Get(0).(*model.AssignmentEvent), args.Error(1)
+}
+
+func (m *MockTaskRepository) GetAssignmentEvents(after *Cursor, lag time.Duration, limit int) ([]model.AssignmentEvent, error) {
+	args := m.Called(after, lag, limit)
+	return args.Get(0).([]model.AssignmentEvent), args.Error(1)
+}
+
+func (m *MockTaskRepository) GetComments(taskID uint, limit, offset int) ([]model.Comment, int64, error) {
+	args := m.Called(taskID, limit, offset)
+	return args.Get(0).([]model.Comment), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockTaskRepository) GetComment(taskID, id uint) (*model.Comment, error) {
+	args := m.Called(taskID, id)
+	return args.Get(0).(*model.Comment), args.Error(1)
+}
+
+func (m *MockTaskRepository) CreateComment(comment *model.Comment) error {
+	args := m.Called(comment)
+	return args.Error(0)
+}
+
+func (m *MockTaskRepository) UpdateComment(comment *model.Comment) error {
+	args := m.Called(comment)
+	return args.Error(0)
+}
+
+func (m *MockTaskRepository) DeleteComment(comment *model.Comment) error {
+	args := m.Called(comment)
+	return args.Error(0)
+}
+
+// MockTagRepository は TagRepository インターフェースのモック実装です
+type MockTagRepository struct {
+	mock.Mock
+}
+
+func (m *MockTagRepository) GetTags() ([]model.Tag, error) {
+	args := m.Called()
+	return args.Get(0).([]model.Tag), args.Error(1)
+}
+
+func (m *MockTagRepository) CreateTag(tag *model.Tag) error {
+	args := m.Called(tag)
+	return args.Error(0)
+}
+
+func (m *MockTagRepository) GetTagByID(id uint) (*model.Tag, error) {
+	args := m.Called(id)
+	return args.Get(0).(*model.Tag), args.Error(1)
+}
+
+func (m *MockTagRepository) UpdateTag(tag *model.Tag) error {
+	args := m.Called(tag)
+	return args.Error(0)
+}
+
+func (m *MockTagRepository) DeleteTag(tag *model.Tag) error {
+	args := m.Called(tag)
+	return args.Error(0)
+}
+
+// ForTenant はワークスペースで絞り込まず、モック自身を返します
+func (m *MockTagRepository) ForTenant(tenantID uint) TagRepository {
+	m.Called(tenantID)
+	return m
+}
+
+// MockProjectRepository は ProjectRepository インターフェースのモック実装です
+type MockProjectRepository struct {
+	mock.Mock
+}
+
+// ForUser は所有者で絞り込まず、モック自身を返します
+func (m *MockProjectRepository) ForUser(userID uint) ProjectRepository {
+	m.Called(userID)
+	return m
+}
+
+// ForTenant はワークスペースで絞り込まず、モック自身を返します
+func (m *MockProjectRepository) ForTenant(tenantID uint) ProjectRepository {
+	m.Called(tenantID)
+	return m
+}
+
+func (m *MockProjectRepository) GetProjects(includeArchived bool) ([]model.Project, error) {
+	args := m.Called(includeArchived)
+	return args.Get(0).([]model.Project), args.Error(1)
+}
+
+func (m *MockProjectRepository) CreateProject(project *model.Project) error {
+	args := m.Called(project)
+	return args.Error(0)
+}
+
+func (m *MockProjectRepository) GetProjectByID(id uint) (*model.Project, error) {
+	args := m.Called(id)
+	return args.Get(0).(*model.Project), args.Error(1)
+}
+
+func (m *MockProjectRepository) UpdateProject(project *model.Project) error {
+	args := m.Called(project)
+	return args.Error(0)
+}
+
+func (m *MockProjectRepository) DeleteProject(project *model.Project, deleteTasks bool) ([]TaskChange, error) {
+	args := m.Called(project, deleteTasks)
+	return args.Get(0).([]TaskChange), args.Error(1)
+}
+
+func (m *MockProjectRepository) GetProjectTasks(projectID uint, includeArchived bool, limit, offset int) ([]model.Task, int64, error) {
+	args := m.Called(projectID, includeArchived, limit, offset)
+	return args.Get(0).([]model.Task), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockProjectRepository) MoveTasks(taskIDs []uint, projectID *uint) ([]TaskChange, error) {
+	args := m.Called(taskIDs, projectID)
+	return args.Get(0).([]TaskChange), args.Error(1)
+}
+
+func (m *MockProjectRepository) RemoveTask(projectID, taskID uint) (*TaskChange, error) {
+	args := m.Called(projectID, taskID)
+	return args.Get(0).(*TaskChange), args.Error(1)
+}
+
+// MockAuditRepository は AuditRepository インターフェースのモック実装です
+type MockAuditRepository struct {
+	mock.Mock
+}
+
+func (m *MockAuditRepository) CreateAuditLog(entry *model.AuditLog) error {
+	args := m.Called(entry)
+	return args.Error(0)
+}
+
+func (m *MockAuditRepository) GetAuditLogs(filter AuditFilter) ([]model.AuditLog, int64, error) {
+	args := m.Called(filter)
+	return args.Get(0).([]model.AuditLog), args.Get(1).(int64), args.Error(2)
+}
+
+// ForTenant はワークスペースで絞り込まず、モック自身を返します
+func (m *MockAuditRepository) ForTenant(tenantID uint) AuditRepository {
+	m.Called(tenantID)
+	return m
+}
+
+// MockUserRepository は UserRepository インターフェースのモック実装です
+type MockUserRepository struct {
+	mock.Mock
+}
+
+func (m *MockUserRepository) CreateUser(user *model.User, workspaceSlug string) error {
+	args := m.Called(user, workspaceSlug)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) GetUserByID(id uint) (*model.User, error) {
+	args := m.Called(id)
+	return args.Get(0).(*model.User), args.Error(1)
+}
+
+func (m *MockUserRepository) GetUserByEmail(email string) (*model.User, error) {
+	args := m.Called(email)
+	return args.Get(0).(*model.User), args.Error(1)
+}
+
+func (m *MockUserRepository) CreateSession(session *model.Session) error {
+	args := m.Called(session)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) GetSession(tokenHash string) (*model.Session, error) {
+	args := m.Called(tokenHash)
+	return args.Get(0).(*model.Session), args.Error(1)
+}
+
+func (m *MockUserRepository) DeleteSession(session *model.Session) error {
+	args := m.Called(session)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) DeleteExpiredSessions(before time.Time) (int64, error) {
+	args := m.Called(before)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockUserRepository) RevokeToken(token *model.RevokedToken) error {
+	args := m.Called(token)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) IsTokenRevoked(jti string) (bool, error) {
+	args := m.Called(jti)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockUserRepository) DeleteExpiredRevokedTokens(before time.Time) (int64, error) {
+	args := m.Called(before)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockUserRepository) CreatePersonalAccessToken(token *model.PersonalAccessToken) error {
+	args := m.Called(token)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) GetPersonalAccessTokens(userID uint) ([]model.PersonalAccessToken, error) {
+	args := m.Called(userID)
+	return args.Get(0).([]model.PersonalAccessToken), args.Error(1)
+}
+
+func (m *MockUserRepository) GetPersonalAccessToken(tokenHash string) (*model.PersonalAccessToken, error) {
+	args := m.Called(tokenHash)
+	return args.Get(0).(*model.PersonalAccessToken), args.Error(1)
+}
+
+func (m *MockUserRepository) DeletePersonalAccessToken(userID, id uint) error {
+	args := m.Called(userID, id)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) TouchPersonalAccessToken(token *model.PersonalAccessToken, usedAt time.Time) error {
+	args := m.Called(token, usedAt)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) ClaimLegacyData(user *model.User) (int64, error) {
+	args := m.Called(user)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+// MockMemberRepository は MemberRepository インターフェースのモック実装です
+type MockMemberRepository struct {
+	mock.Mock
+}
+
+func (m *MockMemberRepository) GetTaskRole(taskID, userID uint) (string, error) {
+	args := m.Called(taskID, userID)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockMemberRepository) GetProjectRole(projectID, userID uint) (string, error) {
+	args := m.Called(projectID, userID)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockMemberRepository) GetTaskMembers(taskID uint) ([]model.TaskMember, error) {
+	args := m.Called(taskID)
+	return args.Get(0).([]model.TaskMember), args.Error(1)
+}
+
+func (m *MockMemberRepository) SetTaskMember(member *model.TaskMember) error {
+	args := m.Called(member)
+	return args.Error(0)
+}
+
+func (m *MockMemberRepository) RemoveTaskMember(taskID, userID uint) error {
+	args := m.Called(taskID, userID)
+	return args.Error(0)
+}
+
+func (m *MockMemberRepository) GetProjectMembers(projectID uint) ([]model.ProjectMember, error) {
+	args := m.Called(projectID)
+	return args.Get(0).([]model.ProjectMember), args.Error(1)
+}
+
+func (m *MockMemberRepository) SetProjectMember(member *model.ProjectMember) error {
+	args := m.Called(member)
+	return args.Error(0)
+}
+
+func (m *MockMemberRepository) RemoveProjectMember(projectID, userID uint) error {
+	args := m.Called(projectID, userID)
+	return args.Error(0)
+}
+
+// ForTenant はワークスペースで絞り込まず、モック自身を返します
+func (m *MockMemberRepository) ForTenant(tenantID uint) MemberRepository {
+	m.Called(tenantID)
+	return m
+}
+
+// MockWorkspaceRepository は WorkspaceRepository インターフェースのモック実装です
+type MockWorkspaceRepository struct {
+	mock.Mock
+}
+
+func (m *MockWorkspaceRepository) GetWorkspaces(userID uint) ([]model.Workspace, error) {
+	args := m.Called(userID)
+	return args.Get(0).([]model.Workspace), args.Error(1)
+}
+
+func (m *MockWorkspaceRepository) CreateWorkspace(workspace *model.Workspace, adminID uint) error {
+	args := m.Called(workspace, adminID)
+	return args.Error(0)
+}
+
+func (m *MockWorkspaceRepository) GetWorkspaceBySlug(slug string) (*model.Workspace, error) {
+	args := m.Called(slug)
+	return args.Get(0).(*model.Workspace), args.Error(1)
+}
+
+func (m *MockWorkspaceRepository) UpdateWorkspace(workspace *model.Workspace) error {
+	args := m.Called(workspace)
+	return args.Error(0)
+}
+
+func (m *MockWorkspaceRepository) GetWorkspaceRole(workspaceID, userID uint) (string, error) {
+	args := m.Called(workspaceID, userID)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockWorkspaceRepository) GetWorkspaceMembers(workspaceID uint) ([]model.WorkspaceMember, error) {
+	args := m.Called(workspaceID)
+	return args.Get(0).([]model.WorkspaceMember), args.Error(1)
+}
+
+func (m *MockWorkspaceRepository) SetWorkspaceMember(member *model.WorkspaceMember) error {
+	args := m.Called(member)
+	return args.Error(0)
+}
+
+func (m *MockWorkspaceRepository) RemoveWorkspaceMember(workspaceID, userID uint) error {
+	args := m.Called(workspaceID, userID)
+	return args.Error(0)
+}
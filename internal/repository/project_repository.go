@@ -0,0 +1,198 @@
+package repository
+
+import (
+	"github.com/ryory2/test-go-app-todo-go/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ProjectRepository interface {
+	GetProjects(includeArchived bool) ([]model.Project, error)
+	CreateProject(project *model.Project) error
+	GetProjectByID(id uint) (*model.Project, error)
+	UpdateProject(project *model.Project) error
+	DeleteProject(project *model.Project, deleteTasks bool) ([]TaskChange, error)
+	GetProjectTasks(projectID uint, includeArchived bool, limit, offset int) ([]model.Task, int64, error)
+	MoveTasks(taskIDs []uint, projectID *uint) ([]TaskChange, error)
+	RemoveTask(projectID, taskID uint) (*TaskChange, error)
+	ForUser(userID uint) ProjectRepository
+	ForTenant(tenantID uint) ProjectRepository
+}
+
+type projectRepository struct {
+	db *gorm.DB
+	// tenantID が設定されている場合は、そのワークスペースのプロジェクトとタスクのみを操作する
+	tenantID *uint
+	// ownerID が設定されている場合は、プロジェクト内のタスクのうちそのユーザーが所有するもの（参照はメンバーとして共有されたものを含む）のみを操作し、
+	// プロジェクトの一覧はそのユーザーがメンバーになっているもののみを返す
+	ownerID *uint
+}
+
+func NewProjectRepository(db *gorm.DB) ProjectRepository {
+	return &projectRepository{db: db}
+}
+
+// ForUser はプロジェクト内のタスクの操作を userID のユーザーが所有するタスクに絞り込んだリポジトリを返します
+func (r *projectRepository) ForUser(userID uint) ProjectRepository {
+	return &projectRepository{db: r.db, tenantID: r.tenantID, ownerID: &userID}
+}
+
+// ForTenant は tenantID のワークスペースのプロジェクトとタスクのみを操作するリポジトリを返します
+func (r *projectRepository) ForTenant(tenantID uint) ProjectRepository {
+	return &projectRepository{db: r.db, tenantID: &tenantID, ownerID: r.ownerID}
+}
+
+func (r *projectRepository) GetProjects(includeArchived bool) ([]model.Project, error) {
+	var projects []model.Project
+	query := r.db.Scopes(inTenant("projects", r.tenantID), memberOf(r.ownerID)).Order("id")
+	if !includeArchived {
+		query = query.Where("archived_at IS NULL")
+	}
+	if err := query.Find(&projects).Error; err != nil {
+		return nil, err
+	}
+	return projects, nil
+}
+
+func (r *projectRepository) CreateProject(project *model.Project) error {
+	if r.tenantID != nil {
+		project.TenantID = *r.tenantID
+	}
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(project).Error; err != nil {
+			return err
+		}
+		// 作成したユーザーをプロジェクトの owner にする
+		if r.ownerID == nil {
+			return nil
+		}
+		return tx.Create(&model.ProjectMember{ProjectID: project.ID, UserID: *r.ownerID, Role: model.RoleOwner}).Error
+	})
+}
+
+func (r *projectRepository) GetProjectByID(id uint) (*model.Project, error) {
+	var project model.Project
+	if err := r.db.Scopes(inTenant("projects", r.tenantID)).First(&project, id).Error; err != nil {
+		return nil, err
+	}
+	return &project, nil
+}
+
+func (r *projectRepository) UpdateProject(project *model.Project) error {
+	return r.db.Save(project).Error
+}
+
+func (r *projectRepository) DeleteProject(project *model.Project, deleteTasks bool) ([]TaskChange, error) {
+	var tasks []model.Task
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		// プロジェクトの削除は owner のみに許可しているため、他のメンバーが所有するタスクやゴミ箱のタスクも対象にする
+		// （外部キーの ON DELETE SET NULL に任せるとバージョンが進まず、監査ログにも記録されない）
+		err := tx.Unscoped().Clauses(clause.Locking{Strength: "UPDATE"}).Scopes(inTenant("tasks", r.tenantID)).
+			Where("project_id = ?", project.ID).Order("id").Find(&tasks).Error
+		if err != nil {
+			return err
+		}
+		if len(tasks) > 0 {
+			ids := taskIDs(tasks)
+			if err := tx.Unscoped().Model(&model.Task{}).Where("id IN ?", ids).
+				Updates(map[string]interface{}{"project_id": nil, "version": gorm.Expr("version + 1")}).Error; err != nil {
+				return err
+			}
+			// ゴミ箱にないタスクのみ削除する（ゴミ箱のタスクはプロジェクトから外すのみ）
+			if deleteTasks {
+				if err := tx.Where("id IN ?", ids).Delete(&model.Task{}).Error; err != nil {
+					return err
+				}
+			}
+		}
+		return tx.Delete(project).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make([]TaskChange, 0, len(tasks))
+	for i := range tasks {
+		apply := func(task *model.Task) { task.ProjectID = nil }
+		if deleteTasks && !tasks[i].DeletedAt.Valid {
+			apply = nil
+		}
+		changes = append(changes, taskChanges(tasks[i:i+1], apply)...)
+	}
+	return changes, nil
+}
+
+func (r *projectRepository) GetProjectTasks(projectID uint, includeArchived bool, limit, offset int) ([]model.Task, int64, error) {
+	var tasks []model.Task
+	var total int64
+	query := r.db.Model(&model.Task{}).Scopes(inTenant("tasks", r.tenantID), visibleTo(r.ownerID)).Where("project_id = ?", projectID)
+	if !includeArchived {
+		query = query.Where("archived_at IS NULL")
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if err := query.Preload("Tags").Preload("Assignees").Order("id").Limit(limit).Offset(offset).Find(&tasks).Error; err != nil {
+		return nil, 0, err
+	}
+	if err := fillComputedFields(r.db, tasks); err != nil {
+		return nil, 0, err
+	}
+
+	return tasks, total, nil
+}
+
+func (r *projectRepository) MoveTasks(ids []uint, projectID *uint) ([]TaskChange, error) {
+	ids = uniqueValues(ids)
+	var tasks []model.Task
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := r.lockTasks(tx, "id IN ?", ids).Find(&tasks).Error; err != nil {
+			return err
+		}
+		// 存在しないタスクが含まれている場合はすべてロールバックする
+		if len(tasks) != len(ids) {
+			return gorm.ErrRecordNotFound
+		}
+		return tx.Model(&model.Task{}).Where("id IN ?", ids).
+			Updates(map[string]interface{}{"project_id": projectID, "version": gorm.Expr("version + 1")}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return taskChanges(tasks, func(task *model.Task) { task.ProjectID = projectID }), nil
+}
+
+func (r *projectRepository) RemoveTask(projectID, taskID uint) (*TaskChange, error) {
+	var task model.Task
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := r.lockTasks(tx, "id = ? AND project_id = ?", taskID, projectID).First(&task).Error; err != nil {
+			return err
+		}
+		return tx.Model(&model.Task{}).Where("id = ?", task.ID).
+			Updates(map[string]interface{}{"project_id": nil, "version": gorm.Expr("version + 1")}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	change := taskChanges([]model.Task{task}, func(task *model.Task) { task.ProjectID = nil })[0]
+	return &change, nil
+}
+
+// memberOf は userID のユーザーがメンバーになっているプロジェクトに絞り込むスコープを返します（userID が nil の場合は絞り込まない）
+func memberOf(userID *uint) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if userID == nil {
+			return db
+		}
+		return db.Where("projects.id IN (?)", db.Session(&gorm.Session{NewDB: true}).
+			Model(&model.ProjectMember{}).Select("project_id").Where("user_id = ?", *userID))
+	}
+}
+
+// lockTasks は条件に一致する、リポジトリのユーザーが所有するタスクを行ロックして取得するクエリを返します
+func (r *projectRepository) lockTasks(tx *gorm.DB, query string, args ...interface{}) *gorm.DB {
+	return tx.Clauses(clause.Locking{Strength: "UPDATE"}).Scopes(inTenant("tasks", r.tenantID), ownedBy(r.ownerID)).
+		Where(query, args...).Order("id")
+}
@@ -1,70 +1,946 @@
 package repository
 
 import (
+	"errors"
+	"slices"
+	"time"
+
 	"github.com/ryory2/test-go-app-todo-go/internal/model"
+	"github.com/ryory2/test-go-app-todo-go/pkg/rrule"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+var ErrTagNotFound = errors.New("tag not found")
+
+// ErrVersionConflict はタスクが読み込み後に他の更新で変更されていた場合のエラーです
+var ErrVersionConflict = errors.New("task version conflict")
+
+// ErrUndoTokenUsed は取り消しトークンが使用済みまたは期限切れの場合のエラーです
+var ErrUndoTokenUsed = errors.New("undo token already used or expired")
+
 type TaskRepository interface {
-	GetTasks(status string, limit, offset int) ([]model.Task, int64, error)
+	GetTasks(filter TaskFilter) (*TaskPage, error)
+	GetRecurringTasks(filter TaskFilter, dueBefore time.Time) ([]model.Task, error)
 	CreateTask(task *model.Task) error
 	GetTaskByID(id uint) (*model.Task, error)
 	UpdateTask(task *model.Task) error
-	DeleteTask(task *model.Task) error
+	DeleteTask(task *model.Task) ([]uint, error)
 	ToggleTaskCompletion(task *model.Task) error
+	SetTaskTags(task *model.Task, tagIDs []uint) error
+	GetChildren(parentID uint) ([]model.Task, error)
+	GetSubtree(rootID uint) ([]model.Task, error)
+	SetTaskParent(task *model.Task, parentID *uint) error
+	ReattachChildren(task *model.Task, childIDs []uint, from *uint) error
+	DeleteTaskTree(task *model.Task) error
+	ToggleTaskCompletionCascade(task *model.Task) error
+	Transaction(fn func(repo TaskRepository) error) error
+	GetDeletedTasks(limit, offset int) ([]model.Task, int64, error)
+	GetDeletedTaskByID(id uint) (*model.Task, error)
+	RestoreTask(task *model.Task) error
+	PurgeTask(task *model.Task) error
+	PurgeDeletedTasks(before time.Time) ([]model.Task, error)
+	PurgeOccurrence(id uint) error
+	SetTaskArchived(task *model.Task, archived bool) error
+	ArchiveCompletedTasks(before time.Time) ([]TaskChange, error)
+	GetCompletionHistory(taskID uint) ([]model.CompletionEvent, error)
+	GetRevisions(taskID uint, limit, offset int) ([]model.TaskRevision, int64, error)
+	GetRevision(taskID, revision uint) (*model.TaskRevision, error)
+	CreateUndoToken(token *model.UndoToken) error
+	GetUndoToken(tokenHash string) (*model.UndoToken, error)
+	UseUndoToken(token *model.UndoToken) error
+	DeleteExpiredUndoTokens(before time.Time) (int64, error)
+	AssignTask(task *model.Task, userIDs []uint, actorID uint) ([]model.AssignmentEvent, error)
+	UnassignTask(task *model.Task, userID, actorID uint) (*model.AssignmentEvent, error)
+	GetAssignmentEvents(after *Cursor, lag time.Duration, limit int) ([]model.AssignmentEvent, error)
+	GetComments(taskID uint, limit, offset int) ([]model.Comment, int64, error)
+	GetComment(taskID, id uint) (*model.Comment, error)
+	CreateComment(comment *model.Comment) error
+	UpdateComment(comment *model.Comment) error
+	DeleteComment(comment *model.Comment) error
+	ForUser(userID uint) TaskRepository
+	ForTenant(tenantID uint) TaskRepository
 }
 
 type taskRepository struct {
 	db *gorm.DB
+	// tenantID が設定されている場合は、そのワークスペースのタスクのみを操作する
+	tenantID *uint
+	// ownerID が設定されている場合は、そのユーザーが参照できるタスク（ゴミ箱の操作などは所有するタスク）のみを操作する
+	ownerID *uint
 }
 
+// NewTaskRepository はすべてのユーザーのタスクを操作するリポジトリを返します（定期的な削除処理など向け）。
+// リクエストの処理では ForUser で認証済みのユーザーに絞り込んだリポジトリを使用します
 func NewTaskRepository(db *gorm.DB) TaskRepository {
-	return &taskRepository{db}
+	return &taskRepository{db: db}
+}
+
+// ForUser は userID のユーザーが所有するタスク、または共有されたタスクのみを操作するリポジトリを返します
+func (r *taskRepository) ForUser(userID uint) TaskRepository {
+	return &taskRepository{db: r.db, tenantID: r.tenantID, ownerID: &userID}
+}
+
+// ForTenant は tenantID のワークスペースのタスクのみを操作するリポジトリを返します
+func (r *taskRepository) ForTenant(tenantID uint) TaskRepository {
+	return &taskRepository{db: r.db, tenantID: &tenantID, ownerID: r.ownerID}
+}
+
+func (r *taskRepository) GetTasks(filter TaskFilter) (*TaskPage, error) {
+	page := &TaskPage{}
+	query, rank := filter.apply(r.db, r.db.Model(&model.Task{}).Scopes(r.visible))
+
+	// 全件数はカーソルの位置に関係なく、絞り込み条件に一致する件数とする
+	if !filter.SkipCount {
+		if err := query.Count(&page.Total).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	before := filter.Cursor != nil && filter.Cursor.Before
+	if filter.Cursor != nil {
+		query = query.Where(filter.Cursor.where(filter.Sort))
+	}
+	// 前のページは逆順に取得してから並べ直す
+	query = query.Order(orderBy(filter.Sort, rank, before))
+
+	// 続きのページがあるかを判定するため1件多く取得する
+	if err := query.Preload("Tags").Preload("Assignees").Limit(filter.Limit + 1).Offset(filter.Offset).Find(&page.Tasks).Error; err != nil {
+		return nil, err
+	}
+	hasMore := len(page.Tasks) > filter.Limit
+	if hasMore {
+		page.Tasks = page.Tasks[:filter.Limit]
+	}
+	if before {
+		slices.Reverse(page.Tasks)
+	}
+
+	if err := fillComputedFields(r.db, page.Tasks); err != nil {
+		return nil, err
+	}
+
+	// 関連度順はキーセットページングに対応しないため、カーソルを返さない
+	if len(page.Tasks) > 0 && (rank == nil || len(filter.Sort) > 0) {
+		first, last := &page.Tasks[0], &page.Tasks[len(page.Tasks)-1]
+		if hasMore || before {
+			page.NextCursor = newCursor(filter.Sort, last, false).Encode(filter.Sort)
+		}
+		if (before && hasMore) || (!before && (filter.Cursor != nil || filter.Offset > 0)) {
+			page.PrevCursor = newCursor(filter.Sort, first, true).Encode(filter.Sort)
+		}
+	}
+
+	return page, nil
+}
+
+// GetRecurringTasks は絞り込み条件に一致する未完了の繰り返しタスクのうち、期限日が dueBefore より前のものを返します。
+// 発生予定の展開に使うため、ページング・並び順の条件は無視します
+func (r *taskRepository) GetRecurringTasks(filter TaskFilter, dueBefore time.Time) ([]model.Task, error) {
+	var tasks []model.Task
+	query, _ := filter.apply(r.db, r.db.Model(&model.Task{}).Scopes(r.visible))
+	err := query.Where("tasks.rrule <> '' AND tasks.is_completed = ? AND tasks.due_date < ?", false, dueBefore).
+		Order("tasks.id").Find(&tasks).Error
+	if err != nil {
+		return nil, err
+	}
+	return tasks, nil
+}
+
+func (r *taskRepository) CreateTask(task *model.Task) error {
+	if r.ownerID != nil {
+		task.OwnerID = r.ownerID
+	}
+	if r.tenantID != nil {
+		task.TenantID = *r.tenantID
+	}
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(task).Error; err != nil {
+			return err
+		}
+		// 作成時の内容を最初のリビジョンとして保存する
+		return tx.Create(model.NewTaskRevision(task)).Error
+	})
+}
+
+func (r *taskRepository) GetTaskByID(id uint) (*model.Task, error) {
+	var task model.Task
+	if err := r.db.Scopes(r.visible).Preload("Tags").Preload("Assignees").First(&task, id).Error; err != nil {
+		return nil, err
+	}
+	tasks := []model.Task{task}
+	if err := fillComputedFields(r.db, tasks); err != nil {
+		return nil, err
+	}
+	return &tasks[0], nil
+}
+
+func (r *taskRepository) UpdateTask(task *model.Task) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := saveTask(tx, task); err != nil {
+			return err
+		}
+		// 更新後の内容をリビジョンとして保存する
+		return tx.Create(model.NewTaskRevision(task)).Error
+	})
+}
+
+// DeleteTask はタスクをゴミ箱に移動し、親を付け替えた子タスク（ユーザーが参照できないものを含む）の ID を返します
+func (r *taskRepository) DeleteTask(task *model.Task) ([]uint, error) {
+	var childIDs []uint
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		// 子タスクは削除するタスクの親へ付け替える
+		if err := tx.Model(&model.Task{}).Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("parent_id = ?", task.ID).Order("id").Pluck("id", &childIDs).Error; err != nil {
+			return err
+		}
+		if len(childIDs) > 0 {
+			if err := tx.Model(&model.Task{}).Where("id IN ?", childIDs).
+				Updates(map[string]interface{}{"parent_id": task.ParentID, "version": gorm.Expr("version + 1")}).Error; err != nil {
+				return err
+			}
+		}
+		return deleteTask(tx, task)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return childIDs, nil
+}
+
+func (r *taskRepository) ToggleTaskCompletion(task *model.Task) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		task.IsCompleted = !task.IsCompleted
+		return saveTask(tx, task)
+	})
+}
+
+func (r *taskRepository) ToggleTaskCompletionCascade(task *model.Task) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		task.IsCompleted = !task.IsCompleted
+		if err := saveTask(tx, task); err != nil {
+			return err
+		}
+		// 完了にした場合のみ子孫タスクもすべて完了にする
+		if !task.IsCompleted {
+			return nil
+		}
+		descendants := tx.Model(&model.Task{}).
+			Where("id IN (?)", subtreeIDs(tx, task.ID)).
+			Where("id <> ? AND is_completed = ?", task.ID, false)
+
+		// 未完了だった子孫タスクの完了履歴を記録する
+		err := tx.Exec("INSERT INTO completion_events (task_id, action, created_at) ?",
+			descendants.Session(&gorm.Session{}).Select("id, ?, ?", model.CompletionActionCompleted, task.CompletedAt)).Error
+		if err != nil {
+			return err
+		}
+		return descendants.
+			Updates(map[string]interface{}{"is_completed": true, "completed_at": task.CompletedAt, "updated_at": task.UpdatedAt, "version": gorm.Expr("version + 1")}).Error
+	})
+}
+
+func (r *taskRepository) SetTaskTags(task *model.Task, tagIDs []uint) error {
+	var tags []model.Tag
+	if len(tagIDs) > 0 {
+		if err := r.db.Scopes(inTenant("tags", r.tenantID)).Find(&tags, tagIDs).Error; err != nil {
+			return err
+		}
+	}
+	if len(tags) != len(uniqueValues(tagIDs)) {
+		return ErrTagNotFound
+	}
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		association := tx.Model(task).Association("Tags")
+		if len(tags) == 0 {
+			if err := association.Clear(); err != nil {
+				return err
+			}
+		} else if err := association.Replace(tags); err != nil {
+			return err
+		}
+		// タグの変更もタスクの更新としてバージョンを進める
+		return bumpVersion(tx, task, nil)
+	})
+	if err != nil {
+		return err
+	}
+	task.Tags = tags
+	return nil
+}
+
+func (r *taskRepository) GetChildren(parentID uint) ([]model.Task, error) {
+	var tasks []model.Task
+	if err := r.db.Scopes(r.visible).Preload("Tags").Preload("Assignees").Where("parent_id = ?", parentID).Order("id").Find(&tasks).Error; err != nil {
+		return nil, err
+	}
+	if err := fillComputedFields(r.db, tasks); err != nil {
+		return nil, err
+	}
+	return tasks, nil
 }
 
-func (r *taskRepository) GetTasks(status string, limit, offset int) ([]model.Task, int64, error) {
+func (r *taskRepository) GetSubtree(rootID uint) ([]model.Task, error) {
 	var tasks []model.Task
+	if err := r.db.Scopes(r.visible).Preload("Tags").Preload("Assignees").Where("id IN (?)", subtreeIDs(r.db, rootID)).Order("id").Find(&tasks).Error; err != nil {
+		return nil, err
+	}
+	if err := fillComputedFields(r.db, tasks); err != nil {
+		return nil, err
+	}
+	return tasks, nil
+}
+
+func (r *taskRepository) SetTaskParent(task *model.Task, parentID *uint) error {
+	if err := bumpVersion(r.db, task, map[string]interface{}{"parent_id": parentID}); err != nil {
+		return err
+	}
+	task.ParentID = parentID
+	return nil
+}
+
+// ReattachChildren は childIDs の子タスクのうち、親が from のままのものを task の子タスクに戻します。
+// 削除の取り消しで使うため、ユーザーが参照できない子タスクも対象にします
+func (r *taskRepository) ReattachChildren(task *model.Task, childIDs []uint, from *uint) error {
+	if len(childIDs) == 0 {
+		return nil
+	}
+	query := r.db.Model(&model.Task{}).Scopes(inTenant("tasks", r.tenantID)).Where("id IN ?", childIDs)
+	if from == nil {
+		query = query.Where("parent_id IS NULL")
+	} else {
+		query = query.Where("parent_id = ?", *from)
+	}
+	return query.Updates(map[string]interface{}{"parent_id": task.ID, "version": gorm.Expr("version + 1")}).Error
+}
+
+func (r *taskRepository) SetTaskArchived(task *model.Task, archived bool) error {
+	now := time.Now()
+	var archivedAt *time.Time
+	if archived {
+		archivedAt = &now
+	}
+	if err := bumpVersion(r.db, task, map[string]interface{}{"archived_at": archivedAt, "updated_at": now}); err != nil {
+		return err
+	}
+	task.ArchivedAt = archivedAt
+	task.UpdatedAt = now
+	return nil
+}
+
+func (r *taskRepository) ArchiveCompletedTasks(before time.Time) ([]TaskChange, error) {
+	now := time.Now()
+	var tasks []model.Task
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Scopes(r.owned).
+			Where("is_completed = ? AND archived_at IS NULL AND completed_at < ?", true, before).
+			Order("id").Find(&tasks).Error
+		if err != nil || len(tasks) == 0 {
+			return err
+		}
+		return tx.Model(&model.Task{}).Where("id IN ?", taskIDs(tasks)).
+			Updates(map[string]interface{}{"archived_at": now, "updated_at": now, "version": gorm.Expr("version + 1")}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return taskChanges(tasks, func(task *model.Task) {
+		task.ArchivedAt = &now
+		task.UpdatedAt = now
+	}), nil
+}
+
+func (r *taskRepository) GetCompletionHistory(taskID uint) ([]model.CompletionEvent, error) {
+	var events []model.CompletionEvent
+	if err := r.db.Scopes(r.visibleTask).Where("task_id = ?", taskID).Order("created_at, id").Find(&events).Error; err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+func (r *taskRepository) GetRevisions(taskID uint, limit, offset int) ([]model.TaskRevision, int64, error) {
+	var revisions []model.TaskRevision
 	var total int64
-	query := r.db.Model(&model.Task{})
+	query := r.db.Model(&model.TaskRevision{}).Scopes(r.visibleTask).Where("task_id = ?", taskID)
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if err := query.Order("revision DESC").Limit(limit).Offset(offset).Find(&revisions).Error; err != nil {
+		return nil, 0, err
+	}
 
-	if status == "completed" {
-		query = query.Where("is_completed = ?", true)
-	} else if status == "pending" {
-		query = query.Where("is_completed = ?", false)
+	return revisions, total, nil
+}
+
+func (r *taskRepository) GetRevision(taskID, revision uint) (*model.TaskRevision, error) {
+	var rev model.TaskRevision
+	if err := r.db.Scopes(r.visibleTask).Where("task_id = ? AND revision = ?", taskID, revision).First(&rev).Error; err != nil {
+		return nil, err
 	}
+	return &rev, nil
+}
+
+func (r *taskRepository) CreateUndoToken(token *model.UndoToken) error {
+	return r.db.Create(token).Error
+}
+
+func (r *taskRepository) GetUndoToken(tokenHash string) (*model.UndoToken, error) {
+	var token model.UndoToken
+	if err := r.db.Scopes(r.visibleTask).Where("token_hash = ?", tokenHash).First(&token).Error; err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+func (r *taskRepository) UseUndoToken(token *model.UndoToken) error {
+	// 同じトークンで同時に取り消した場合も1回のみ成功する
+	now := time.Now()
+	result := r.db.Model(token).Where("used_at IS NULL AND expires_at > ?", now).Update("used_at", now)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrUndoTokenUsed
+	}
+	return nil
+}
+
+func (r *taskRepository) DeleteExpiredUndoTokens(before time.Time) (int64, error) {
+	result := r.db.Where("expires_at < ?", before).Delete(&model.UndoToken{})
+	return result.RowsAffected, result.Error
+}
+
+func (r *taskRepository) AssignTask(task *model.Task, userIDs []uint, actorID uint) ([]model.AssignmentEvent, error) {
+	var events []model.AssignmentEvent
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		// すでに担当者になっているユーザーは追加しない
+		var assigned []uint
+		if err := tx.Model(&model.TaskAssignee{}).Where("task_id = ?", task.ID).Pluck("user_id", &assigned).Error; err != nil {
+			return err
+		}
+		var assignees []model.TaskAssignee
+		for _, userID := range uniqueValues(userIDs) {
+			if slices.Contains(assigned, userID) {
+				continue
+			}
+			assignees = append(assignees, model.TaskAssignee{TaskID: task.ID, UserID: userID})
+			events = append(events, model.AssignmentEvent{TaskID: task.ID, UserID: userID, Action: model.AssignmentActionAssigned, ActorID: &actorID})
+		}
+		if len(assignees) == 0 {
+			return nil
+		}
+		if err := tx.Create(&assignees).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(&events).Error; err != nil {
+			return err
+		}
+		// 担当者の変更もタスクの更新としてバージョンを進める
+		return bumpVersion(tx, task, nil)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := loadAssignees(r.db, task); err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+func (r *taskRepository) UnassignTask(task *model.Task, userID, actorID uint) (*model.AssignmentEvent, error) {
+	event := &model.AssignmentEvent{TaskID: task.ID, UserID: userID, Action: model.AssignmentActionUnassigned, ActorID: &actorID}
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("task_id = ? AND user_id = ?", task.ID, userID).Delete(&model.TaskAssignee{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		if err := tx.Create(event).Error; err != nil {
+			return err
+		}
+		return bumpVersion(tx, task, nil)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := loadAssignees(r.db, task); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+// GetAssignmentEvents は after より後の担当者の変更イベントを作成日時・id の順に返します。
+// id・作成日時の順とコミットの順は一致しないため、作成から lag が経過していないイベントは返さず、後からコミットされたイベントを読み飛ばさないようにします
+func (r *taskRepository) GetAssignmentEvents(after *Cursor, lag time.Duration, limit int) ([]model.AssignmentEvent, error) {
+	var events []model.AssignmentEvent
+	// 作成日時と同じくデータベースの時計と比較する
+	query := r.db.Scopes(r.visibleTask).Where("created_at < clock_timestamp() - make_interval(secs => ?)", lag.Seconds())
+	if after != nil {
+		query = query.Where("(created_at, id) > (?, ?)", after.values...)
+	}
+	if err := query.Order("created_at, id").Limit(limit).Find(&events).Error; err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+func (r *taskRepository) GetComments(taskID uint, limit, offset int) ([]model.Comment, int64, error) {
+	var comments []model.Comment
+	var total int64
+	query := r.db.Model(&model.Comment{}).Scopes(r.visibleTask).Where("task_id = ?", taskID)
 
 	if err := query.Count(&total).Error; err != nil {
 		return nil, 0, err
 	}
 
-	if err := query.Limit(limit).Offset(offset).Find(&tasks).Error; err != nil {
+	if err := query.Preload("Author").Order("created_at, id").Limit(limit).Offset(offset).Find(&comments).Error; err != nil {
 		return nil, 0, err
 	}
 
-	return tasks, total, nil
+	return comments, total, nil
 }
 
-func (r *taskRepository) CreateTask(task *model.Task) error {
-	return r.db.Create(task).Error
+func (r *taskRepository) GetComment(taskID, id uint) (*model.Comment, error) {
+	var comment model.Comment
+	if err := r.db.Scopes(r.visibleTask).Preload("Author").Where("task_id = ?", taskID).First(&comment, id).Error; err != nil {
+		return nil, err
+	}
+	return &comment, nil
 }
 
-func (r *taskRepository) GetTaskByID(id uint) (*model.Task, error) {
+func (r *taskRepository) CreateComment(comment *model.Comment) error {
+	if err := r.db.Omit("Author").Create(comment).Error; err != nil {
+		return err
+	}
+	// レスポンスに含める投稿者を読み込む
+	return r.db.Preload("Author").First(comment, comment.ID).Error
+}
+
+func (r *taskRepository) UpdateComment(comment *model.Comment) error {
+	return r.db.Model(comment).Omit(clause.Associations).
+		Updates(map[string]interface{}{"body": comment.Body, "edited_at": comment.EditedAt, "updated_at": comment.UpdatedAt}).Error
+}
+
+func (r *taskRepository) DeleteComment(comment *model.Comment) error {
+	return r.db.Delete(comment).Error
+}
+
+func (r *taskRepository) DeleteTaskTree(task *model.Task) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		// 子孫タスクの削除日時をルートと揃え、復元時にまとめて戻せるようにする
+		now := time.Now()
+		tx = tx.Session(&gorm.Session{NowFunc: func() time.Time { return now }})
+
+		var ids []uint
+		if err := tx.Raw("SELECT id FROM (?) AS subtree", subtreeIDs(tx, task.ID)).Scan(&ids).Error; err != nil {
+			return err
+		}
+		// ルートのタスクのみバージョンを確認して削除する
+		if err := deleteTask(tx, task); err != nil {
+			return err
+		}
+		return tx.Where("id <> ?", task.ID).Delete(&model.Task{}, ids).Error
+	})
+}
+
+func (r *taskRepository) GetDeletedTasks(limit, offset int) ([]model.Task, int64, error) {
+	var tasks []model.Task
+	var total int64
+	// 削除と同じく、所有者以外（共有されたタスク・プロジェクトのタスク）のゴミ箱のタスクも対象にする（操作の権限はハンドラーで確認する）
+	query := r.db.Unscoped().Model(&model.Task{}).Scopes(r.visible).Where("deleted_at IS NOT NULL")
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if err := query.Preload("Tags").Preload("Assignees").Order("deleted_at DESC, id").Limit(limit).Offset(offset).Find(&tasks).Error; err != nil {
+		return nil, 0, err
+	}
+	if err := fillComputedFields(r.db, tasks); err != nil {
+		return nil, 0, err
+	}
+
+	return tasks, total, nil
+}
+
+func (r *taskRepository) GetDeletedTaskByID(id uint) (*model.Task, error) {
 	var task model.Task
-	if err := r.db.First(&task, id).Error; err != nil {
+	if err := r.db.Unscoped().Scopes(r.visible).Preload("Tags").Preload("Assignees").Where("deleted_at IS NOT NULL").First(&task, id).Error; err != nil {
 		return nil, err
 	}
 	return &task, nil
 }
 
-func (r *taskRepository) UpdateTask(task *model.Task) error {
-	return r.db.Save(task).Error
+func (r *taskRepository) RestoreTask(task *model.Task) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		// 同じ操作で削除された（削除日時が同じ）子孫タスクもあわせて復元する
+		err := tx.Unscoped().Model(&model.Task{}).
+			Where("id IN (?)", subtreeIDs(tx, task.ID)).
+			Where("deleted_at = ?", task.DeletedAt.Time).
+			Updates(map[string]interface{}{"deleted_at": nil, "version": gorm.Expr("version + 1")}).Error
+		if err != nil {
+			return err
+		}
+
+		// 親タスクがゴミ箱にある場合は親から外す
+		if task.ParentID != nil {
+			var parents int64
+			if err := tx.Model(&model.Task{}).Where("id = ?", *task.ParentID).Count(&parents).Error; err != nil {
+				return err
+			}
+			if parents == 0 {
+				if err := tx.Model(&model.Task{}).Where("id = ?", task.ID).Update("parent_id", nil).Error; err != nil {
+					return err
+				}
+				task.ParentID = nil
+			}
+		}
+
+		task.DeletedAt = gorm.DeletedAt{}
+		task.Version++
+		return nil
+	})
 }
 
-func (r *taskRepository) DeleteTask(task *model.Task) error {
-	return r.db.Delete(task).Error
+func (r *taskRepository) PurgeTask(task *model.Task) error {
+	return r.db.Unscoped().Delete(task).Error
 }
 
-func (r *taskRepository) ToggleTaskCompletion(task *model.Task) error {
-	task.IsCompleted = !task.IsCompleted
-	return r.db.Save(task).Error
+func (r *taskRepository) PurgeDeletedTasks(before time.Time) ([]model.Task, error) {
+	// 監査ログに記録するため、削除したタスクを返す
+	var tasks []model.Task
+	if err := r.db.Unscoped().Clauses(clause.Returning{}).Scopes(r.owned).Where("deleted_at < ?", before).Delete(&tasks).Error; err != nil {
+		return nil, err
+	}
+	return tasks, nil
+}
+
+// PurgeOccurrence は繰り返しタスクの完了時に生成した次回のタスクを完全に削除します。
+// 生成後に変更されていた（バージョンが 1 でない）場合は ErrVersionConflict を返します
+func (r *taskRepository) PurgeOccurrence(id uint) error {
+	result := r.db.Unscoped().Scopes(r.visible).Where("recurrence_of_id IS NOT NULL AND version = 1").Delete(&model.Task{}, id)
+	if result.Error == nil && result.RowsAffected == 0 {
+		result.Error = ErrVersionConflict
+	}
+	return result.Error
+}
+
+// Transaction は fn に渡したリポジトリの操作を1つのトランザクションで実行します。
+// トランザクション内で呼び出した場合はセーブポイントになります
+func (r *taskRepository) Transaction(fn func(repo TaskRepository) error) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return fn(&taskRepository{db: tx, tenantID: r.tenantID, ownerID: r.ownerID})
+	})
+}
+
+// saveTask はタスクのすべての列を保存し、バージョンを1つ進めます。
+// 読み込み後に他の更新でバージョンが変わっていた場合は ErrVersionConflict を返します。
+// 完了状態が変わった場合は完了日時を設定し、完了履歴を記録します。
+// 繰り返しタスクを完了にした場合は、更新・切り替えのどちらでも次回のタスクを生成します
+func saveTask(tx *gorm.DB, task *model.Task) error {
+	version, completedAt := task.Version, task.CompletedAt
+
+	// 保存済みの完了状態と比較する
+	var stored model.Task
+	result := tx.Select("is_completed").Where("version = ?", version).Limit(1).Find(&stored, task.ID)
+	if result.Error == nil && result.RowsAffected == 0 {
+		result.Error = ErrVersionConflict
+	}
+	if result.Error != nil {
+		return result.Error
+	}
+	var event *model.CompletionEvent
+	if stored.IsCompleted != task.IsCompleted {
+		now := time.Now()
+		event = &model.CompletionEvent{TaskID: task.ID, Action: model.CompletionActionReopened, CreatedAt: now}
+		task.CompletedAt = nil
+		if task.IsCompleted {
+			event.Action = model.CompletionActionCompleted
+			task.CompletedAt = &now
+		}
+	}
+
+	task.Version++
+	result = tx.Model(task).Where("version = ?", version).Select("*").Omit(clause.Associations).Updates(task)
+	if result.Error == nil && result.RowsAffected == 0 {
+		result.Error = ErrVersionConflict
+	}
+	if result.Error == nil && event != nil {
+		result.Error = tx.Create(event).Error
+	}
+	if result.Error == nil && event != nil && task.IsCompleted {
+		result.Error = spawnNextOccurrence(tx, task)
+	}
+	if result.Error != nil {
+		task.Version, task.CompletedAt = version, completedAt
+		return result.Error
+	}
+	return nil
+}
+
+// bumpVersion は指定された列を更新し、バージョンを1つ進めます（values が nil の場合はバージョンのみ）
+func bumpVersion(tx *gorm.DB, task *model.Task, values map[string]interface{}) error {
+	if values == nil {
+		values = make(map[string]interface{})
+	}
+	values["version"] = gorm.Expr("version + 1")
+	result := tx.Model(task).Where("version = ?", task.Version).Omit(clause.Associations).Updates(values)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrVersionConflict
+	}
+	task.Version++
+	return nil
+}
+
+// deleteTask はバージョンが一致する場合のみタスクを削除します
+func deleteTask(tx *gorm.DB, task *model.Task) error {
+	result := tx.Where("version = ?", task.Version).Delete(task)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrVersionConflict
+	}
+	return nil
+}
+
+// fillComputedFields はクライアントに返すタスクの、保存していない項目（完了率・コメントの件数）を設定します
+func fillComputedFields(db *gorm.DB, tasks []model.Task) error {
+	if err := fillProgress(db, tasks); err != nil {
+		return err
+	}
+	return fillCommentCounts(db, tasks)
+}
+
+// fillProgress は各タスクの直下の子タスクから完了率を計算して設定します
+func fillProgress(db *gorm.DB, tasks []model.Task) error {
+	if len(tasks) == 0 {
+		return nil
+	}
+
+	var rows []struct {
+		ParentID  uint
+		Total     int
+		Completed int
+	}
+	err := db.Model(&model.Task{}).
+		Select("parent_id, COUNT(*) AS total, SUM(CASE WHEN is_completed THEN 1 ELSE 0 END) AS completed").
+		Where("parent_id IN ?", taskIDs(tasks)).
+		Group("parent_id").
+		Scan(&rows).Error
+	if err != nil {
+		return err
+	}
+
+	progress := make(map[uint]int, len(rows))
+	for _, row := range rows {
+		progress[row.ParentID] = row.Completed * 100 / row.Total
+	}
+	for i := range tasks {
+		if p, ok := progress[tasks[i].ID]; ok {
+			tasks[i].Progress = &p
+		}
+	}
+	return nil
+}
+
+// fillCommentCounts は各タスクへのコメントの件数を設定します
+func fillCommentCounts(db *gorm.DB, tasks []model.Task) error {
+	if len(tasks) == 0 {
+		return nil
+	}
+
+	var rows []struct {
+		TaskID uint
+		Count  int
+	}
+	err := db.Model(&model.Comment{}).
+		Select("task_id, COUNT(*) AS count").
+		Where("task_id IN ?", taskIDs(tasks)).
+		Group("task_id").
+		Scan(&rows).Error
+	if err != nil {
+		return err
+	}
+
+	counts := make(map[uint]int, len(rows))
+	for _, row := range rows {
+		counts[row.TaskID] = row.Count
+	}
+	for i := range tasks {
+		tasks[i].CommentCount = counts[tasks[i].ID]
+	}
+	return nil
+}
+
+// spawnNextOccurrence は繰り返しタスクが完了した際に次回のタスクを生成します
+func spawnNextOccurrence(tx *gorm.DB, task *model.Task) error {
+	if !task.IsCompleted || task.RRule == "" || task.DueDate.IsZero() {
+		return nil
+	}
+
+	// 再オープン後に再度完了した場合などに重複して生成しない（ゴミ箱にあるものも含める）
+	var successors int64
+	if err := tx.Unscoped().Model(&model.Task{}).Where("recurrence_of_id = ?", task.ID).Count(&successors).Error; err != nil {
+		return err
+	}
+	if successors > 0 {
+		return nil
+	}
+
+	rule, err := rrule.Parse(task.RRule)
+	if err != nil {
+		return err
+	}
+	next, ok := rule.Next(task.DueDate, task.DueDate)
+	if !ok {
+		// COUNT や UNTIL により系列が終了している
+		return nil
+	}
+	// COUNT は残りの回数として引き継ぐ
+	if rule.Count > 0 {
+		rule.Count--
+	}
+
+	nextTask := model.Task{
+		Title:          task.Title,
+		Description:    task.Description,
+		DueDate:        next,
+		Priority:       task.Priority,
+		ProjectID:      task.ProjectID,
+		ParentID:       task.ParentID,
+		RRule:          rule.String(),
+		RecurrenceOfID: &task.ID,
+		OwnerID:        task.OwnerID,
+		TenantID:       task.TenantID,
+		Tags:           task.Tags,
+	}
+	if err := tx.Create(&nextTask).Error; err != nil {
+		return err
+	}
+	if err := tx.Create(model.NewTaskRevision(&nextTask)).Error; err != nil {
+		return err
+	}
+	task.NextOccurrence = &nextTask
+	return nil
+}
+
+// ownedBy は ownerID が所有するタスクに絞り込むスコープを返します（ownerID が nil の場合は絞り込まない）
+func ownedBy(ownerID *uint) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if ownerID == nil {
+			return db
+		}
+		return db.Where("tasks.owner_id = ?", *ownerID)
+	}
+}
+
+// visibleTo は userID のユーザーが参照できるタスク（所有するタスク、メンバーとして共有されたタスク、
+// メンバーになっているプロジェクトのタスク）に絞り込むスコープを返します（userID が nil の場合は絞り込まない）
+func visibleTo(userID *uint) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if userID == nil {
+			return db
+		}
+		newDB := db.Session(&gorm.Session{NewDB: true})
+		return db.Where("tasks.owner_id = ? OR tasks.id IN (?) OR tasks.project_id IN (?)", *userID,
+			newDB.Model(&model.TaskMember{}).Select("task_id").Where("user_id = ?", *userID),
+			newDB.Model(&model.ProjectMember{}).Select("project_id").Where("user_id = ?", *userID))
+	}
+}
+
+// visibleTask は task_id 列を持つテーブルを、リポジトリのユーザーが参照できるタスク（ゴミ箱のものを含む）の行に絞り込みます
+func (r *taskRepository) visibleTask(db *gorm.DB) *gorm.DB {
+	if r.ownerID == nil && r.tenantID == nil {
+		return db
+	}
+	return db.Where("task_id IN (?)", r.db.Session(&gorm.Session{NewDB: true}).Unscoped().
+		Model(&model.Task{}).Select("id").Scopes(r.visible))
+}
+
+// visible はリポジトリのワークスペースのタスクのうち、リポジトリのユーザーが参照できるものに絞り込みます
+func (r *taskRepository) visible(db *gorm.DB) *gorm.DB {
+	return db.Scopes(inTenant("tasks", r.tenantID), visibleTo(r.ownerID))
+}
+
+// owned はリポジトリのワークスペースのタスクのうち、リポジトリのユーザーが所有するものに絞り込みます
+func (r *taskRepository) owned(db *gorm.DB) *gorm.DB {
+	return db.Scopes(inTenant("tasks", r.tenantID), ownedBy(r.ownerID))
+}
+
+// inTenant は table の行を tenantID のワークスペースのものに絞り込むスコープを返します（tenantID が nil の場合は絞り込まない）
+func inTenant(table string, tenantID *uint) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if tenantID == nil {
+			return db
+		}
+		return db.Where(table+".tenant_id = ?", *tenantID)
+	}
+}
+
+// loadAssignees はタスクの担当者を読み込み直します
+func loadAssignees(db *gorm.DB, task *model.Task) error {
+	var assignees []model.User
+	err := db.Where("id IN (?)", db.Model(&model.TaskAssignee{}).Select("user_id").Where("task_id = ?", task.ID)).
+		Order("id").Find(&assignees).Error
+	if err != nil {
+		return err
+	}
+	task.Assignees = assignees
+	return nil
+}
+
+// subtreeIDs は rootID 自身とその子孫タスクのIDを返すサブクエリを組み立てます
+func subtreeIDs(db *gorm.DB, rootID uint) *gorm.DB {
+	return db.Raw(`WITH RECURSIVE subtree AS (
+		SELECT id FROM tasks WHERE id = ?
+		UNION
+		SELECT t.id FROM tasks t JOIN subtree s ON t.parent_id = s.id
+	) SELECT id FROM subtree`, rootID)
+}
+
+// taskIDs はタスクのIDを返します
+func taskIDs(tasks []model.Task) []uint {
+	ids := make([]uint, len(tasks))
+	for i, task := range tasks {
+		ids[i] = task.ID
+	}
+	return ids
+}
+
+// taskChanges は変更前のタスクと、apply で変更してバージョンを1つ進めたタスクの組を返します。
+// apply が nil の場合は削除として変更後を nil にします
+func taskChanges(tasks []model.Task, apply func(task *model.Task)) []TaskChange {
+	changes := make([]TaskChange, len(tasks))
+	for i := range tasks {
+		changes[i].Before = &tasks[i]
+		if apply == nil {
+			continue
+		}
+		after := tasks[i]
+		apply(&after)
+		after.Version++
+		changes[i].After = &after
+	}
+	return changes
+}
+
+func uniqueValues[T comparable](values []T) []T {
+	seen := make(map[T]struct{}, len(values))
+	result := make([]T, 0, len(values))
+	for _, v := range values {
+		if _, ok := seen[v]; ok {
+			continue
+		}
+		seen[v] = struct{}{}
+		result = append(result, v)
+	}
+	return result
 }
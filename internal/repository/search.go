@@ -0,0 +1,36 @@
+package repository
+
+import (
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// likeEscaper は LIKE のワイルドカードをエスケープします（エスケープ文字は "!"）
+var likeEscaper = strings.NewReplacer("!", "!!", "%", "!%", "_", "!_")
+
+// searchClauses はキーワード検索の絞り込み条件とランキング式を返します。
+// PostgreSQL では search_vector 列（GINインデックス付き）による全文検索を使用し、
+// それ以外のデータベースではタイトル・説明の部分一致で同等の検索を行います
+func searchClauses(db *gorm.DB, q string) (where clause.Expr, rank clause.Expr) {
+	if db.Dialector.Name() == "postgres" {
+		where = gorm.Expr("tasks.search_vector @@ websearch_to_tsquery('simple', ?)", q)
+		rank = gorm.Expr("ts_rank(tasks.search_vector, websearch_to_tsquery('simple', ?))", q)
+		return where, rank
+	}
+
+	// すべての語を含むタスクに絞り込み、タイトルでの一致を説明での一致より高く評価する
+	var conditions, scores []string
+	var whereVars, rankVars []interface{}
+	for _, term := range strings.Fields(strings.ToLower(q)) {
+		pattern := "%" + likeEscaper.Replace(term) + "%"
+		conditions = append(conditions, "(LOWER(tasks.title) LIKE ? ESCAPE '!' OR LOWER(tasks.description) LIKE ? ESCAPE '!')")
+		whereVars = append(whereVars, pattern, pattern)
+		scores = append(scores, "CASE WHEN LOWER(tasks.title) LIKE ? ESCAPE '!' THEN 2 ELSE 0 END + CASE WHEN LOWER(tasks.description) LIKE ? ESCAPE '!' THEN 1 ELSE 0 END")
+		rankVars = append(rankVars, pattern, pattern)
+	}
+	where = gorm.Expr(strings.Join(conditions, " AND "), whereVars...)
+	rank = gorm.Expr("("+strings.Join(scores, " + ")+")", rankVars...)
+	return where, rank
+}
@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ryory2/test-go-app-todo-go/internal/model"
+	"gorm.io/gorm/clause"
+)
+
+// SortField はタスク一覧の並び順の1キーです
+type SortField struct {
+	Field string
+	Desc  bool
+}
+
+// sortKey は並び替えキーのSQL式と、カーソル作成時にタスクからキーの値を取り出す関数です
+type sortKey struct {
+	expr  string
+	value func(task *model.Task) interface{}
+}
+
+// sortKeys は並び替えに使用できるキーです
+var sortKeys = map[string]sortKey{
+	"due_date": {"tasks.due_date", func(t *model.Task) interface{} { return t.DueDate }},
+	"priority": {
+		"CASE tasks.priority WHEN 'low' THEN 1 WHEN 'medium' THEN 2 WHEN 'high' THEN 3 WHEN 'urgent' THEN 4 ELSE 0 END",
+		func(t *model.Task) interface{} { return t.Priority.Rank() },
+	},
+	"created_at": {"tasks.created_at", func(t *model.Task) interface{} { return t.CreatedAt }},
+	"updated_at": {"tasks.updated_at", func(t *model.Task) interface{} { return t.UpdatedAt }},
+	"title":      {"tasks.title", func(t *model.Task) interface{} { return t.Title }},
+}
+
+// ParseSort は "-priority,due_date" 形式の並び順を解析します（先頭の "-" は降順）
+func ParseSort(value string) ([]SortField, error) {
+	var fields []SortField
+	seen := make(map[string]bool)
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		field := SortField{Field: strings.TrimPrefix(item, "-"), Desc: strings.HasPrefix(item, "-")}
+		if _, ok := sortKeys[field.Field]; !ok {
+			return nil, fmt.Errorf("unknown sort field %q", field.Field)
+		}
+		if seen[field.Field] {
+			return nil, fmt.Errorf("duplicate sort field %q", field.Field)
+		}
+		seen[field.Field] = true
+		fields = append(fields, field)
+	}
+	return fields, nil
+}
+
+// FormatSort は並び順を ParseSort と同じ形式の文字列に戻します
+func FormatSort(fields []SortField) string {
+	items := make([]string, len(fields))
+	for i, field := range fields {
+		if field.Desc {
+			items[i] = "-" + field.Field
+		} else {
+			items[i] = field.Field
+		}
+	}
+	return strings.Join(items, ",")
+}
+
+// orderBy は ORDER BY 句を組み立てます。並び順の指定がなく rank が与えられた場合は関連度順とし、
+// ページングの結果を安定させるため最後に id を加えます。reverse が true の場合はすべてのキーを逆順にします
+func orderBy(fields []SortField, rank *clause.Expr, reverse bool) clause.OrderBy {
+	direction := func(desc bool) string {
+		if desc != reverse {
+			return "DESC"
+		}
+		return "ASC"
+	}
+
+	var parts []string
+	var vars []interface{}
+	if len(fields) == 0 && rank != nil {
+		parts = append(parts, rank.SQL+" "+direction(true))
+		vars = append(vars, rank.Vars...)
+	}
+	for _, field := range fields {
+		parts = append(parts, sortKeys[field.Field].expr+" "+direction(field.Desc))
+	}
+	parts = append(parts, "tasks.id "+direction(false))
+	return clause.OrderBy{Expression: clause.Expr{SQL: strings.Join(parts, ", "), Vars: vars, WithoutParentheses: true}}
+}
@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"github.com/ryory2/test-go-app-todo-go/internal/model"
+	"gorm.io/gorm"
+)
+
+type TagRepository interface {
+	GetTags() ([]model.Tag, error)
+	CreateTag(tag *model.Tag) error
+	GetTagByID(id uint) (*model.Tag, error)
+	UpdateTag(tag *model.Tag) error
+	DeleteTag(tag *model.Tag) error
+	ForTenant(tenantID uint) TagRepository
+}
+
+type tagRepository struct {
+	db *gorm.DB
+	// tenantID が設定されている場合は、そのワークスペースのタグのみを操作する
+	tenantID *uint
+}
+
+func NewTagRepository(db *gorm.DB) TagRepository {
+	return &tagRepository{db: db}
+}
+
+// ForTenant は tenantID のワークスペースのタグのみを操作するリポジトリを返します
+func (r *tagRepository) ForTenant(tenantID uint) TagRepository {
+	return &tagRepository{db: r.db, tenantID: &tenantID}
+}
+
+func (r *tagRepository) GetTags() ([]model.Tag, error) {
+	var tags []model.Tag
+	if err := r.db.Scopes(inTenant("tags", r.tenantID)).Order("name").Find(&tags).Error; err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
+
+func (r *tagRepository) CreateTag(tag *model.Tag) error {
+	if r.tenantID != nil {
+		tag.TenantID = *r.tenantID
+	}
+	return r.db.Create(tag).Error
+}
+
+func (r *tagRepository) GetTagByID(id uint) (*model.Tag, error) {
+	var tag model.Tag
+	if err := r.db.Scopes(inTenant("tags", r.tenantID)).First(&tag, id).Error; err != nil {
+		return nil, err
+	}
+	return &tag, nil
+}
+
+func (r *tagRepository) UpdateTag(tag *model.Tag) error {
+	return r.db.Save(tag).Error
+}
+
+func (r *tagRepository) DeleteTag(tag *model.Tag) error {
+	// tasks_tags の関連行は外部キーの ON DELETE CASCADE で削除される
+	return r.db.Delete(tag).Error
+}
@@ -0,0 +1,212 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/ryory2/test-go-app-todo-go/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type UserRepository interface {
+	CreateUser(user *model.User, workspaceSlug string) error
+	GetUserByID(id uint) (*model.User, error)
+	GetUserByEmail(email string) (*model.User, error)
+	CreateSession(session *model.Session) error
+	GetSession(tokenHash string) (*model.Session, error)
+	DeleteSession(session *model.Session) error
+	DeleteExpiredSessions(before time.Time) (int64, error)
+	RevokeToken(token *model.RevokedToken) error
+	IsTokenRevoked(jti string) (bool, error)
+	DeleteExpiredRevokedTokens(before time.Time) (int64, error)
+	CreatePersonalAccessToken(token *model.PersonalAccessToken) error
+	GetPersonalAccessTokens(userID uint) ([]model.PersonalAccessToken, error)
+	GetPersonalAccessToken(tokenHash string) (*model.PersonalAccessToken, error)
+	DeletePersonalAccessToken(userID, id uint) error
+	TouchPersonalAccessToken(token *model.PersonalAccessToken, usedAt time.Time) error
+	ClaimLegacyData(user *model.User) (int64, error)
+}
+
+type userRepository struct {
+	db *gorm.DB
+}
+
+func NewUserRepository(db *gorm.DB) UserRepository {
+	return &userRepository{db}
+}
+
+// CreateUser はユーザーを作成し、workspaceSlug のワークスペースに member として参加させます。
+// メンバーがいないワークスペースでは admin とします。workspaceSlug が空、またはワークスペースが存在しない場合は参加させません
+func (r *userRepository) CreateUser(user *model.User, workspaceSlug string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(user).Error; err != nil {
+			return err
+		}
+		if workspaceSlug == "" {
+			return nil
+		}
+
+		// 同時に登録したユーザーが両方とも管理者にならないように、ワークスペースの行をロックする
+		var workspaces []model.Workspace
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("slug = ?", workspaceSlug).Limit(1).Find(&workspaces).Error; err != nil {
+			return err
+		}
+		if len(workspaces) == 0 {
+			return nil
+		}
+
+		var members int64
+		if err := tx.Model(&model.WorkspaceMember{}).Where("workspace_id = ?", workspaces[0].ID).Count(&members).Error; err != nil {
+			return err
+		}
+		role := model.WorkspaceRoleMember
+		if members == 0 {
+			role = model.WorkspaceRoleAdmin
+		}
+		return tx.Create(&model.WorkspaceMember{WorkspaceID: workspaces[0].ID, UserID: user.ID, Role: role}).Error
+	})
+}
+
+func (r *userRepository) GetUserByID(id uint) (*model.User, error) {
+	var user model.User
+	if err := r.db.First(&user, id).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (r *userRepository) GetUserByEmail(email string) (*model.User, error) {
+	var user model.User
+	if err := r.db.Where("email = ?", email).First(&user).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (r *userRepository) CreateSession(session *model.Session) error {
+	return r.db.Omit("User").Create(session).Error
+}
+
+func (r *userRepository) GetSession(tokenHash string) (*model.Session, error) {
+	var session model.Session
+	if err := r.db.Joins("User").Where("sessions.token_hash = ?", tokenHash).First(&session).Error; err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+func (r *userRepository) DeleteSession(session *model.Session) error {
+	// 同じリフレッシュトークンで同時に更新した場合も1回のみ成功する
+	result := r.db.Delete(session)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *userRepository) DeleteExpiredSessions(before time.Time) (int64, error) {
+	result := r.db.Where("expires_at < ?", before).Delete(&model.Session{})
+	return result.RowsAffected, result.Error
+}
+
+func (r *userRepository) RevokeToken(token *model.RevokedToken) error {
+	// 失効済みのトークンを再度失効させてもエラーにしない
+	return r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(token).Error
+}
+
+func (r *userRepository) IsTokenRevoked(jti string) (bool, error) {
+	var count int64
+	if err := r.db.Model(&model.RevokedToken{}).Where("jti = ?", jti).Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (r *userRepository) DeleteExpiredRevokedTokens(before time.Time) (int64, error) {
+	result := r.db.Where("expires_at < ?", before).Delete(&model.RevokedToken{})
+	return result.RowsAffected, result.Error
+}
+
+func (r *userRepository) CreatePersonalAccessToken(token *model.PersonalAccessToken) error {
+	return r.db.Omit("User").Create(token).Error
+}
+
+func (r *userRepository) GetPersonalAccessTokens(userID uint) ([]model.PersonalAccessToken, error) {
+	var tokens []model.PersonalAccessToken
+	if err := r.db.Where("user_id = ?", userID).Order("id").Find(&tokens).Error; err != nil {
+		return nil, err
+	}
+	return tokens, nil
+}
+
+func (r *userRepository) GetPersonalAccessToken(tokenHash string) (*model.PersonalAccessToken, error) {
+	var token model.PersonalAccessToken
+	if err := r.db.Joins("User").Where("personal_access_tokens.token_hash = ?", tokenHash).First(&token).Error; err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+func (r *userRepository) DeletePersonalAccessToken(userID, id uint) error {
+	result := r.db.Where("user_id = ?", userID).Delete(&model.PersonalAccessToken{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *userRepository) TouchPersonalAccessToken(token *model.PersonalAccessToken, usedAt time.Time) error {
+	if err := r.db.Model(token).Omit("User").UpdateColumn("last_used_at", usedAt).Error; err != nil {
+		return err
+	}
+	token.LastUsedAt = &usedAt
+	return nil
+}
+
+// ClaimLegacyData は移行用のユーザーが所有するタスクとメンバーの行を user に引き継ぎ、移行用のユーザーを削除します。
+// 引き継いだタスクの件数を返します。移行用のユーザーが存在しない場合は gorm.ErrRecordNotFound を返します
+func (r *userRepository) ClaimLegacyData(user *model.User) (int64, error) {
+	var claimed int64
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var legacy model.User
+		if err := tx.Where("email = ?", model.LegacyOwnerEmail).First(&legacy).Error; err != nil {
+			return err
+		}
+
+		result := tx.Exec("UPDATE tasks SET owner_id = ? WHERE owner_id = ?", user.ID, legacy.ID)
+		if result.Error != nil {
+			return result.Error
+		}
+		claimed = result.RowsAffected
+
+		// すでにメンバーである場合は、移行用のユーザーが最上位のロール（owner・admin）であればそのロールに上げる。
+		// 残った移行用のユーザーの行は、移行用のユーザーとともに削除される
+		for _, m := range []struct{ table, key, topRole string }{
+			{"project_members", "project_id", model.RoleOwner},
+			{"task_members", "task_id", model.RoleOwner},
+			{"workspace_members", "workspace_id", model.WorkspaceRoleAdmin},
+		} {
+			err := tx.Exec("UPDATE "+m.table+" AS m SET role = ? WHERE m.user_id = ? AND EXISTS "+
+				"(SELECT 1 FROM "+m.table+" AS o WHERE o."+m.key+" = m."+m.key+" AND o.user_id = ? AND o.role = ?)",
+				m.topRole, user.ID, legacy.ID, m.topRole).Error
+			if err != nil {
+				return err
+			}
+			err = tx.Exec("UPDATE "+m.table+" AS m SET user_id = ? WHERE m.user_id = ? AND NOT EXISTS "+
+				"(SELECT 1 FROM "+m.table+" AS o WHERE o."+m.key+" = m."+m.key+" AND o.user_id = ?)",
+				user.ID, legacy.ID, user.ID).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		return tx.Delete(&legacy).Error
+	})
+	return claimed, err
+}
@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/ryory2/test-go-app-todo-go/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrLastAdmin は最後の管理者を削除・変更しようとした場合のエラーです
+var ErrLastAdmin = errors.New("cannot remove the last admin")
+
+type WorkspaceRepository interface {
+	GetWorkspaces(userID uint) ([]model.Workspace, error)
+	CreateWorkspace(workspace *model.Workspace, adminID uint) error
+	GetWorkspaceBySlug(slug string) (*model.Workspace, error)
+	UpdateWorkspace(workspace *model.Workspace) error
+	GetWorkspaceRole(workspaceID, userID uint) (string, error)
+	GetWorkspaceMembers(workspaceID uint) ([]model.WorkspaceMember, error)
+	SetWorkspaceMember(member *model.WorkspaceMember) error
+	RemoveWorkspaceMember(workspaceID, userID uint) error
+}
+
+type workspaceRepository struct {
+	db *gorm.DB
+}
+
+func NewWorkspaceRepository(db *gorm.DB) WorkspaceRepository {
+	return &workspaceRepository{db: db}
+}
+
+func (r *workspaceRepository) GetWorkspaces(userID uint) ([]model.Workspace, error) {
+	var workspaces []model.Workspace
+	err := r.db.Where("id IN (?)", r.db.Model(&model.WorkspaceMember{}).Select("workspace_id").Where("user_id = ?", userID)).
+		Order("id").Find(&workspaces).Error
+	if err != nil {
+		return nil, err
+	}
+	return workspaces, nil
+}
+
+func (r *workspaceRepository) CreateWorkspace(workspace *model.Workspace, adminID uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(workspace).Error; err != nil {
+			return err
+		}
+		return tx.Create(&model.WorkspaceMember{WorkspaceID: workspace.ID, UserID: adminID, Role: model.WorkspaceRoleAdmin}).Error
+	})
+}
+
+func (r *workspaceRepository) GetWorkspaceBySlug(slug string) (*model.Workspace, error) {
+	var workspace model.Workspace
+	if err := r.db.Where("slug = ?", slug).First(&workspace).Error; err != nil {
+		return nil, err
+	}
+	return &workspace, nil
+}
+
+func (r *workspaceRepository) UpdateWorkspace(workspace *model.Workspace) error {
+	return r.db.Save(workspace).Error
+}
+
+func (r *workspaceRepository) GetWorkspaceRole(workspaceID, userID uint) (string, error) {
+	var roles []string
+	if err := r.db.Model(&model.WorkspaceMember{}).Where("workspace_id = ? AND user_id = ?", workspaceID, userID).Pluck("role", &roles).Error; err != nil {
+		return "", err
+	}
+	if len(roles) == 0 {
+		return "", nil
+	}
+	return roles[0], nil
+}
+
+func (r *workspaceRepository) GetWorkspaceMembers(workspaceID uint) ([]model.WorkspaceMember, error) {
+	var members []model.WorkspaceMember
+	if err := r.db.Preload("User").Where("workspace_id = ?", workspaceID).Order("user_id").Find(&members).Error; err != nil {
+		return nil, err
+	}
+	return members, nil
+}
+
+func (r *workspaceRepository) SetWorkspaceMember(member *model.WorkspaceMember) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if member.Role != model.WorkspaceRoleAdmin {
+			if err := ensureOtherAdmin(tx, member.WorkspaceID, member.UserID); err != nil {
+				return err
+			}
+		}
+		return tx.Omit("User").Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "workspace_id"}, {Name: "user_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"role", "updated_at"}),
+		}).Create(member).Error
+	})
+}
+
+func (r *workspaceRepository) RemoveWorkspaceMember(workspaceID, userID uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := ensureOtherAdmin(tx, workspaceID, userID); err != nil {
+			return err
+		}
+		result := tx.Where("workspace_id = ? AND user_id = ?", workspaceID, userID).Delete(&model.WorkspaceMember{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return nil
+	})
+}
+
+// ensureOtherAdmin は userID のメンバーが管理者の場合に、ワークスペースに他の管理者がいることを確認します
+func ensureOtherAdmin(tx *gorm.DB, workspaceID, userID uint) error {
+	var admins []uint
+	err := tx.Model(&model.WorkspaceMember{}).Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("workspace_id = ? AND role = ?", workspaceID, model.WorkspaceRoleAdmin).Pluck("user_id", &admins).Error
+	if err != nil {
+		return err
+	}
+	if len(admins) == 1 && admins[0] == userID {
+		return ErrLastAdmin
+	}
+	return nil
+}
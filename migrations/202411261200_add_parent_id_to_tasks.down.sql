@@ -0,0 +1,2 @@
+ALTER TABLE tasks DROP CONSTRAINT IF EXISTS chk_tasks_parent_not_self;
+ALTER TABLE tasks DROP COLUMN IF EXISTS parent_id;
\ No newline at end of file
@@ -0,0 +1,2 @@
+ALTER TABLE tasks DROP COLUMN IF EXISTS recurrence_of_id;
+ALTER TABLE tasks DROP COLUMN IF EXISTS rrule;
\ No newline at end of file
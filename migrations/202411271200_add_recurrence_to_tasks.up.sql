@@ -0,0 +1,5 @@
+ALTER TABLE tasks ADD COLUMN rrule VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE tasks ADD COLUMN recurrence_of_id INTEGER REFERENCES tasks(id) ON DELETE SET NULL;
+
+-- 1つのタスクから生成される次回のタスクは1件のみ
+CREATE UNIQUE INDEX idx_tasks_recurrence_of_id ON tasks(recurrence_of_id);
\ No newline at end of file
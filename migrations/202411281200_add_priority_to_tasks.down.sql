@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_tasks_due_date;
+ALTER TABLE tasks DROP COLUMN IF EXISTS priority;
\ No newline at end of file
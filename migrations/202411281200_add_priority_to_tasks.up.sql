@@ -0,0 +1,4 @@
+ALTER TABLE tasks ADD COLUMN priority VARCHAR(10) NOT NULL DEFAULT 'none'
+    CHECK (priority IN ('none', 'low', 'medium', 'high', 'urgent'));
+
+CREATE INDEX idx_tasks_due_date ON tasks(due_date);
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE tasks ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
\ No newline at end of file
@@ -0,0 +1,2 @@
+ALTER TABLE tasks ADD COLUMN archived_at TIMESTAMP WITH TIME ZONE;
+CREATE INDEX idx_tasks_archived_at ON tasks(archived_at);
\ No newline at end of file
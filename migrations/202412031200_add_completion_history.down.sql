@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS completion_events;
+ALTER TABLE tasks DROP COLUMN IF EXISTS completed_at;
\ No newline at end of file
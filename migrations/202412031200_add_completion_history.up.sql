@@ -0,0 +1,13 @@
+ALTER TABLE tasks ADD COLUMN completed_at TIMESTAMP WITH TIME ZONE;
+
+-- 既存の完了済みタスクは最終更新日時を完了日時とみなす
+UPDATE tasks SET completed_at = updated_at WHERE is_completed = TRUE;
+
+CREATE TABLE completion_events (
+    id SERIAL PRIMARY KEY,
+    task_id INTEGER NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    action VARCHAR(20) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_completion_events_task_id ON completion_events(task_id);
\ No newline at end of file
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS task_revisions;
\ No newline at end of file
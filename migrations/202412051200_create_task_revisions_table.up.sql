@@ -0,0 +1,16 @@
+CREATE TABLE task_revisions (
+    id SERIAL PRIMARY KEY,
+    task_id INTEGER NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    revision INTEGER NOT NULL,
+    title VARCHAR(100) NOT NULL,
+    description VARCHAR(500),
+    due_date DATE,
+    priority VARCHAR(10) NOT NULL DEFAULT 'none',
+    rrule VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (task_id, revision)
+);
+
+-- 既存のタスクは現在の内容を最初のリビジョンとする
+INSERT INTO task_revisions (task_id, revision, title, description, due_date, priority, rrule, created_at)
+SELECT id, version, title, description, due_date, priority, rrule, updated_at FROM tasks;
\ No newline at end of file
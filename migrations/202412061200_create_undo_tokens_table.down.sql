@@ -0,0 +1 @@
+DROP TABLE IF EXISTS undo_tokens;
\ No newline at end of file
@@ -0,0 +1,7 @@
+-- 所有者の情報は失われる（移行用のユーザーも users テーブルとともに削除される）。
+-- 再度 up を実行すると、すべてのタスクが移行用のユーザーの所有に戻る
+ALTER TABLE tasks DROP COLUMN IF EXISTS owner_id;
+
+DROP TABLE IF EXISTS sessions;
+
+DROP TABLE IF EXISTS users;
\ No newline at end of file
@@ -0,0 +1,30 @@
+CREATE TABLE users (
+    id SERIAL PRIMARY KEY,
+    email VARCHAR(255) NOT NULL UNIQUE,
+    name VARCHAR(100) NOT NULL DEFAULT '',
+    password_hash VARCHAR(60) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE sessions (
+    id SERIAL PRIMARY KEY,
+    token_hash CHAR(64) NOT NULL UNIQUE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_sessions_expires_at ON sessions(expires_at);
+
+ALTER TABLE tasks ADD COLUMN owner_id INTEGER REFERENCES users(id) ON DELETE CASCADE;
+
+CREATE INDEX idx_tasks_owner_id ON tasks(owner_id);
+
+-- 既存のタスクはログインできない移行用のユーザー（パスワードなし）の所有とする。
+-- 運用者が server -claim-legacy-data <メールアドレス> で登録済みのユーザーに引き継ぐ
+INSERT INTO users (email, name, password_hash)
+SELECT 'legacy-owner@localhost', 'Legacy owner', '' WHERE EXISTS (SELECT 1 FROM tasks);
+
+UPDATE tasks SET owner_id = (SELECT id FROM users WHERE email = 'legacy-owner@localhost')
+WHERE owner_id IS NULL;
\ No newline at end of file
@@ -0,0 +1,26 @@
+DROP TRIGGER IF EXISTS trg_project_members_tenant_id ON project_members;
+DROP TRIGGER IF EXISTS trg_task_members_tenant_id ON task_members;
+DROP TRIGGER IF EXISTS trg_undo_tokens_tenant_id ON undo_tokens;
+DROP TRIGGER IF EXISTS trg_task_revisions_tenant_id ON task_revisions;
+DROP TRIGGER IF EXISTS trg_completion_events_tenant_id ON completion_events;
+DROP TRIGGER IF EXISTS trg_tasks_tags_tenant_id ON tasks_tags;
+DROP FUNCTION IF EXISTS set_tenant_id_from_project();
+DROP FUNCTION IF EXISTS set_tenant_id_from_task();
+
+ALTER TABLE tags DROP CONSTRAINT IF EXISTS tags_tenant_id_name_key;
+ALTER TABLE tags ADD CONSTRAINT tags_name_key UNIQUE (name);
+
+ALTER TABLE project_members DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE task_members DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE undo_tokens DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE task_revisions DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE completion_events DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE tasks_tags DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE audit_logs DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE projects DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE tags DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE tasks DROP COLUMN IF EXISTS tenant_id;
+
+DROP TABLE IF EXISTS workspace_members;
+
+DROP TABLE IF EXISTS workspaces;
\ No newline at end of file
@@ -0,0 +1,85 @@
+CREATE TABLE workspaces (
+    id SERIAL PRIMARY KEY,
+    slug VARCHAR(50) NOT NULL UNIQUE,
+    name VARCHAR(100) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE workspace_members (
+    workspace_id INTEGER NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    role VARCHAR(20) NOT NULL CHECK (role IN ('admin', 'member')),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (workspace_id, user_id)
+);
+
+CREATE INDEX idx_workspace_members_user_id ON workspace_members(user_id);
+
+-- 既存のデータはすべて既定のワークスペースに属するものとし、既存のユーザーをメンバー（最初に登録したユーザーを管理者）とする。
+-- 以降に登録したユーザーは登録時に既定のワークスペースのメンバーになる
+INSERT INTO workspaces (id, slug, name) VALUES (1, 'default', 'Default');
+SELECT setval('workspaces_id_seq', 1);
+
+INSERT INTO workspace_members (workspace_id, user_id, role)
+SELECT 1, id, CASE WHEN id = (SELECT MIN(id) FROM users) THEN 'admin' ELSE 'member' END FROM users;
+
+-- ユーザー・セッション・トークンはワークスペースをまたいで使うため tenant_id を持たない
+ALTER TABLE tasks ADD COLUMN tenant_id INTEGER NOT NULL DEFAULT 1 REFERENCES workspaces(id) ON DELETE CASCADE;
+ALTER TABLE tags ADD COLUMN tenant_id INTEGER NOT NULL DEFAULT 1 REFERENCES workspaces(id) ON DELETE CASCADE;
+ALTER TABLE projects ADD COLUMN tenant_id INTEGER NOT NULL DEFAULT 1 REFERENCES workspaces(id) ON DELETE CASCADE;
+ALTER TABLE audit_logs ADD COLUMN tenant_id INTEGER NOT NULL DEFAULT 1 REFERENCES workspaces(id);
+ALTER TABLE tasks_tags ADD COLUMN tenant_id INTEGER NOT NULL DEFAULT 1 REFERENCES workspaces(id) ON DELETE CASCADE;
+ALTER TABLE completion_events ADD COLUMN tenant_id INTEGER NOT NULL DEFAULT 1 REFERENCES workspaces(id) ON DELETE CASCADE;
+ALTER TABLE task_revisions ADD COLUMN tenant_id INTEGER NOT NULL DEFAULT 1 REFERENCES workspaces(id) ON DELETE CASCADE;
+ALTER TABLE undo_tokens ADD COLUMN tenant_id INTEGER NOT NULL DEFAULT 1 REFERENCES workspaces(id) ON DELETE CASCADE;
+ALTER TABLE task_members ADD COLUMN tenant_id INTEGER NOT NULL DEFAULT 1 REFERENCES workspaces(id) ON DELETE CASCADE;
+ALTER TABLE project_members ADD COLUMN tenant_id INTEGER NOT NULL DEFAULT 1 REFERENCES workspaces(id) ON DELETE CASCADE;
+
+ALTER TABLE tasks ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE tags ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE projects ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE audit_logs ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE tasks_tags ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE completion_events ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE task_revisions ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE undo_tokens ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE task_members ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE project_members ALTER COLUMN tenant_id DROP DEFAULT;
+
+CREATE INDEX idx_tasks_tenant_id ON tasks(tenant_id);
+CREATE INDEX idx_projects_tenant_id ON projects(tenant_id);
+CREATE INDEX idx_audit_logs_tenant_id ON audit_logs(tenant_id, created_at);
+
+-- タグ名はワークスペースごとに一意とする
+ALTER TABLE tags DROP CONSTRAINT tags_name_key;
+ALTER TABLE tags ADD CONSTRAINT tags_tenant_id_name_key UNIQUE (tenant_id, name);
+
+-- タスク・プロジェクトに従属する行の tenant_id は親の行から設定する
+CREATE FUNCTION set_tenant_id_from_task() RETURNS TRIGGER AS $$
+BEGIN
+    SELECT tenant_id INTO NEW.tenant_id FROM tasks WHERE id = NEW.task_id;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE FUNCTION set_tenant_id_from_project() RETURNS TRIGGER AS $$
+BEGIN
+    SELECT tenant_id INTO NEW.tenant_id FROM projects WHERE id = NEW.project_id;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_tasks_tags_tenant_id BEFORE INSERT ON tasks_tags
+    FOR EACH ROW EXECUTE FUNCTION set_tenant_id_from_task();
+CREATE TRIGGER trg_completion_events_tenant_id BEFORE INSERT ON completion_events
+    FOR EACH ROW EXECUTE FUNCTION set_tenant_id_from_task();
+CREATE TRIGGER trg_task_revisions_tenant_id BEFORE INSERT ON task_revisions
+    FOR EACH ROW EXECUTE FUNCTION set_tenant_id_from_task();
+CREATE TRIGGER trg_undo_tokens_tenant_id BEFORE INSERT ON undo_tokens
+    FOR EACH ROW EXECUTE FUNCTION set_tenant_id_from_task();
+CREATE TRIGGER trg_task_members_tenant_id BEFORE INSERT ON task_members
+    FOR EACH ROW EXECUTE FUNCTION set_tenant_id_from_task();
+CREATE TRIGGER trg_project_members_tenant_id BEFORE INSERT ON project_members
+    FOR EACH ROW EXECUTE FUNCTION set_tenant_id_from_project();
\ No newline at end of file
@@ -0,0 +1,21 @@
+DROP POLICY IF EXISTS tenant_isolation ON project_members;
+DROP POLICY IF EXISTS tenant_isolation ON task_members;
+DROP POLICY IF EXISTS tenant_isolation ON undo_tokens;
+DROP POLICY IF EXISTS tenant_isolation ON task_revisions;
+DROP POLICY IF EXISTS tenant_isolation ON completion_events;
+DROP POLICY IF EXISTS tenant_isolation ON tasks_tags;
+DROP POLICY IF EXISTS tenant_isolation ON audit_logs;
+DROP POLICY IF EXISTS tenant_isolation ON projects;
+DROP POLICY IF EXISTS tenant_isolation ON tags;
+DROP POLICY IF EXISTS tenant_isolation ON tasks;
+
+ALTER TABLE project_members DISABLE ROW LEVEL SECURITY;
+ALTER TABLE task_members DISABLE ROW LEVEL SECURITY;
+ALTER TABLE undo_tokens DISABLE ROW LEVEL SECURITY;
+ALTER TABLE task_revisions DISABLE ROW LEVEL SECURITY;
+ALTER TABLE completion_events DISABLE ROW LEVEL SECURITY;
+ALTER TABLE tasks_tags DISABLE ROW LEVEL SECURITY;
+ALTER TABLE audit_logs DISABLE ROW LEVEL SECURITY;
+ALTER TABLE projects DISABLE ROW LEVEL SECURITY;
+ALTER TABLE tags DISABLE ROW LEVEL SECURITY;
+ALTER TABLE tasks DISABLE ROW LEVEL SECURITY;
\ No newline at end of file
@@ -0,0 +1,25 @@
+-- 行レベルセキュリティ（任意）
+-- 接続ごとに SET app.tenant_id = '<ワークスペースID>' を設定したロールからは、そのワークスペースの行のみ参照・変更できる。
+-- テーブルの所有者（アプリケーションの接続ユーザー）には適用されないため、アプリケーションはリポジトリの絞り込みで分離し、
+-- レポート用などの別のロールで接続する場合の保護として使う
+ALTER TABLE tasks ENABLE ROW LEVEL SECURITY;
+ALTER TABLE tags ENABLE ROW LEVEL SECURITY;
+ALTER TABLE projects ENABLE ROW LEVEL SECURITY;
+ALTER TABLE audit_logs ENABLE ROW LEVEL SECURITY;
+ALTER TABLE tasks_tags ENABLE ROW LEVEL SECURITY;
+ALTER TABLE completion_events ENABLE ROW LEVEL SECURITY;
+ALTER TABLE task_revisions ENABLE ROW LEVEL SECURITY;
+ALTER TABLE undo_tokens ENABLE ROW LEVEL SECURITY;
+ALTER TABLE task_members ENABLE ROW LEVEL SECURITY;
+ALTER TABLE project_members ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY tenant_isolation ON tasks USING (tenant_id = current_setting('app.tenant_id', true)::integer);
+CREATE POLICY tenant_isolation ON tags USING (tenant_id = current_setting('app.tenant_id', true)::integer);
+CREATE POLICY tenant_isolation ON projects USING (tenant_id = current_setting('app.tenant_id', true)::integer);
+CREATE POLICY tenant_isolation ON audit_logs USING (tenant_id = current_setting('app.tenant_id', true)::integer);
+CREATE POLICY tenant_isolation ON tasks_tags USING (tenant_id = current_setting('app.tenant_id', true)::integer);
+CREATE POLICY tenant_isolation ON completion_events USING (tenant_id = current_setting('app.tenant_id', true)::integer);
+CREATE POLICY tenant_isolation ON task_revisions USING (tenant_id = current_setting('app.tenant_id', true)::integer);
+CREATE POLICY tenant_isolation ON undo_tokens USING (tenant_id = current_setting('app.tenant_id', true)::integer);
+CREATE POLICY tenant_isolation ON task_members USING (tenant_id = current_setting('app.tenant_id', true)::integer);
+CREATE POLICY tenant_isolation ON project_members USING (tenant_id = current_setting('app.tenant_id', true)::integer);
\ No newline at end of file
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS assignment_events;
+DROP TABLE IF EXISTS task_assignees;
\ No newline at end of file
@@ -0,0 +1,40 @@
+CREATE TABLE task_assignees (
+    task_id INTEGER NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    tenant_id INTEGER NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (task_id, user_id)
+);
+
+CREATE INDEX idx_task_assignees_user_id ON task_assignees(user_id);
+
+-- 担当者の追加・解除のイベント。通知などの他の機能は id の昇順に読み出して処理する
+CREATE TABLE assignment_events (
+    id SERIAL PRIMARY KEY,
+    task_id INTEGER NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    tenant_id INTEGER NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE,
+    action VARCHAR(20) NOT NULL CHECK (action IN ('assigned', 'unassigned')),
+    actor_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_assignment_events_task_id ON assignment_events(task_id);
+CREATE INDEX idx_assignment_events_tenant_id ON assignment_events(tenant_id, id);
+
+CREATE TRIGGER trg_task_assignees_tenant_id BEFORE INSERT ON task_assignees
+    FOR EACH ROW EXECUTE FUNCTION set_tenant_id_from_task();
+CREATE TRIGGER trg_assignment_events_tenant_id BEFORE INSERT ON assignment_events
+    FOR EACH ROW EXECUTE FUNCTION set_tenant_id_from_task();
+
+-- 行レベルセキュリティを有効にしている場合は、追加したテーブルにも有効にする
+CREATE POLICY tenant_isolation ON task_assignees USING (tenant_id = current_setting('app.tenant_id', true)::integer);
+CREATE POLICY tenant_isolation ON assignment_events USING (tenant_id = current_setting('app.tenant_id', true)::integer);
+DO $$
+BEGIN
+    IF (SELECT relrowsecurity FROM pg_class WHERE oid = 'tasks'::regclass) THEN
+        ALTER TABLE task_assignees ENABLE ROW LEVEL SECURITY;
+        ALTER TABLE assignment_events ENABLE ROW LEVEL SECURITY;
+    END IF;
+END
+$$;
\ No newline at end of file
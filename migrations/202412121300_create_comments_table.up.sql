@@ -0,0 +1,25 @@
+CREATE TABLE comments (
+    id SERIAL PRIMARY KEY,
+    task_id INTEGER NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    tenant_id INTEGER NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE,
+    author_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    body TEXT NOT NULL,
+    edited_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_comments_task_id ON comments(task_id, created_at);
+
+CREATE TRIGGER trg_comments_tenant_id BEFORE INSERT ON comments
+    FOR EACH ROW EXECUTE FUNCTION set_tenant_id_from_task();
+
+-- 行レベルセキュリティを有効にしている場合は、追加したテーブルにも有効にする
+CREATE POLICY tenant_isolation ON comments USING (tenant_id = current_setting('app.tenant_id', true)::integer);
+DO $$
+BEGIN
+    IF (SELECT relrowsecurity FROM pg_class WHERE oid = 'tasks'::regclass) THEN
+        ALTER TABLE comments ENABLE ROW LEVEL SECURITY;
+    END IF;
+END
+$$;
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE tags DROP COLUMN IF EXISTS owner_id;
\ No newline at end of file
@@ -0,0 +1,2 @@
+-- 作成したユーザーを記録する（既存のタグは所有者なしとし、ワークスペースの管理者のみが変更・削除できる）
+ALTER TABLE tags ADD COLUMN owner_id INTEGER REFERENCES users(id) ON DELETE SET NULL;
\ No newline at end of file
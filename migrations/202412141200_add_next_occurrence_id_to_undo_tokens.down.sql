@@ -0,0 +1 @@
+ALTER TABLE undo_tokens DROP COLUMN IF EXISTS next_occurrence_id;
\ No newline at end of file
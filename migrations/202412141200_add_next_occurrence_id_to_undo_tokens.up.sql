@@ -0,0 +1 @@
+ALTER TABLE undo_tokens ADD COLUMN next_occurrence_id INTEGER REFERENCES tasks(id) ON DELETE SET NULL;
\ No newline at end of file
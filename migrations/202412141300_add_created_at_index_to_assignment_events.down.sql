@@ -0,0 +1 @@
+DROP INDEX IF EXISTS idx_assignment_events_created_at;
\ No newline at end of file
@@ -0,0 +1,2 @@
+-- 担当者の変更イベントは作成日時・id の順に読み出す（id の順とコミットの順は一致しないため）
+CREATE INDEX idx_assignment_events_created_at ON assignment_events(tenant_id, created_at, id);
\ No newline at end of file
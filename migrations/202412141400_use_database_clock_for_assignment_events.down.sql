@@ -0,0 +1,2 @@
+ALTER TABLE assignment_events ALTER COLUMN created_at DROP NOT NULL;
+ALTER TABLE assignment_events ALTER COLUMN created_at SET DEFAULT CURRENT_TIMESTAMP;
\ No newline at end of file
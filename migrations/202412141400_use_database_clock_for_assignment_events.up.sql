@@ -0,0 +1,3 @@
+-- 担当者の変更イベントの作成日時はデータベースの時計で設定する（アプリケーションサーバーの時計のずれで取得の順序が崩れないようにする）
+ALTER TABLE assignment_events ALTER COLUMN created_at SET DEFAULT clock_timestamp();
+ALTER TABLE assignment_events ALTER COLUMN created_at SET NOT NULL;
\ No newline at end of file
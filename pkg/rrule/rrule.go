@@ -0,0 +1,446 @@
+// Package rrule は RFC 5545 の繰り返しルール（RRULE）のサブセットを扱います。
+//
+// 対応しているパラメータ: FREQ (DAILY/WEEKLY/MONTHLY/YEARLY), INTERVAL, COUNT, UNTIL,
+// BYDAY, BYMONTHDAY, BYMONTH, WKST (MO のみ)。
+// BYSETPOS や BYWEEKNO などその他のパラメータはエラーになります。
+package rrule
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Frequency は繰り返しの単位です
+type Frequency int
+
+const (
+	Daily Frequency = iota
+	Weekly
+	Monthly
+	Yearly
+)
+
+var frequencyNames = map[Frequency]string{
+	Daily:   "DAILY",
+	Weekly:  "WEEKLY",
+	Monthly: "MONTHLY",
+	Yearly:  "YEARLY",
+}
+
+var weekdayNames = map[time.Weekday]string{
+	time.Monday:    "MO",
+	time.Tuesday:   "TU",
+	time.Wednesday: "WE",
+	time.Thursday:  "TH",
+	time.Friday:    "FR",
+	time.Saturday:  "SA",
+	time.Sunday:    "SU",
+}
+
+// maxPeriods は発生日の探索を打ち切る期間数です（2月31日のような発生しないルールへの対策）
+const maxPeriods = 10000
+
+// Weekday は BYDAY の要素です。N が 0 以外の場合は月内の第N曜日（負数は末尾から）を表します
+type Weekday struct {
+	Day time.Weekday
+	N   int
+}
+
+// Rule は解析済みの繰り返しルールです
+type Rule struct {
+	Freq       Frequency
+	Interval   int
+	Count      int
+	Until      time.Time
+	ByDay      []Weekday
+	ByMonthDay []int
+	ByMonth    []time.Month
+}
+
+// Parse は "FREQ=WEEKLY;BYDAY=MO,WE" 形式の文字列を解析します。先頭の "RRULE:" は省略可能です
+func Parse(s string) (*Rule, error) {
+	s = strings.TrimPrefix(strings.TrimSpace(s), "RRULE:")
+	if s == "" {
+		return nil, errors.New("empty rule")
+	}
+
+	rule := &Rule{Interval: 1}
+	hasFreq := false
+	seen := make(map[string]bool)
+	for _, part := range strings.Split(s, ";") {
+		key, value, ok := strings.Cut(part, "=")
+		if !ok || value == "" {
+			return nil, fmt.Errorf("malformed part %q", part)
+		}
+		key = strings.ToUpper(key)
+		value = strings.ToUpper(value)
+		if seen[key] {
+			return nil, fmt.Errorf("duplicate %s", key)
+		}
+		seen[key] = true
+
+		var err error
+		switch key {
+		case "FREQ":
+			hasFreq = true
+			rule.Freq, err = parseFrequency(value)
+		case "INTERVAL":
+			rule.Interval, err = parsePositive(key, value)
+		case "COUNT":
+			rule.Count, err = parsePositive(key, value)
+		case "UNTIL":
+			rule.Until, err = parseUntil(value)
+		case "BYDAY":
+			rule.ByDay, err = parseByDay(value)
+		case "BYMONTHDAY":
+			rule.ByMonthDay, err = parseByMonthDay(value)
+		case "BYMONTH":
+			rule.ByMonth, err = parseByMonth(value)
+		case "WKST":
+			if value != "MO" {
+				err = errors.New("only WKST=MO is supported")
+			}
+		default:
+			err = fmt.Errorf("unsupported parameter %s", key)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if !hasFreq {
+		return nil, errors.New("FREQ is required")
+	}
+	if rule.Count > 0 && !rule.Until.IsZero() {
+		return nil, errors.New("COUNT and UNTIL must not be used together")
+	}
+	if err := rule.validate(); err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+func (r *Rule) validate() error {
+	for _, wd := range r.ByDay {
+		if wd.N != 0 && r.Freq != Monthly && r.Freq != Yearly {
+			return errors.New("ordinal BYDAY is only supported with MONTHLY or YEARLY")
+		}
+		if wd.N != 0 && r.Freq == Yearly && len(r.ByMonth) == 0 {
+			return errors.New("ordinal BYDAY with YEARLY requires BYMONTH")
+		}
+	}
+	if len(r.ByMonthDay) > 0 && r.Freq == Weekly {
+		return errors.New("BYMONTHDAY is not allowed with WEEKLY")
+	}
+	return nil
+}
+
+// String はルールを正規化した文字列で返します
+func (r *Rule) String() string {
+	parts := []string{"FREQ=" + frequencyNames[r.Freq]}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	if !r.Until.IsZero() {
+		parts = append(parts, "UNTIL="+r.Until.UTC().Format("20060102T150405Z"))
+	}
+	if len(r.ByMonth) > 0 {
+		values := make([]string, len(r.ByMonth))
+		for i, m := range r.ByMonth {
+			values[i] = strconv.Itoa(int(m))
+		}
+		parts = append(parts, "BYMONTH="+strings.Join(values, ","))
+	}
+	if len(r.ByMonthDay) > 0 {
+		values := make([]string, len(r.ByMonthDay))
+		for i, d := range r.ByMonthDay {
+			values[i] = strconv.Itoa(d)
+		}
+		parts = append(parts, "BYMONTHDAY="+strings.Join(values, ","))
+	}
+	if len(r.ByDay) > 0 {
+		values := make([]string, len(r.ByDay))
+		for i, wd := range r.ByDay {
+			values[i] = weekdayNames[wd.Day]
+			if wd.N != 0 {
+				values[i] = strconv.Itoa(wd.N) + values[i]
+			}
+		}
+		parts = append(parts, "BYDAY="+strings.Join(values, ","))
+	}
+	return strings.Join(parts, ";")
+}
+
+// Next は dtstart から始まる系列で after より後の最初の発生日時を返します
+func (r *Rule) Next(dtstart, after time.Time) (time.Time, bool) {
+	var next time.Time
+	found := false
+	r.iterate(dtstart, func(t time.Time) bool {
+		if t.After(after) {
+			next, found = t, true
+			return false
+		}
+		return true
+	})
+	return next, found
+}
+
+// Between は dtstart から始まる系列のうち [from, to] に含まれる発生日時を最大 limit 件返します
+func (r *Rule) Between(dtstart, from, to time.Time, limit int) []time.Time {
+	var result []time.Time
+	r.iterate(dtstart, func(t time.Time) bool {
+		if t.After(to) || len(result) >= limit {
+			return false
+		}
+		if !t.Before(from) {
+			result = append(result, t)
+		}
+		return true
+	})
+	return result
+}
+
+// iterate は発生日時を昇順に fn へ渡します。fn が false を返すか系列が終了すると停止します
+func (r *Rule) iterate(dtstart time.Time, fn func(time.Time) bool) {
+	count := 0
+	for period := 0; period < maxPeriods; period++ {
+		for _, t := range r.candidates(dtstart, period*r.Interval) {
+			if t.Before(dtstart) {
+				continue
+			}
+			if !r.Until.IsZero() && t.After(r.Until) {
+				return
+			}
+			if !fn(t) {
+				return
+			}
+			count++
+			if r.Count > 0 && count >= r.Count {
+				return
+			}
+		}
+	}
+}
+
+// candidates は dtstart から offset 期間後の期間に含まれる候補日時を昇順で返します
+func (r *Rule) candidates(dtstart time.Time, offset int) []time.Time {
+	y, m, d := dtstart.Date()
+	at := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, dtstart.Hour(), dtstart.Minute(), dtstart.Second(), dtstart.Nanosecond(), dtstart.Location())
+	}
+
+	var result []time.Time
+	switch r.Freq {
+	case Daily:
+		t := at(y, m, d).AddDate(0, 0, offset)
+		if r.matchesMonth(t.Month()) && r.matchesMonthDay(t) && r.matchesWeekday(t.Weekday()) {
+			result = append(result, t)
+		}
+	case Weekly:
+		// 週の始まりは月曜日
+		monday := at(y, m, d).AddDate(0, 0, -((int(dtstart.Weekday())+6)%7)+7*offset)
+		days := []time.Weekday{dtstart.Weekday()}
+		if len(r.ByDay) > 0 {
+			days = days[:0]
+			for _, wd := range r.ByDay {
+				days = append(days, wd.Day)
+			}
+		}
+		for _, day := range days {
+			t := monday.AddDate(0, 0, (int(day)+6)%7)
+			if r.matchesMonth(t.Month()) {
+				result = append(result, t)
+			}
+		}
+	case Monthly:
+		first := time.Date(y, m, 1, 0, 0, 0, 0, time.UTC).AddDate(0, offset, 0)
+		if r.matchesMonth(first.Month()) {
+			for _, day := range r.monthDays(first.Year(), first.Month(), d) {
+				result = append(result, at(first.Year(), first.Month(), day))
+			}
+		}
+	case Yearly:
+		year := y + offset
+		months := r.ByMonth
+		if len(months) == 0 {
+			months = []time.Month{m}
+		}
+		for _, month := range months {
+			for _, day := range r.monthDays(year, month, d) {
+				result = append(result, at(year, month, day))
+			}
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool { return result[i].Before(result[j]) })
+	return result
+}
+
+// monthDays は指定した月で BYMONTHDAY / BYDAY に一致する日を昇順で返します
+func (r *Rule) monthDays(year int, month time.Month, defaultDay int) []int {
+	last := time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+
+	if len(r.ByMonthDay) == 0 && len(r.ByDay) == 0 {
+		// 該当する日がない月（31日がない月など）はスキップする
+		if defaultDay > last {
+			return nil
+		}
+		return []int{defaultDay}
+	}
+
+	var days []int
+	for day := 1; day <= last; day++ {
+		t := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+		if len(r.ByMonthDay) > 0 && !r.matchesMonthDay(t) {
+			continue
+		}
+		if len(r.ByDay) > 0 && !r.matchesOrdinalWeekday(t, last) {
+			continue
+		}
+		days = append(days, day)
+	}
+	return days
+}
+
+func (r *Rule) matchesMonth(month time.Month) bool {
+	if len(r.ByMonth) == 0 {
+		return true
+	}
+	for _, m := range r.ByMonth {
+		if m == month {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Rule) matchesMonthDay(t time.Time) bool {
+	if len(r.ByMonthDay) == 0 {
+		return true
+	}
+	last := time.Date(t.Year(), t.Month()+1, 0, 0, 0, 0, 0, t.Location()).Day()
+	for _, md := range r.ByMonthDay {
+		if md == t.Day() || (md < 0 && last+md+1 == t.Day()) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Rule) matchesWeekday(day time.Weekday) bool {
+	if len(r.ByDay) == 0 {
+		return true
+	}
+	for _, wd := range r.ByDay {
+		if wd.Day == day {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesOrdinalWeekday は第N曜日の指定を含めて BYDAY に一致するか判定します
+func (r *Rule) matchesOrdinalWeekday(t time.Time, last int) bool {
+	for _, wd := range r.ByDay {
+		if wd.Day != t.Weekday() {
+			continue
+		}
+		switch {
+		case wd.N == 0:
+			return true
+		case wd.N > 0 && (t.Day()-1)/7+1 == wd.N:
+			return true
+		case wd.N < 0 && (last-t.Day())/7+1 == -wd.N:
+			return true
+		}
+	}
+	return false
+}
+
+func parseFrequency(value string) (Frequency, error) {
+	for freq, name := range frequencyNames {
+		if name == value {
+			return freq, nil
+		}
+	}
+	return 0, fmt.Errorf("unsupported FREQ %s", value)
+}
+
+func parsePositive(key, value string) (int, error) {
+	n, err := strconv.Atoi(value)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid %s %s", key, value)
+	}
+	return n, nil
+}
+
+func parseUntil(value string) (time.Time, error) {
+	for _, layout := range []string{"20060102T150405Z", "20060102T150405", "20060102"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid UNTIL %s", value)
+}
+
+func parseByDay(value string) ([]Weekday, error) {
+	var result []Weekday
+	for _, item := range strings.Split(value, ",") {
+		if len(item) < 2 {
+			return nil, fmt.Errorf("invalid BYDAY %s", item)
+		}
+		name := item[len(item)-2:]
+		var day time.Weekday
+		found := false
+		for d, n := range weekdayNames {
+			if n == name {
+				day, found = d, true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("invalid BYDAY %s", item)
+		}
+		n := 0
+		if prefix := item[:len(item)-2]; prefix != "" {
+			var err error
+			n, err = strconv.Atoi(prefix)
+			if err != nil || n == 0 || n < -5 || n > 5 {
+				return nil, fmt.Errorf("invalid BYDAY %s", item)
+			}
+		}
+		result = append(result, Weekday{Day: day, N: n})
+	}
+	return result, nil
+}
+
+func parseByMonthDay(value string) ([]int, error) {
+	var result []int
+	for _, item := range strings.Split(value, ",") {
+		n, err := strconv.Atoi(item)
+		if err != nil || n == 0 || n < -31 || n > 31 {
+			return nil, fmt.Errorf("invalid BYMONTHDAY %s", item)
+		}
+		result = append(result, n)
+	}
+	return result, nil
+}
+
+func parseByMonth(value string) ([]time.Month, error) {
+	var result []time.Month
+	for _, item := range strings.Split(value, ",") {
+		n, err := strconv.Atoi(item)
+		if err != nil || n < 1 || n > 12 {
+			return nil, fmt.Errorf("invalid BYMONTH %s", item)
+		}
+		result = append(result, time.Month(n))
+	}
+	return result, nil
+}
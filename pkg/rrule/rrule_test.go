@@ -0,0 +1,117 @@
+// pkg/rrule/rrule_test.go
+package rrule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 9, 0, 0, 0, time.UTC)
+}
+
+// TestParse はルール文字列の解析と正規化をテストします。
+func TestParse(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"FREQ=DAILY", "FREQ=DAILY"},
+		{"RRULE:freq=weekly;interval=2;byday=MO,WE", "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,WE"},
+		{"FREQ=MONTHLY;BYDAY=-1FR;COUNT=3", "FREQ=MONTHLY;COUNT=3;BYDAY=-1FR"},
+		{"FREQ=YEARLY;BYMONTH=3;BYMONTHDAY=31;UNTIL=20301231", "FREQ=YEARLY;UNTIL=20301231T000000Z;BYMONTH=3;BYMONTHDAY=31"},
+	}
+
+	for _, tt := range tests {
+		rule, err := Parse(tt.input)
+		if assert.NoError(t, err, tt.input) {
+			assert.Equal(t, tt.expected, rule.String())
+		}
+	}
+}
+
+// TestParse_Invalid は不正・未対応のルールがエラーになることをテストします。
+func TestParse_Invalid(t *testing.T) {
+	inputs := []string{
+		"",
+		"INTERVAL=2",
+		"FREQ=HOURLY",
+		"FREQ=DAILY;COUNT=0",
+		"FREQ=DAILY;COUNT=2;UNTIL=20300101",
+		"FREQ=WEEKLY;BYDAY=1MO",
+		"FREQ=WEEKLY;BYMONTHDAY=1",
+		"FREQ=MONTHLY;BYSETPOS=1",
+		"FREQ=MONTHLY;BYMONTHDAY=32",
+		"FREQ=DAILY;FREQ=WEEKLY",
+	}
+
+	for _, input := range inputs {
+		_, err := Parse(input)
+		assert.Error(t, err, input)
+	}
+}
+
+// TestNext は次回の発生日時の計算をテストします。
+func TestNext(t *testing.T) {
+	tests := []struct {
+		rule     string
+		dtstart  time.Time
+		expected time.Time
+	}{
+		// 毎日
+		{"FREQ=DAILY", date(2024, 12, 31), date(2025, 1, 1)},
+		// 隔週の月・水曜日（水曜日の次は翌々週の月曜日）
+		{"FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,WE", date(2024, 12, 4), date(2024, 12, 16)},
+		// 毎月末日
+		{"FREQ=MONTHLY;BYMONTHDAY=-1", date(2025, 1, 31), date(2025, 2, 28)},
+		// 31日がない月はスキップされる
+		{"FREQ=MONTHLY", date(2025, 1, 31), date(2025, 3, 31)},
+		// 毎月最終金曜日
+		{"FREQ=MONTHLY;BYDAY=-1FR", date(2024, 11, 29), date(2024, 12, 27)},
+		// 毎年11月の第4木曜日
+		{"FREQ=YEARLY;BYMONTH=11;BYDAY=4TH", date(2024, 11, 28), date(2025, 11, 27)},
+	}
+
+	for _, tt := range tests {
+		rule, err := Parse(tt.rule)
+		if !assert.NoError(t, err, tt.rule) {
+			continue
+		}
+		next, ok := rule.Next(tt.dtstart, tt.dtstart)
+		assert.True(t, ok, tt.rule)
+		assert.Equal(t, tt.expected, next, tt.rule)
+	}
+}
+
+// TestNext_Exhausted は COUNT や UNTIL で系列が終了した場合をテストします。
+func TestNext_Exhausted(t *testing.T) {
+	rule, err := Parse("FREQ=DAILY;COUNT=1")
+	assert.NoError(t, err)
+	_, ok := rule.Next(date(2025, 1, 1), date(2025, 1, 1))
+	assert.False(t, ok)
+
+	rule, err = Parse("FREQ=WEEKLY;UNTIL=20250105T000000Z")
+	assert.NoError(t, err)
+	_, ok = rule.Next(date(2025, 1, 1), date(2025, 1, 1))
+	assert.False(t, ok)
+}
+
+// TestBetween は期間内の発生日時の展開をテストします。
+func TestBetween(t *testing.T) {
+	rule, err := Parse("FREQ=WEEKLY;BYDAY=MO,FR;COUNT=5")
+	assert.NoError(t, err)
+
+	// 2025/1/6(月) から開始し、COUNT=5 のため 1/20 で終了する
+	occurrences := rule.Between(date(2025, 1, 6), date(2025, 1, 8), date(2025, 2, 1), 100)
+	assert.Equal(t, []time.Time{
+		date(2025, 1, 10),
+		date(2025, 1, 13),
+		date(2025, 1, 17),
+		date(2025, 1, 20),
+	}, occurrences)
+
+	// limit で件数が制限される
+	assert.Len(t, rule.Between(date(2025, 1, 6), date(2025, 1, 1), date(2025, 2, 1), 2), 2)
+}